@@ -14,8 +14,9 @@ resource "aws_route53_record" "etcds" {
 }
 
 # IAM Policy
+# The role is shared by all components running on controllers, which need access to AWS API.
 resource "aws_iam_instance_profile" "csi-driver" {
-  count = var.enable_csi ? 1 : 0
+  count = var.enable_csi || var.enable_autoscaling ? 1 : 0
   role  = join("", aws_iam_role.csi-driver.*.name)
 }
 
@@ -53,8 +54,46 @@ resource "aws_iam_role_policy" "csi-driver" {
   EOF
 }
 
+resource "aws_iam_role_policy" "cluster-autoscaler" {
+  count = var.enable_autoscaling ? 1 : 0
+  role  = join("", aws_iam_role.csi-driver.*.id)
+
+  policy = <<-EOF
+  {
+    "Version": "2012-10-17",
+    "Statement": [
+      {
+        "Action": [
+          "autoscaling:DescribeAutoScalingGroups",
+          "autoscaling:DescribeAutoScalingInstances",
+          "autoscaling:DescribeLaunchConfigurations",
+          "autoscaling:DescribeTags",
+          "ec2:DescribeInstanceTypes",
+          "ec2:DescribeLaunchTemplateVersions"
+        ],
+        "Effect": "Allow",
+        "Resource": "*"
+      },
+      {
+        "Action": [
+          "autoscaling:SetDesiredCapacity",
+          "autoscaling:TerminateInstanceInAutoScalingGroup"
+        ],
+        "Effect": "Allow",
+        "Resource": "*",
+        "Condition": {
+          "StringEquals": {
+            "autoscaling:ResourceTag/k8s.io/cluster-autoscaler/${var.cluster_name}": "owned"
+          }
+        }
+      }
+    ]
+  }
+  EOF
+}
+
 resource "aws_iam_role" "csi-driver" {
-  count = var.enable_csi ? 1 : 0
+  count = var.enable_csi || var.enable_autoscaling ? 1 : 0
   path  = "/"
   tags  = var.tags
 
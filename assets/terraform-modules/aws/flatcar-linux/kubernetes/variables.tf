@@ -92,6 +92,12 @@ variable "enable_csi" {
   description = "Set up IAM role needed for dynamic volumes provisioning to work on AWS"
 }
 
+variable "enable_autoscaling" {
+  type        = bool
+  default     = false
+  description = "Set up IAM role needed by cluster-autoscaler running on controllers to scale worker pools"
+}
+
 # configuration
 
 variable "ssh_keys" {
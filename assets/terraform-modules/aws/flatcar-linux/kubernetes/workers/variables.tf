@@ -33,6 +33,24 @@ variable "worker_count" {
   description = "Number of instances"
 }
 
+variable "min_count" {
+  type        = number
+  default     = null
+  description = "Minimum size of the autoscaling group. Defaults to worker_count."
+}
+
+variable "max_count" {
+  type        = number
+  default     = null
+  description = "Maximum size of the autoscaling group. Defaults to worker_count + 2."
+}
+
+variable "enable_autoscaling" {
+  type        = bool
+  default     = false
+  description = "Let cluster-autoscaler manage the number of instances between min_count and max_count."
+}
+
 variable "instance_type" {
   type        = string
   default     = "t3.small"
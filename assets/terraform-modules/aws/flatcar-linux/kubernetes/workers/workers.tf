@@ -3,9 +3,10 @@ resource "aws_autoscaling_group" "workers" {
   name = "${var.pool_name}-worker"
 
   # count
-  desired_capacity          = var.worker_count
-  min_size                  = var.worker_count
-  max_size                  = var.worker_count + 2
+  # Size of the autoscaled group is managed by cluster-autoscaler, so Terraform must not reset it.
+  desired_capacity          = var.enable_autoscaling ? null : var.worker_count
+  min_size                  = var.min_count != null ? var.min_count : var.worker_count
+  max_size                  = var.max_count != null ? var.max_count : var.worker_count + 2
   default_cooldown          = 30
   health_check_grace_period = 30
 
@@ -40,7 +41,25 @@ resource "aws_autoscaling_group" "workers" {
         value               = "${var.cluster_name}-${var.pool_name}-worker"
         propagate_at_launch = true
       },
+      {
+        key                 = "lokomotive.kinvolk.io/worker-pool"
+        value               = var.pool_name
+        propagate_at_launch = false
+      },
     ],
+    # Tags used by cluster-autoscaler component to discover autoscaled worker pools.
+    var.enable_autoscaling ? [
+      {
+        key                 = "k8s.io/cluster-autoscaler/enabled"
+        value               = "true"
+        propagate_at_launch = false
+      },
+      {
+        key                 = "k8s.io/cluster-autoscaler/${var.cluster_name}"
+        value               = "owned"
+        propagate_at_launch = false
+      },
+    ] : [],
     [
       for tag in keys(var.tags) :
       {
@@ -1,22 +1,44 @@
+locals {
+  api_server = format("%s.%s", var.cluster_name, var.dns_zone)
+}
+
 # Self-hosted Kubernetes assets (kubeconfig, manifests)
 module "bootkube" {
   source = "../../../bootkube"
 
-  cluster_name = var.cluster_name
-  api_servers  = [format("%s.%s", var.cluster_name, var.dns_zone)]
-  etcd_servers = formatlist("%s.%s", azurerm_dns_a_record.etcds.*.name, var.dns_zone)
-  asset_dir    = var.asset_dir
+  cluster_name                = var.cluster_name
+  api_servers                 = [local.api_server]
+  etcd_servers                = formatlist("%s.%s", azurerm_dns_a_record.etcds.*.name, var.dns_zone)
+  etcd_endpoints              = azurerm_network_interface.controllers.*.private_ip_address
+  asset_dir                   = var.asset_dir
+  network_encapsulation       = "vxlan"
+  network_mtu                 = var.network_mtu
+  pod_cidr                    = var.pod_cidr
+  service_cidr                = var.service_cidr
+  cluster_domain_suffix       = var.cluster_domain_suffix
+  enable_reporting            = var.enable_reporting
+  enable_aggregation          = var.enable_aggregation
+  kube_apiserver_extra_flags  = var.kube_apiserver_extra_flags
+  certs_validity_period_hours = var.certs_validity_period_hours
+  controller_count            = var.controller_count
+
+  # Disable the self hosted kubelet.
+  disable_self_hosted_kubelet = var.disable_self_hosted_kubelet
 
-  network_encapsulation = "vxlan"
+  # Block access to Azure Instance Metadata Service for all pods.
+  #
+  # https://docs.microsoft.com/en-us/azure/virtual-machines/linux/instance-metadata-service
+  blocked_metadata_cidrs = ["169.254.169.254/32"]
 
-  # we should be able to use 1450 MTU, but in practice, 1410 was needed
-  network_mtu = "1410"
+  bootstrap_tokens     = var.enable_tls_bootstrap ? concat([local.controller_bootstrap_token], var.worker_bootstrap_tokens) : []
+  enable_tls_bootstrap = var.enable_tls_bootstrap
+  encrypt_pod_traffic  = var.encrypt_pod_traffic
 
-  pod_cidr              = var.pod_cidr
-  service_cidr          = var.service_cidr
-  cluster_domain_suffix = var.cluster_domain_suffix
-  enable_reporting      = var.enable_reporting
-  enable_aggregation    = var.enable_aggregation
+  ignore_x509_cn_check = var.ignore_x509_cn_check
 
-  certs_validity_period_hours = var.certs_validity_period_hours
+  conntrack_max_per_core = var.conntrack_max_per_core
+
+  # Node Local DNS configuration.
+  enable_node_local_dns = var.enable_node_local_dns
+  node_local_dns_ip     = var.node_local_dns_ip
 }
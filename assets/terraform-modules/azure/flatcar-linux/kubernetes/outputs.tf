@@ -31,6 +31,14 @@ output "kubeconfig" {
   value = module.bootkube.kubeconfig-kubelet
 }
 
+output "ca_cert" {
+  value = module.bootkube.ca_cert
+}
+
+output "apiserver" {
+  value = local.api_server
+}
+
 # Outputs for custom firewalling
 
 output "worker_security_group_name" {
@@ -78,3 +86,11 @@ output "calico_values" {
 output "lokomotive_values" {
   value = module.bootkube.lokomotive_values
 }
+
+output "bootstrap-secrets_values" {
+  value = module.bootkube.bootstrap-secrets_values
+}
+
+output "node-local-dns_values" {
+  value = module.bootkube.node-local-dns_values
+}
@@ -38,24 +38,12 @@ variable "controller_count" {
   description = "Number of controllers (i.e. masters)"
 }
 
-variable "worker_count" {
-  type        = number
-  default     = 1
-  description = "Number of workers"
-}
-
 variable "controller_type" {
   type        = string
   default     = "Standard_B2s"
   description = "Machine type for controllers (see `az vm list-skus --location centralus`)"
 }
 
-variable "worker_type" {
-  type        = string
-  default     = "Standard_B2s"
-  description = "Machine type for workers (see `az vm list-skus --location centralus`)"
-}
-
 variable "os_image" {
   type        = string
   default     = "coreos-stable"
@@ -68,26 +56,20 @@ variable "disk_size" {
   description = "Size of the disk in GB"
 }
 
-variable "worker_priority" {
-  type        = string
-  default     = "Regular"
-  description = "Set worker priority to Low to use reduced cost surplus capacity, with the tradeoff that instances can be deallocated at any time."
-}
-
 variable "controller_clc_snippets" {
   type        = list(string)
   description = "Controller Container Linux Config snippets"
   default     = []
 }
 
-variable "worker_clc_snippets" {
-  type        = list(string)
-  description = "Worker Container Linux Config snippets"
-  default     = []
-}
-
 # configuration
 
+variable "network_mtu" {
+  type        = number
+  description = "CNI interface MTU. Azure requires lower MTU than 1450 due to VXLAN overhead."
+  default     = 1410
+}
+
 variable "ssh_keys" {
   type        = list(string)
   description = "SSH public keys for user 'core'"
@@ -146,3 +128,53 @@ variable "certs_validity_period_hours" {
   type        = number
   default     = 8760
 }
+
+variable "encrypt_pod_traffic" {
+  description = "Enable in-cluster pod traffic encryption."
+  type        = bool
+  default     = false
+}
+
+variable "disable_self_hosted_kubelet" {
+  description = "Disable the self hosted kubelet installed by default"
+  type        = bool
+}
+
+variable "enable_tls_bootstrap" {
+  description = "Enable TLS Bootstrap for Kubelet."
+  type        = bool
+}
+
+variable "worker_bootstrap_tokens" {
+  description = "List of token-id and token-secret of each node."
+  type        = list(any)
+}
+
+variable "kube_apiserver_extra_flags" {
+  description = "Extra flags passed to self-hosted kube-apiserver."
+  type        = list(string)
+  default     = []
+}
+
+variable "ignore_x509_cn_check" {
+  description = "Ignore CN checks in x509 certificates."
+  type        = bool
+  default     = false
+}
+
+variable "conntrack_max_per_core" {
+  description = "--conntrack-max-per-core value for kube-proxy. Maximum number of NAT connections to track per CPU core (0 to leave the limit as-is and ignore the conntrack-min kube-proxy flag)."
+  type        = number
+}
+
+variable "enable_node_local_dns" {
+  description = "Enable Node Local DNS on the cluster."
+  type        = bool
+  default     = false
+}
+
+variable "node_local_dns_ip" {
+  description = "Node Local DNS IP for the pods running on each node. This is the local IP pods can reach for name resolution."
+  type        = string
+  default     = "169.254.1.1"
+}
@@ -16,9 +16,9 @@ terraform {
       source  = "hashicorp/null"
       version = "3.1.0"
     }
-    template = {
-      source  = "hashicorp/template"
-      version = "2.2.0"
+    random = {
+      source  = "hashicorp/random"
+      version = "3.0.0"
     }
   }
 }
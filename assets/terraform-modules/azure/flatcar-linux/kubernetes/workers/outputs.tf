@@ -0,0 +1,3 @@
+output "worker_bootstrap_token" {
+  value = local.worker_bootstrap_token
+}
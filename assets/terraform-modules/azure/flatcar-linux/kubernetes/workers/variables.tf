@@ -66,6 +66,24 @@ variable "priority" {
   description = "Set priority to Low to use reduced cost surplus capacity, with the tradeoff that instances can be evicted at any time."
 }
 
+variable "disk_size" {
+  type        = number
+  default     = 30
+  description = "Size of the OS disk in GB"
+}
+
+variable "labels" {
+  type        = map(string)
+  description = "Map of custom labels for worker nodes."
+  default     = {}
+}
+
+variable "taints" {
+  type        = map(string)
+  default     = {}
+  description = "Map of custom taints for worker nodes."
+}
+
 variable "clc_snippets" {
   type        = list(string)
   description = "Container Linux Config snippets"
@@ -79,6 +97,16 @@ variable "kubeconfig" {
   description = "Must be set to `kubeconfig` output by cluster"
 }
 
+variable "ca_cert" {
+  description = "Kubernetes CA certificate needed in the kubeconfig file."
+  type        = string
+}
+
+variable "apiserver" {
+  description = "Apiserver endpoint needed in the kubeconfig file."
+  type        = string
+}
+
 variable "ssh_keys" {
   type        = list(string)
   description = "SSH public keys for user 'core'"
@@ -100,3 +128,26 @@ variable "cluster_domain_suffix" {
   type        = string
   default     = "cluster.local"
 }
+
+variable "enable_tls_bootstrap" {
+  description = "Enable TLS Bootstrap for Kubelet."
+  type        = bool
+}
+
+variable "cpu_manager_policy" {
+  description = "CPU Manager policy to use for the worker pool. Possible values: `none`, `static`."
+  default     = "none"
+  type        = string
+}
+
+variable "kube_reserved_cpu" {
+  description = "CPU cores reserved for the Worker Kubernetes components like kubelet, etc."
+  default     = "300m"
+  type        = string
+}
+
+variable "system_reserved_cpu" {
+  description = "CPU cores reserved for the host services like Docker, sshd, kernel, etc."
+  default     = "500m"
+  type        = string
+}
@@ -10,9 +10,9 @@ terraform {
       source  = "hashicorp/azurerm"
       version = "2.65.0"
     }
-    template = {
-      source  = "hashicorp/template"
-      version = "2.2.0"
+    random = {
+      source  = "hashicorp/random"
+      version = "3.0.0"
     }
   }
 }
@@ -34,6 +34,7 @@ resource "azurerm_virtual_machine_scale_set" "workers" {
     caching           = "ReadWrite"
     os_type           = "linux"
     managed_disk_type = "Standard_LRS"
+    disk_size_gb      = var.disk_size
   }
 
   os_profile {
@@ -101,19 +102,24 @@ resource "azurerm_monitor_autoscale_setting" "workers" {
 
 # Worker Ignition configs
 data "ct_config" "worker-ignition" {
-  content      = data.template_file.worker-config.rendered
-  pretty_print = false
-  snippets     = var.clc_snippets
-}
-
-# Worker Container Linux configs
-data "template_file" "worker-config" {
-  template = file("${path.module}/cl/worker.yaml.tmpl")
+  content = templatefile("${path.module}/cl/worker.yaml.tmpl", {
+    kubeconfig = var.enable_tls_bootstrap ? indent(10, templatefile("${path.module}/cl/bootstrap-kubeconfig.yaml.tmpl", {
+      token_id     = random_string.bootstrap_token_id[0].result
+      token_secret = random_string.bootstrap_token_secret[0].result
+      ca_cert      = var.ca_cert
+      server       = "https://${var.apiserver}:6443"
+    })) : indent(10, var.kubeconfig)
 
-  vars = {
-    kubeconfig             = indent(10, var.kubeconfig)
     ssh_keys               = jsonencode(var.ssh_keys)
     cluster_dns_service_ip = cidrhost(var.service_cidr, 10)
     cluster_domain_suffix  = var.cluster_domain_suffix
-  }
+    node_labels            = merge({ "node.kubernetes.io/node" = "" }, var.labels)
+    taints                 = var.taints
+    enable_tls_bootstrap   = var.enable_tls_bootstrap
+    cpu_manager_policy     = var.cpu_manager_policy
+    system_reserved_cpu    = var.system_reserved_cpu
+    kube_reserved_cpu      = var.kube_reserved_cpu
+  })
+  pretty_print = false
+  snippets     = var.clc_snippets
 }
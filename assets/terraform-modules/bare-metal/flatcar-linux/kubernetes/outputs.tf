@@ -2,6 +2,20 @@ output "kubeconfig-admin" {
   value = module.bootkube.kubeconfig-admin
 }
 
+# Outputs for worker pools.
+
+output "ca_cert" {
+  value = module.bootkube.ca_cert
+}
+
+output "apiserver" {
+  value = format("%s.%s", var.cluster_name, var.k8s_domain_name)
+}
+
+output "cluster_dns_service_ip" {
+  value = module.bootkube.cluster_dns_service_ip
+}
+
 # values.yaml content for all deployed charts.
 output "pod-checkpointer_values" {
   value = module.bootkube.pod-checkpointer_values
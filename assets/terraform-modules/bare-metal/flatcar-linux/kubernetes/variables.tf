@@ -43,16 +43,19 @@ variable "controller_domains" {
 variable "worker_names" {
   type        = list(string)
   description = "Ordered list of worker names (e.g. [node2, node3])"
+  default     = []
 }
 
 variable "worker_macs" {
   type        = list(string)
   description = "Ordered list of worker identifying MAC addresses (e.g. [52:54:00:b2:2f:86, 52:54:00:c3:61:77])"
+  default     = []
 }
 
 variable "worker_domains" {
   type        = list(string)
   description = "Ordered list of worker FQDNs (e.g. [node2.example.com, node3.example.com])"
+  default     = []
 }
 
 variable "clc_snippets" {
@@ -73,6 +76,15 @@ variable "labels" {
   default     = {}
 }
 
+variable "worker_bootstrap_tokens" {
+  type = list(object({
+    token_id     = string
+    token_secret = string
+  }))
+  description = "List of token-id and token-secret of each node in worker pools."
+  default     = []
+}
+
 # configuration
 
 variable "k8s_domain_name" {
@@ -222,6 +234,12 @@ variable "node_specific_labels" {
   default     = {}
 }
 
+variable "node_specific_install_disks" {
+  type        = map(string)
+  description = "Map from machine names to disk devices, which overrides install_disk for given machine."
+  default     = {}
+}
+
 variable "wipe_additional_disks" {
   type        = bool
   description = "Wipes any additional disks attached, if set to true"
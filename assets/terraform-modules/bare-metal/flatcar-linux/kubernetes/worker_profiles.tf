@@ -12,7 +12,7 @@ module "worker_profile" {
   kernel_args              = var.kernel_args
   kernel_console           = var.kernel_console
   installer_clc_snippets   = lookup(var.installer_clc_snippets, var.worker_names[count.index], [])
-  install_disk             = var.install_disk
+  install_disk             = lookup(var.node_specific_install_disks, var.worker_names[count.index], var.install_disk)
   install_to_smallest_disk = var.install_to_smallest_disk
   container_linux_oem      = var.container_linux_oem
   ssh_keys                 = var.ssh_keys
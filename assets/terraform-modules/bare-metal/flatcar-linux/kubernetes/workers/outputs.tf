@@ -0,0 +1,3 @@
+output "bootstrap_tokens" {
+  value = module.worker.*.bootstrap_token
+}
@@ -0,0 +1,178 @@
+variable "cluster_name" {
+  type        = string
+  description = "Unique cluster name"
+}
+
+variable "pool_name" {
+  type        = string
+  description = "Unique worker pool name"
+}
+
+variable "matchbox_http_endpoint" {
+  type        = string
+  description = "Matchbox HTTP read-only endpoint (e.g. http://matchbox.example.com:8080)"
+}
+
+variable "os_channel" {
+  type        = string
+  description = "Flatcar Container Linux channel to install from (stable, beta, alpha, edge)"
+  default     = "stable"
+}
+
+variable "os_version" {
+  type        = string
+  description = "Flatcar Container Linux version to install (for example '2191.5.0' - see https://www.flatcar-linux.org/releases/)"
+  default     = "current"
+}
+
+# machines
+
+variable "names" {
+  type        = list(string)
+  description = "Ordered list of worker names (e.g. [node2, node3])"
+}
+
+variable "macs" {
+  type        = list(string)
+  description = "Ordered list of worker identifying MAC addresses (e.g. [52:54:00:b2:2f:86, 52:54:00:c3:61:77])"
+}
+
+variable "domains" {
+  type        = list(string)
+  description = "Ordered list of worker FQDNs (e.g. [node2.example.com, node3.example.com])"
+}
+
+variable "clc_snippets" {
+  type        = list(string)
+  description = "List of Container Linux Config snippets for nodes in the worker pool"
+  default     = []
+}
+
+variable "installer_clc_snippets" {
+  type        = list(string)
+  description = "List of Container Linux Config snippets, applied for the PXE-booted installer OS"
+  default     = []
+}
+
+variable "labels" {
+  type        = map(string)
+  description = "Map of labels for worker nodes."
+  default     = {}
+}
+
+variable "taints" {
+  type        = map(string)
+  description = "Map of taints for worker nodes."
+  default     = {}
+}
+
+variable "node_specific_labels" {
+  type        = map(map(string))
+  description = "Map of node specific labels map."
+  default     = {}
+}
+
+variable "node_specific_install_disks" {
+  type        = map(string)
+  description = "Map from machine names to disk devices, which overrides install_disk for given machine."
+  default     = {}
+}
+
+# configuration
+
+variable "ssh_keys" {
+  type        = list(string)
+  description = "SSH public keys for user 'core'"
+}
+
+variable "asset_dir" {
+  description = "Path to a directory where generated assets should be placed (contains secrets)"
+  type        = string
+}
+
+variable "ca_cert" {
+  description = "Kubernetes CA certificate needed in the kubeconfig file."
+  type        = string
+}
+
+variable "apiserver" {
+  description = "Apiserver endpoint needed in the kubeconfig file."
+  type        = string
+}
+
+variable "cluster_dns_service_ip" {
+  description = "Must be set to `cluster_dns_service_ip` output by cluster"
+  type        = string
+}
+
+variable "cluster_domain_suffix" {
+  description = "Queries for domains with the suffix will be answered by coredns. Default is cluster.local (e.g. foo.default.svc.cluster.local) "
+  type        = string
+  default     = "cluster.local"
+}
+
+variable "download_protocol" {
+  type        = string
+  default     = "https"
+  description = "Protocol iPXE should use to download the kernel and initrd. Defaults to https, which requires iPXE compiled with crypto support. Unused if cached_install is true."
+}
+
+variable "cached_install" {
+  type        = bool
+  default     = false
+  description = "Whether the operating system should PXE boot and install from matchbox /assets cache. Note that the admin must have downloaded the os_version into matchbox assets."
+}
+
+variable "install_disk" {
+  type        = string
+  default     = "/dev/sda"
+  description = "Disk device to which the install profiles should install the operating system (e.g. /dev/sda)"
+}
+
+variable "install_to_smallest_disk" {
+  description = "Install Flatcar Container Linux to the smallest disk."
+  type        = bool
+  default     = false
+}
+
+variable "container_linux_oem" {
+  type        = string
+  default     = ""
+  description = "DEPRECATED: Specify an OEM image id to use as base for the installation (e.g. ami, vmware_raw, xen) or leave blank for the default image"
+}
+
+variable "kernel_args" {
+  description = "Additional kernel arguments to provide at PXE boot and in /usr/share/oem/grub.cfg."
+  type        = list(string)
+  default     = []
+}
+
+variable "kernel_console" {
+  description = "The kernel arguments to configure the console at PXE boot and in /usr/share/oem/grub.cfg."
+  type        = list(string)
+  default     = ["console=tty0", "console=ttyS0"]
+}
+
+variable "wipe_additional_disks" {
+  type        = bool
+  description = "Wipes any additional disks attached, if set to true"
+  default     = false
+}
+
+variable "pxe_commands" {
+  type        = string
+  default     = "echo 'you must (re)provision the node by booting via iPXE from http://MATCHBOX/boot.ipxe'; exit 1"
+  description = "shell commands to execute for PXE (re)provisioning, with access to the variables $mac (the MAC address), $name (the node name), and $domain (the domain name), e.g., 'bmc=bmc-$domain; ipmitool -H $bmc power off; ipmitool -H $bmc chassis bootdev pxe; ipmitool -H $bmc power on'"
+}
+
+variable "install_pre_reboot_cmds" {
+  type        = string
+  default     = "true"
+  description = "shell commands to execute on the provisioned host after installation finished and before reboot, e.g., docker run --privileged --net host --rm debian sh -c 'apt update && apt install -y ipmitool && ipmitool chassis bootdev disk options=persistent'"
+}
+
+variable "ignore_changes" {
+  description = "Enable to prevent workers from being reprovisioned on configuration changes"
+  type        = bool
+  default     = true
+}
@@ -0,0 +1,12 @@
+# Terraform version and plugin versions
+
+terraform {
+  required_version = ">= 0.13"
+
+  required_providers {
+    matchbox = {
+      source  = "poseidon/matchbox"
+      version = "0.4.1"
+    }
+  }
+}
@@ -0,0 +1,68 @@
+module "worker" {
+  source                 = "../../../../worker"
+  count                  = length(var.names)
+  count_index            = count.index
+  cluster_dns_service_ip = var.cluster_dns_service_ip
+  ssh_keys               = var.ssh_keys
+  cluster_domain_suffix  = var.cluster_domain_suffix
+  ca_cert                = var.ca_cert
+  apiserver              = var.apiserver
+  kubelet_labels         = merge({ "lokomotive.alpha.kinvolk.io/worker-pool" = var.pool_name }, lookup(var.node_specific_labels, var.names[count.index], {}), var.labels)
+  kubelet_taints         = var.taints
+  cluster_name           = var.cluster_name
+  set_standard_hostname  = false
+  clc_snippets = concat(var.clc_snippets, [
+    <<EOF
+filesystems:
+  - name: root
+    mount:
+      device: /dev/disk/by-label/ROOT
+      format: ext4
+      wipe_filesystem: true
+      label: ROOT
+storage:
+  files:
+    - path: /ignition_ran
+      filesystem: root
+      mode: 0644
+      contents:
+        inline: |
+          Flag file indicating that Ignition ran.
+          Should be deleted by the SSH step that checks it.
+    - path: /etc/hostname
+      filesystem: root
+      mode: 0644
+      contents:
+        inline: ${var.names[count.index]}
+EOF
+    ,
+  ])
+}
+
+module "worker_profile" {
+  source                   = "../../../../matchbox-flatcar"
+  count                    = length(var.names)
+  asset_dir                = var.asset_dir
+  node_name                = var.names[count.index]
+  node_mac                 = var.macs[count.index]
+  node_domain              = var.domains[count.index]
+  download_protocol        = var.download_protocol
+  os_channel               = var.os_channel
+  os_version               = var.os_version
+  http_endpoint            = var.matchbox_http_endpoint
+  kernel_args              = var.kernel_args
+  kernel_console           = var.kernel_console
+  installer_clc_snippets   = var.installer_clc_snippets
+  install_disk             = lookup(var.node_specific_install_disks, var.names[count.index], var.install_disk)
+  install_to_smallest_disk = var.install_to_smallest_disk
+  container_linux_oem      = var.container_linux_oem
+  ssh_keys                 = var.ssh_keys
+  ignition_clc_config      = module.worker[count.index].clc_config
+  cached_install           = var.cached_install
+  wipe_additional_disks    = var.wipe_additional_disks
+  pxe_commands             = var.pxe_commands
+  install_pre_reboot_cmds  = var.install_pre_reboot_cmds
+  ignore_changes           = var.ignore_changes
+  group_prefix             = var.cluster_name
+  profile_prefix           = format("%s-worker", var.cluster_name)
+}
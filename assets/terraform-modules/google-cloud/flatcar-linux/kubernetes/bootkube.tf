@@ -1,21 +1,47 @@
+locals {
+  api_server      = format("%s.%s", var.cluster_name, var.dns_zone)
+  api_server_port = 443
+}
+
 # Self-hosted Kubernetes assets (kubeconfig, manifests)
 module "bootkube" {
   source = "../../../bootkube"
 
-  cluster_name          = var.cluster_name
-  api_servers           = [format("%s.%s", var.cluster_name, var.dns_zone)]
-  etcd_servers          = google_dns_record_set.etcds.*.name
-  asset_dir             = var.asset_dir
-  network_mtu           = 1440
-  pod_cidr              = var.pod_cidr
-  service_cidr          = var.service_cidr
-  cluster_domain_suffix = var.cluster_domain_suffix
-  enable_reporting      = var.enable_reporting
-  enable_aggregation    = var.enable_aggregation
-  encrypt_pod_traffic   = var.encrypt_pod_traffic
-
-  // temporary
-  external_apiserver_port = 443
-
+  cluster_name                = var.cluster_name
+  api_servers                 = [local.api_server]
+  etcd_servers                = [for fqdn in google_dns_record_set.etcds.*.name : trimsuffix(fqdn, ".")]
+  etcd_endpoints              = google_compute_instance.controllers.*.network_interface.0.network_ip
+  asset_dir                   = var.asset_dir
+  network_mtu                 = var.network_mtu
+  pod_cidr                    = var.pod_cidr
+  service_cidr                = var.service_cidr
+  cluster_domain_suffix       = var.cluster_domain_suffix
+  enable_reporting            = var.enable_reporting
+  enable_aggregation          = var.enable_aggregation
+  kube_apiserver_extra_flags  = var.kube_apiserver_extra_flags
   certs_validity_period_hours = var.certs_validity_period_hours
+
+  # Global TCP proxy load balancer exposes kube-apiserver on port 443.
+  external_apiserver_port = local.api_server_port
+  controller_count        = var.controller_count
+
+  # Disable the self hosted kubelet.
+  disable_self_hosted_kubelet = var.disable_self_hosted_kubelet
+
+  # Block access to GCE metadata for all pods.
+  #
+  # https://cloud.google.com/compute/docs/metadata/overview
+  blocked_metadata_cidrs = ["169.254.169.254/32"]
+
+  bootstrap_tokens     = var.enable_tls_bootstrap ? concat([local.controller_bootstrap_token], var.worker_bootstrap_tokens) : []
+  enable_tls_bootstrap = var.enable_tls_bootstrap
+  encrypt_pod_traffic  = var.encrypt_pod_traffic
+
+  ignore_x509_cn_check = var.ignore_x509_cn_check
+
+  conntrack_max_per_core = var.conntrack_max_per_core
+
+  # Node Local DNS configuration.
+  enable_node_local_dns = var.enable_node_local_dns
+  node_local_dns_ip     = var.node_local_dns_ip
 }
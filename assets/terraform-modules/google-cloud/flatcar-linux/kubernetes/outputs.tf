@@ -2,44 +2,33 @@ output "kubeconfig-admin" {
   value = module.bootkube.kubeconfig-admin
 }
 
-# Outputs for Kubernetes Ingress
-
-output "ingress_static_ipv4" {
-  description = "Global IPv4 address for proxy load balancing to the nearest Ingress controller"
-  value       = google_compute_global_address.ingress-ipv4.address
-}
-
-output "ingress_static_ipv6" {
-  description = "Global IPv6 address for proxy load balancing to the nearest Ingress controller"
-  value       = google_compute_global_address.ingress-ipv6.address
-}
-
 # Outputs for worker pools
 
 output "network_name" {
-  value = google_compute_network.network.name
+  value       = google_compute_network.network.name
+  description = "Name of the network for creating worker instances"
 }
 
 output "kubeconfig" {
   value = module.bootkube.kubeconfig-kubelet
 }
 
-# Outputs for custom firewalling
-
-output "network_self_link" {
-  value = google_compute_network.network.self_link
+output "ca_cert" {
+  value = module.bootkube.ca_cert
 }
 
-# Outputs for custom load balancing
+output "apiserver" {
+  value = local.api_server
+}
 
-output "worker_instance_group" {
-  description = "Worker managed instance group full URL"
-  value       = module.workers.instance_group
+output "apiserver_port" {
+  value = local.api_server_port
 }
 
-output "worker_target_pool" {
-  description = "Worker target pool self link"
-  value       = module.workers.target_pool
+# Outputs for custom firewalling
+
+output "network_self_link" {
+  value = google_compute_network.network.self_link
 }
 
 # values.yaml content for all deployed charts.
@@ -66,3 +55,11 @@ output "calico_values" {
 output "lokomotive_values" {
   value = module.bootkube.lokomotive_values
 }
+
+output "bootstrap-secrets_values" {
+  value = module.bootkube.bootstrap-secrets_values
+}
+
+output "node-local-dns_values" {
+  value = module.bootkube.node-local-dns_values
+}
@@ -28,24 +28,12 @@ variable "controller_count" {
   description = "Number of controllers (i.e. masters)"
 }
 
-variable "worker_count" {
-  type        = number
-  default     = 1
-  description = "Number of workers"
-}
-
 variable "controller_type" {
   type        = string
   default     = "n1-standard-1"
   description = "Machine type for controllers (see `gcloud compute machine-types list`)"
 }
 
-variable "worker_type" {
-  type        = string
-  default     = "n1-standard-1"
-  description = "Machine type for controllers (see `gcloud compute machine-types list`)"
-}
-
 variable "os_image" {
   type        = string
   default     = "flatcar-stable"
@@ -58,26 +46,20 @@ variable "disk_size" {
   description = "Size of the disk in GB"
 }
 
-variable "worker_preemptible" {
-  type        = bool
-  default     = false
-  description = "If enabled, Compute Engine will terminate workers randomly within 24 hours"
-}
-
 variable "controller_clc_snippets" {
   type        = list(string)
   description = "Controller Container Linux Config snippets"
   default     = []
 }
 
-variable "worker_clc_snippets" {
-  type        = list(string)
-  description = "Worker Container Linux Config snippets"
-  default     = []
-}
-
 # configuration
 
+variable "network_mtu" {
+  type        = number
+  description = "Physical Network MTU. Google Cloud VPC networks use 1460 by default."
+  default     = 1460
+}
+
 variable "ssh_keys" {
   type        = list(string)
   description = "SSH public keys for user 'core'"
@@ -136,3 +118,47 @@ variable "encrypt_pod_traffic" {
   type        = bool
   default     = false
 }
+
+variable "disable_self_hosted_kubelet" {
+  description = "Disable the self hosted kubelet installed by default"
+  type        = bool
+}
+
+variable "enable_tls_bootstrap" {
+  description = "Enable TLS Bootstrap for Kubelet."
+  type        = bool
+}
+
+variable "worker_bootstrap_tokens" {
+  description = "List of token-id and token-secret of each node."
+  type        = list(any)
+}
+
+variable "kube_apiserver_extra_flags" {
+  description = "Extra flags passed to self-hosted kube-apiserver."
+  type        = list(string)
+  default     = []
+}
+
+variable "ignore_x509_cn_check" {
+  description = "Ignore CN checks in x509 certificates."
+  type        = bool
+  default     = false
+}
+
+variable "conntrack_max_per_core" {
+  description = "--conntrack-max-per-core value for kube-proxy. Maximum number of NAT connections to track per CPU core (0 to leave the limit as-is and ignore the conntrack-min kube-proxy flag)."
+  type        = number
+}
+
+variable "enable_node_local_dns" {
+  description = "Enable Node Local DNS on the cluster."
+  type        = bool
+  default     = false
+}
+
+variable "node_local_dns_ip" {
+  description = "Node Local DNS IP for the pods running on each node. This is the local IP pods can reach for name resolution."
+  type        = string
+  default     = "169.254.1.1"
+}
@@ -17,7 +17,12 @@ terraform {
       version = "2.2.0"
     }
     ct = {
-      source = "poseidon/ct"
+      source  = "poseidon/ct"
+      version = "0.8.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.0.0"
     }
   }
 }
@@ -1,3 +1,15 @@
+# Outputs for Kubernetes Ingress
+
+output "ingress_static_ipv4" {
+  description = "Global IPv4 address for proxy load balancing to the nearest Ingress controller"
+  value       = google_compute_global_address.ingress-ipv4.address
+}
+
+output "ingress_static_ipv6" {
+  description = "Global IPv6 address for proxy load balancing to the nearest Ingress controller"
+  value       = google_compute_global_address.ingress-ipv6.address
+}
+
 # Outputs for global load balancing
 
 output "instance_group" {
@@ -11,3 +23,7 @@ output "target_pool" {
   description = "Worker target pool self link"
   value       = google_compute_target_pool.workers.self_link
 }
+
+output "worker_bootstrap_token" {
+  value = local.worker_bootstrap_token
+}
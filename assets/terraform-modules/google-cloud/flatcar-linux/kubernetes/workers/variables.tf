@@ -52,6 +52,18 @@ variable "preemptible" {
   description = "If enabled, Compute Engine will terminate instances randomly within 24 hours"
 }
 
+variable "labels" {
+  type        = map(string)
+  description = "Map of custom labels for worker nodes."
+  default     = {}
+}
+
+variable "taints" {
+  type        = map(string)
+  default     = {}
+  description = "Map of custom taints for worker nodes."
+}
+
 # configuration
 
 variable "kubeconfig" {
@@ -59,6 +71,16 @@ variable "kubeconfig" {
   description = "Must be set to `kubeconfig` output by cluster"
 }
 
+variable "ca_cert" {
+  description = "Kubernetes CA certificate needed in the kubeconfig file."
+  type        = string
+}
+
+variable "apiserver" {
+  description = "Apiserver endpoint needed in the kubeconfig file."
+  type        = string
+}
+
 variable "ssh_keys" {
   type        = list(string)
   description = "SSH public keys for user 'core'"
@@ -87,6 +109,29 @@ variable "clc_snippets" {
   default     = []
 }
 
+variable "enable_tls_bootstrap" {
+  description = "Enable TLS Bootstrap for Kubelet."
+  type        = bool
+}
+
+variable "cpu_manager_policy" {
+  description = "CPU Manager policy to use for the worker pool. Possible values: `none`, `static`."
+  default     = "none"
+  type        = string
+}
+
+variable "kube_reserved_cpu" {
+  description = "CPU cores reserved for the Worker Kubernetes components like kubelet, etc."
+  default     = "300m"
+  type        = string
+}
+
+variable "system_reserved_cpu" {
+  description = "CPU cores reserved for the host services like Docker, sshd, kernel, etc."
+  default     = "500m"
+  type        = string
+}
+
 # unofficial, undocumented, unsupported, temporary
 
 variable "accelerator_type" {
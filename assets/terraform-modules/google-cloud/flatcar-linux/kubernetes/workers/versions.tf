@@ -6,12 +6,13 @@ terraform {
       source  = "hashicorp/google"
       version = "2.16.0"
     }
-    template = {
-      source  = "hashicorp/template"
-      version = "2.2.0"
-    }
     ct = {
-      source = "poseidon/ct"
+      source  = "poseidon/ct"
+      version = "0.8.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.0.0"
     }
   }
 }
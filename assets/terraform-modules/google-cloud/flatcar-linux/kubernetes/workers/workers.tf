@@ -68,19 +68,24 @@ resource "google_compute_instance_template" "worker" {
 
 # Worker Ignition config
 data "ct_config" "worker-ignition" {
-  content      = data.template_file.worker-config.rendered
-  pretty_print = false
-  snippets     = var.clc_snippets
-}
-
-# Worker Container Linux config
-data "template_file" "worker-config" {
-  template = file("${path.module}/cl/worker.yaml.tmpl")
+  content = templatefile("${path.module}/cl/worker.yaml.tmpl", {
+    kubeconfig = var.enable_tls_bootstrap ? indent(10, templatefile("${path.module}/cl/bootstrap-kubeconfig.yaml.tmpl", {
+      token_id     = random_string.bootstrap_token_id[0].result
+      token_secret = random_string.bootstrap_token_secret[0].result
+      ca_cert      = var.ca_cert
+      server       = "https://${var.apiserver}:443"
+    })) : indent(10, var.kubeconfig)
 
-  vars = {
-    kubeconfig             = indent(10, var.kubeconfig)
     ssh_keys               = jsonencode(var.ssh_keys)
     cluster_dns_service_ip = cidrhost(var.service_cidr, 10)
     cluster_domain_suffix  = var.cluster_domain_suffix
-  }
+    node_labels            = merge({ "node.kubernetes.io/node" = "" }, var.labels)
+    taints                 = var.taints
+    enable_tls_bootstrap   = var.enable_tls_bootstrap
+    cpu_manager_policy     = var.cpu_manager_policy
+    system_reserved_cpu    = var.system_reserved_cpu
+    kube_reserved_cpu      = var.kube_reserved_cpu
+  })
+  pretty_print = false
+  snippets     = var.clc_snippets
 }
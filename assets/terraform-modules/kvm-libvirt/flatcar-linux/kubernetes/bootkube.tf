@@ -1,34 +1,28 @@
+# Self-hosted Kubernetes assets (kubeconfig, manifests).
 module "bootkube" {
-  source       = "../../../bootkube"
-  cluster_name = var.cluster_name
-
-  # Cannot use cyclic dependencies on controllers or their DNS records
-  api_servers          = [data.template_file.controllernames[0].rendered]
-  api_servers_external = libvirt_domain.controller-machine.*.network_interface.0.addresses.0
-  api_servers_ips      = libvirt_domain.controller-machine.*.network_interface.0.addresses.0
-  etcd_servers         = data.template_file.controllernames.*.rendered
-  asset_dir            = var.asset_dir
-  network_mtu          = var.network_mtu
-
-  network_ip_autodetection_method = var.network_ip_autodetection_method
+  source = "../../../bootkube"
 
+  cluster_name          = var.cluster_name
+  api_servers           = [local.api_server]
+  api_servers_external  = local.controller_ips
+  api_servers_ips       = local.controller_ips
+  etcd_servers          = module.controller[0].etcd_servers
+  asset_dir             = var.asset_dir
+  network_mtu           = var.network_mtu
   pod_cidr              = var.pod_cidr
   service_cidr          = var.service_cidr
   cluster_domain_suffix = var.cluster_domain_suffix
   enable_reporting      = var.enable_reporting
   enable_aggregation    = var.enable_aggregation
   encrypt_pod_traffic   = var.encrypt_pod_traffic
+  controller_count      = var.controller_count
 
-  certs_validity_period_hours = var.certs_validity_period_hours
-}
+  network_ip_autodetection_method = var.network_ip_autodetection_method
 
-data "template_file" "controllernames" {
-  count    = var.controller_count
-  template = "$${cluster_name}-controller-$${index}.$${machine_domain}"
+  certs_validity_period_hours = var.certs_validity_period_hours
 
-  vars = {
-    index          = count.index
-    cluster_name   = var.cluster_name
-    machine_domain = var.machine_domain
-  }
+  bootstrap_tokens            = concat(module.controller.*.bootstrap_token, var.worker_bootstrap_tokens)
+  enable_tls_bootstrap        = true
+  disable_self_hosted_kubelet = false
+  conntrack_max_per_core      = var.conntrack_max_per_core
 }
@@ -1,3 +1,11 @@
+locals {
+  api_server = format("%s.%s", var.cluster_name, var.machine_domain)
+
+  # Controllers get static IP addresses, so DNS records for the Kubernetes API and etcd
+  # can be created together with the network.
+  controller_ips = [for i in range(var.controller_count) : cidrhost(var.node_ip_pool, 10 + i)]
+}
+
 resource "random_string" "volumepath" {
   length  = 6
   special = false
@@ -16,6 +24,54 @@ resource "libvirt_volume" "base" {
   format = "qcow2"
 }
 
+resource "libvirt_network" "vmnet" {
+  name      = var.cluster_name
+  mode      = "nat"
+  domain    = var.machine_domain
+  addresses = [var.node_ip_pool]
+
+  dns {
+    local_only = true
+
+    # Kubernetes API is served by all controllers.
+    dynamic "hosts" {
+      for_each = local.controller_ips
+
+      content {
+        hostname = local.api_server
+        ip       = hosts.value
+      }
+    }
+
+    dynamic "hosts" {
+      for_each = local.controller_ips
+
+      content {
+        hostname = format("%s-etcd%d.%s", var.cluster_name, hosts.key, var.machine_domain)
+        ip       = hosts.value
+      }
+    }
+  }
+}
+
+module "controller" {
+  source = "../../../controller"
+
+  count = var.controller_count
+
+  cluster_name           = var.cluster_name
+  controllers_count      = var.controller_count
+  dns_zone               = var.machine_domain
+  count_index            = count.index
+  cluster_dns_service_ip = module.bootkube.cluster_dns_service_ip
+  ssh_keys               = var.ssh_keys
+  cluster_domain_suffix  = var.cluster_domain_suffix
+  apiserver              = local.api_server
+  ca_cert                = module.bootkube.ca_cert
+  set_standard_hostname  = true
+  clc_snippets           = var.controller_clc_snippets
+}
+
 resource "libvirt_volume" "controller-disk" {
   name           = "${var.cluster_name}-controller-${count.index}.qcow2"
   count          = var.controller_count
@@ -28,19 +84,7 @@ resource "libvirt_ignition" "ignition" {
   name    = "${var.cluster_name}-controller-${count.index}-ignition"
   pool    = libvirt_pool.volumetmp.name
   count   = var.controller_count
-  content = data.ct_config.controller-ignitions[count.index].rendered
-}
-
-resource "libvirt_network" "vmnet" {
-  name      = var.cluster_name
-  mode      = "nat"
-  domain    = var.machine_domain
-  addresses = [var.node_ip_pool]
-
-  dns {
-    local_only = true
-    # can specify local names here
-  }
+  content = module.controller[count.index].clc_config
 }
 
 resource "libvirt_domain" "controller-machine" {
@@ -63,39 +107,7 @@ resource "libvirt_domain" "controller-machine" {
   network_interface {
     network_id     = libvirt_network.vmnet.id
     hostname       = "${var.cluster_name}-controller-${count.index}"
-    addresses      = [cidrhost(var.node_ip_pool, 10 + count.index)] # TODO: use as public addr in kubeconfig
+    addresses      = [local.controller_ips[count.index]]
     wait_for_lease = true
   }
 }
-
-data "ct_config" "controller-ignitions" {
-  count    = var.controller_count
-  content  = data.template_file.controller-configs[count.index].rendered
-  snippets = var.controller_clc_snippets
-}
-
-data "template_file" "controller-configs" {
-  count    = var.controller_count
-  template = file("${path.module}/cl/controller.yaml.tmpl")
-
-  vars = {
-    # Cannot use cyclic dependencies on controllers or their DNS records
-    etcd_name              = "${var.cluster_name}-controller-${count.index}"
-    etcd_domain            = "${var.cluster_name}-controller-${count.index}.${var.machine_domain}"
-    etcd_initial_cluster   = join(",", data.template_file.etcds.*.rendered)
-    kubeconfig             = indent(10, module.bootkube.kubeconfig-kubelet)
-    ssh_keys               = jsonencode(var.ssh_keys)
-    cluster_dns_service_ip = cidrhost(var.service_cidr, 10)
-    cluster_domain_suffix  = var.cluster_domain_suffix
-  }
-}
-
-data "template_file" "etcds" {
-  count    = var.controller_count
-  template = "$${cluster_name}-controller-$${index}=https://$${cluster_name}-controller-$${index}.${var.machine_domain}:2380" # as etcd_domain above
-
-  vars = {
-    index        = count.index
-    cluster_name = var.cluster_name
-  }
-}
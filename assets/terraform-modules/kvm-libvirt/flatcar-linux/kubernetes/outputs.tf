@@ -2,20 +2,26 @@ output "kubeconfig-admin" {
   value = module.bootkube.kubeconfig-admin
 }
 
+# Outputs for worker pools
+
 output "kubeconfig" {
   value = module.bootkube.kubeconfig-kubelet
 }
 
-output "machine_domain" {
-  value = var.machine_domain
+output "ca_cert" {
+  value = module.bootkube.ca_cert
+}
+
+output "apiserver" {
+  value = local.api_server
 }
 
-output "cluster_name" {
-  value = var.cluster_name
+output "cluster_dns_service_ip" {
+  value = module.bootkube.cluster_dns_service_ip
 }
 
-output "ssh_keys" {
-  value = var.ssh_keys
+output "network_name" {
+  value = libvirt_network.vmnet.name
 }
 
 output "libvirtpool" {
@@ -50,3 +56,7 @@ output "calico_values" {
 output "lokomotive_values" {
   value = module.bootkube.lokomotive_values
 }
+
+output "bootstrap-secrets_values" {
+  value = module.bootkube.bootstrap-secrets_values
+}
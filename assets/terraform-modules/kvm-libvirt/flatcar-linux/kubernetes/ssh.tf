@@ -1,4 +1,4 @@
-# Secure copy etcd TLS assets to controllers.
+# Secure copy etcd TLS assets and kubeconfig to controllers. Activates 'kubelet.service'.
 resource "null_resource" "copy-controller-secrets" {
   count = var.controller_count
 
@@ -9,6 +9,11 @@ resource "null_resource" "copy-controller-secrets" {
     timeout = "15m"
   }
 
+  provisioner "file" {
+    content     = module.controller[count.index].bootstrap_kubeconfig
+    destination = "$HOME/kubeconfig"
+  }
+
   provisioner "file" {
     content     = module.bootkube.etcd_ca_cert
     destination = "$HOME/etcd-client-ca.crt"
@@ -46,6 +51,14 @@ resource "null_resource" "copy-controller-secrets" {
 
   provisioner "remote-exec" {
     inline = [
+      "set -e",
+      "sudo mv $HOME/kubeconfig /etc/kubernetes/kubeconfig",
+      "sudo chown root:root /etc/kubernetes/kubeconfig",
+      "sudo chmod 600 /etc/kubernetes/kubeconfig",
+      "sudo systemctl stop etcd",
+      # Using "etcd/." copies the etcd/ folder recursively in an idempotent
+      # way. See https://unix.stackexchange.com/a/228637 for details.
+      "[ -d /etc/ssl/etcd ] && sudo cp -R /etc/ssl/etcd/. /etc/ssl/etcd.old && sudo rm -rf /etc/ssl/etcd",
       "sudo mkdir -p /etc/ssl/etcd/etcd",
       "sudo mv etcd-client* /etc/ssl/etcd/",
       "sudo cp /etc/ssl/etcd/etcd-client-ca.crt /etc/ssl/etcd/etcd/server-ca.crt",
@@ -56,21 +69,34 @@ resource "null_resource" "copy-controller-secrets" {
       "sudo mv etcd-peer.key /etc/ssl/etcd/etcd/peer.key",
       "sudo chown -R etcd:etcd /etc/ssl/etcd",
       "sudo chmod -R 500 /etc/ssl/etcd",
+      # Use stdbuf to disable the buffer while printing logs to make sure everything is transmitted back to
+      # Terraform before we return error. We should be able to remove it once
+      # https://github.com/hashicorp/terraform/issues/27121 is resolved.
+      "sudo systemctl start etcd || (stdbuf -i0 -o0 -e0 sudo journalctl -u etcd --no-pager; exit 1)",
     ]
   }
+
+  triggers = {
+    controller_id    = libvirt_domain.controller-machine[count.index].id
+    etcd_ca_cert     = module.bootkube.etcd_ca_cert
+    etcd_server_cert = module.bootkube.etcd_server_cert
+    etcd_peer_cert   = module.bootkube.etcd_peer_cert
+  }
 }
 
 # Secure copy bootkube assets to ONE controller and start bootkube to perform
 # one-time self-hosted cluster bootstrapping.
 resource "null_resource" "bootkube-start" {
+  # Without depends_on, this remote-exec may start before the kubeconfig copy.
+  # Terraform only does one task at a time, so it would try to bootstrap
+  # while no Kubelets are running.
   depends_on = [
-    module.bootkube,
     null_resource.copy-controller-secrets,
   ]
 
   connection {
     type    = "ssh"
-    host    = libvirt_domain.controller-machine[0].network_interface[0].addresses[0]
+    host    = libvirt_domain.controller-machine[0].network_interface.0.addresses.0
     user    = "core"
     timeout = "15m"
   }
@@ -83,7 +109,10 @@ resource "null_resource" "bootkube-start" {
   provisioner "remote-exec" {
     inline = [
       "sudo mv $HOME/assets /opt/bootkube",
-      "sudo systemctl start bootkube",
+      # Use stdbuf to disable the buffer while printing logs to make sure everything is transmitted back to
+      # Terraform before we return error. We should be able to remove it once
+      # https://github.com/hashicorp/terraform/issues/27121 is resolved.
+      "sudo systemctl start bootkube || (stdbuf -i0 -o0 -e0 sudo journalctl -u bootkube --no-pager; exit 1)",
     ]
   }
 }
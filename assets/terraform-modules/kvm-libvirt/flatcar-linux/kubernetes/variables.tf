@@ -118,3 +118,16 @@ variable "encrypt_pod_traffic" {
   type        = bool
   default     = false
 }
+
+variable "worker_bootstrap_tokens" {
+  type = list(object({
+    token_id     = string
+    token_secret = string
+  }))
+  description = "List of token-id and token-secret of each node."
+}
+
+variable "conntrack_max_per_core" {
+  description = "--conntrack-max-per-core value for kube-proxy. Maximum number of NAT connections to track per CPU core (0 to leave the limit as-is and ignore the conntrack-min kube-proxy flag)."
+  type        = number
+}
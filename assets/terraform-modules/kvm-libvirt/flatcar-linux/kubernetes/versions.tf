@@ -1,13 +1,24 @@
 # Terraform version and plugin versions
 
 terraform {
-  required_version = ">= 0.12.0"
+  required_version = ">= 0.13"
 
   required_providers {
-    ct       = "0.7.1"
-    null     = "3.0.0"
-    template = "2.2.0"
-    libvirt  = "0.6.0"
-    random   = "3.0.0"
+    ct = {
+      source  = "poseidon/ct"
+      version = "0.8.0"
+    }
+    null = {
+      source  = "hashicorp/null"
+      version = "3.1.0"
+    }
+    libvirt = {
+      source  = "dmacvicar/libvirt"
+      version = "0.6.2"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "3.0.0"
+    }
   }
 }
@@ -5,11 +5,6 @@ variable "cluster_name" {
   description = "Unique cluster name (prepended to dns_zone)"
 }
 
-variable "machine_domain" {
-  type        = string
-  description = "Machine domain"
-}
-
 variable "pool_name" {
   type        = string
   description = "Unique worker pool name (prepended to hostname)"
@@ -33,12 +28,21 @@ variable "virtual_memory" {
   description = "Virtual RAM in MB"
 }
 
-# TODO: migrate to `templatefile` when Terraform `0.12` is out and use `{% for ~}`
-# to avoid specifying `--node-labels` again when the var is empty.
 variable "labels" {
+  type        = map(string)
+  description = "Map of custom labels for worker nodes."
+  default     = {}
+}
+
+variable "taints" {
+  type        = map(string)
+  default     = {}
+  description = "Map of custom taints for worker nodes."
+}
+
+variable "network_name" {
   type        = string
-  default     = ""
-  description = "Custom labels to assign to worker nodes. Provide comma separated key=value pairs as labels. e.g. 'foo=oof,bar=,baz=zab'"
+  description = "Must be set to `network_name` output by cluster"
 }
 
 variable "libvirtpool" {
@@ -63,23 +67,22 @@ variable "clc_snippets" {
   default     = []
 }
 
-variable "kubeconfig" {
-  description = "Kubeconfig file"
+variable "ca_cert" {
+  description = "Kubernetes CA certificate needed in the kubeconfig file."
   type        = string
 }
 
-variable "ssh_keys" {
-  type        = list(string)
-  description = "SSH public keys for user 'core'"
+variable "apiserver" {
+  description = "Apiserver endpoint needed in the kubeconfig file."
+  type        = string
 }
 
-variable "service_cidr" {
-  description = <<EOD
-CIDR IPv4 range to assign Kubernetes services.
-The 1st IP will be reserved for kube_apiserver, the 10th IP will be reserved for coredns.
-EOD
-
+variable "cluster_dns_service_ip" {
+  type        = string
+  description = "Must be set to `cluster_dns_service_ip` output by cluster"
+}
 
-  type    = string
-  default = "10.2.0.0/16"
+variable "ssh_keys" {
+  type        = list(string)
+  description = "SSH public keys for user 'core'"
 }
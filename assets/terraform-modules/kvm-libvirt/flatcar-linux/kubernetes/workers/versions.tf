@@ -1,11 +1,12 @@
 # Terraform version and plugin versions
 
 terraform {
-  required_version = ">= 0.12.0"
+  required_version = ">= 0.13"
 
   required_providers {
-    ct       = "0.7.1"
-    template = "2.2.0"
-    libvirt  = "0.6.0"
+    libvirt = {
+      source  = "dmacvicar/libvirt"
+      version = "0.6.2"
+    }
   }
 }
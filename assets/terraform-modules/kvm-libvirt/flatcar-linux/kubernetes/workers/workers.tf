@@ -1,3 +1,22 @@
+module "worker" {
+  source = "../../../../worker"
+
+  count       = var.worker_count
+  count_index = count.index
+
+  # Used as a hostname prefix, so hostnames are unique across worker pools.
+  cluster_name           = "${var.cluster_name}-${var.pool_name}"
+  cluster_dns_service_ip = var.cluster_dns_service_ip
+  ssh_keys               = var.ssh_keys
+  cluster_domain_suffix  = var.cluster_domain_suffix
+  ca_cert                = var.ca_cert
+  apiserver              = var.apiserver
+  set_standard_hostname  = true
+  kubelet_labels         = merge({ "node.kubernetes.io/node" = "" }, var.labels)
+  kubelet_taints         = var.taints
+  clc_snippets           = var.clc_snippets
+}
+
 resource "libvirt_volume" "worker-disk" {
   name           = "${var.cluster_name}-${var.pool_name}-worker-${count.index}.qcow2"
   count          = var.worker_count
@@ -10,7 +29,7 @@ resource "libvirt_ignition" "ignition" {
   name    = "${var.cluster_name}-${var.pool_name}-worker-${count.index}-ignition"
   pool    = var.libvirtpool
   count   = var.worker_count
-  content = data.ct_config.worker-ignition[count.index].rendered
+  content = module.worker[count.index].clc_config
 }
 
 resource "libvirt_domain" "worker-machine" {
@@ -31,27 +50,8 @@ resource "libvirt_domain" "worker-machine" {
   }
 
   network_interface {
-    network_name   = var.cluster_name
+    network_name   = var.network_name
     hostname       = "${var.cluster_name}-${var.pool_name}-worker-${count.index}"
     wait_for_lease = true
   }
 }
-
-data "ct_config" "worker-ignition" {
-  count    = var.worker_count
-  content  = data.template_file.worker-config[count.index].rendered
-  snippets = var.clc_snippets
-}
-
-data "template_file" "worker-config" {
-  count    = var.worker_count
-  template = file("${path.module}/cl/worker.yaml.tmpl")
-
-  vars = {
-    domain_name            = "${var.cluster_name}-${var.pool_name}-worker-${count.index}.${var.machine_domain}"
-    kubeconfig             = indent(10, var.kubeconfig)
-    ssh_keys               = jsonencode(var.ssh_keys)
-    cluster_dns_service_ip = cidrhost(var.service_cidr, 10)
-    cluster_domain_suffix  = var.cluster_domain_suffix
-  }
-}
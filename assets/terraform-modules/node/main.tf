@@ -13,6 +13,7 @@ data "ct_config" "config" {
     kubelet_taints            = var.kubelet_taints
     kubelet_docker_extra_args = var.kubelet_docker_extra_args
     hostname                  = ""
+    provider_id               = ""
   })
 
   snippets = var.clc_snippets
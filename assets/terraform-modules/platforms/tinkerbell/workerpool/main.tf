@@ -1,3 +1,13 @@
+locals {
+  # Worker pool label must be kept in sync with the Cluster Autoscaler provider template nodes.
+  # Labels and taints are only given to spare workers, as changing them for existing workers
+  # would replace their machine configuration.
+  kubelet_labels = merge({
+    "node.kubernetes.io/node"                 = ""
+    "lokomotive.alpha.kinvolk.io/worker-pool" = var.name
+  }, var.labels)
+}
+
 module "worker" {
   source = "../../../worker"
 
@@ -49,3 +59,51 @@ resource "tinkerbell_workflow" "main" {
 EOF
   template  = tinkerbell_template.main[count.index].id
 }
+
+# Spare workers have templates, but no workflows. Workflows are created by the Cluster Autoscaler
+# provider, when it provisions idle hardware into the worker pool.
+module "spare_worker" {
+  source = "../../../worker"
+
+  count       = var.spare_count
+  count_index = var.node_count + count.index
+
+  cluster_name           = var.cluster_name
+  cluster_dns_service_ip = var.cluster_dns_service_ip
+  ssh_keys               = var.ssh_keys
+  cluster_domain_suffix  = var.cluster_domain_suffix
+  ca_cert                = var.ca_cert
+  apiserver              = var.apiserver
+  set_standard_hostname  = true
+  kubelet_labels         = local.kubelet_labels
+  kubelet_taints         = var.taints
+  provider_id            = "tinkerbell://${var.cluster_name}-worker-${var.name}-spare-${count.index}"
+  clc_snippets = concat(var.clc_snippets, [
+    <<EOF
+storage:
+  files:
+  - path: /etc/systemd/resolved.conf.d/dns_servers.conf
+    filesystem: root
+    mode: 0644
+    contents:
+      inline: |
+        [Resolve]
+        DNS=${var.host_dns_ip}
+        Domains=~.
+EOF
+    ,
+  ])
+}
+
+resource "tinkerbell_template" "spare" {
+  count = var.spare_count
+
+  name = "${var.cluster_name}-worker-${var.name}-spare-${count.index}"
+
+  content = templatefile("${path.module}/templates/flatcar-install.tmpl", {
+    ignition_config          = module.spare_worker[count.index].clc_config
+    flatcar_install_base_url = var.flatcar_install_base_url
+    os_version               = var.os_version
+    os_channel               = var.os_channel
+  })
+}
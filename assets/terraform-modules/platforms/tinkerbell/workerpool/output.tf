@@ -1,3 +1,3 @@
 output "bootstrap_tokens" {
-  value = module.worker.*.bootstrap_token
+  value = concat(module.worker.*.bootstrap_token, module.spare_worker.*.bootstrap_token)
 }
@@ -50,6 +50,12 @@ variable "node_count" {
   default     = 1
 }
 
+variable "spare_count" {
+  type        = number
+  description = "Number of additional nodes, which can be provisioned by the Cluster Autoscaler."
+  default     = 0
+}
+
 variable "cluster_dns_service_ip" {
   type        = string
   description = "IP address of cluster DNS Service. Passed to kubelet as --cluster_dns parameter."
@@ -83,3 +89,15 @@ variable "apiserver" {
   description = "Apiserver private endpoint needed in the kubeconfig file."
   type        = string
 }
+
+variable "labels" {
+  type        = map(string)
+  description = "Map of labels for worker nodes."
+  default     = {}
+}
+
+variable "taints" {
+  type        = map(string)
+  description = "Map of taints for worker nodes."
+  default     = {}
+}
@@ -37,6 +37,7 @@ data "ct_config" "config" {
     cluster_domain_suffix     = var.cluster_domain_suffix
     kubelet_docker_extra_args = []
     hostname                  = var.set_standard_hostname == true ? "${var.cluster_name}-worker-${var.count_index}" : ""
+    provider_id               = var.provider_id
     # Here we set default labels for worker nodes.
     kubelet_labels = length(var.kubelet_labels) > 0 ? var.kubelet_labels : {
       "node.kubernetes.io/node" = ""
@@ -73,3 +73,9 @@ variable "set_standard_hostname" {
   description = "Sets the hostname if true. Hostname is set as <cluster_name>-worker-<count_index>"
   default     = false
 }
+
+variable "provider_id" {
+  type        = string
+  description = "Provider ID of the node passed to kubelet --provider-id flag. Not set if empty."
+  default     = ""
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+)
+
+var (
+	autoscalerProviderListen      string
+	autoscalerProviderTLSCert     string
+	autoscalerProviderTLSKey      string
+	autoscalerProviderTLSClientCA string
+)
+
+var autoscalerProviderCmd = &cobra.Command{
+	Use:   "autoscaler-provider",
+	Short: "Serve Cluster Autoscaler external gRPC cloud provider",
+	Long: `Serve Cluster Autoscaler external gRPC cloud provider.
+The provider scales Tinkerbell worker pools with autoscaling configured by provisioning
+idle hardware registered in the Tinkerbell server. Tinkerbell server address is taken
+from TINKERBELL_GRPC_AUTHORITY and TINKERBELL_CERT_URL environment variables. Cluster
+kubeconfig is used to skip hardware still backing cluster nodes.
+
+The provider requires mutual TLS: server certificate, key and the CA certificate used
+to verify client certificates must be given. The cluster-autoscaler component configured
+with 'externalgrpc' provider must be able to reach the listen address and present
+a client certificate signed by that CA.`,
+	Args: cobra.NoArgs,
+	Run:  runAutoscalerProvider,
+}
+
+func init() { //nolint:gochecknoinits
+	RootCmd.AddCommand(autoscalerProviderCmd)
+
+	pf := autoscalerProviderCmd.PersistentFlags()
+	pf.StringVar(&autoscalerProviderListen, "listen", ":8086", "Address to listen on")
+	pf.StringVar(&autoscalerProviderTLSCert, "tls-cert-file", "", "Path to the server TLS certificate (required)")
+	pf.StringVar(&autoscalerProviderTLSKey, "tls-key-file", "", "Path to the server TLS key (required)")
+	pf.StringVar(&autoscalerProviderTLSClientCA, "tls-client-ca-file", "", "Path to the CA certificate used to verify client certificates (required)") //nolint:lll
+}
+
+func runAutoscalerProvider(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl autoscaler-provider",
+	})
+
+	options := cluster.AutoscalerProviderOptions{
+		ConfigPaths:     lokocfgPaths(),
+		ValuesPath:      viper.GetString("lokocfg-vars"),
+		ListenAddress:   autoscalerProviderListen,
+		TLSCertFile:     autoscalerProviderTLSCert,
+		TLSKeyFile:      autoscalerProviderTLSKey,
+		TLSClientCAFile: autoscalerProviderTLSClientCA,
+	}
+
+	if err := cluster.ServeAutoscalerProvider(contextLogger, options); err != nil {
+		contextLogger.Fatalf("Serving autoscaler provider failed: %v", err)
+	}
+}
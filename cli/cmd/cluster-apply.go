@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,6 +30,7 @@ var (
 	skipPreUpdateHealthCheck bool
 	skipControlPlaneUpdate   bool
 	upgradeKubelets          bool
+	fromPhase                string
 )
 
 var clusterApplyCmd = &cobra.Command{
@@ -35,7 +38,11 @@ var clusterApplyCmd = &cobra.Command{
 	Short: "Deploy or update a cluster",
 	Long: `Deploy or update a cluster.
 Deploys a cluster if it isn't deployed, otherwise updates it.
-Unless explicitly skipped, components listed in the configuration are applied as well.`,
+Unless explicitly skipped, components listed in the configuration are applied as well.
+
+Apply runs in the following phases: ` + strings.Join(cluster.ApplyPhases(), ", ") + `.
+Progress is recorded in the assets directory. If apply fails, the next run with unchanged
+configuration resumes from the failed phase. Use --from-phase to start from a given phase.`,
 	Run: runClusterApply,
 }
 
@@ -52,6 +59,7 @@ func init() {
 		"Skip updating the control plane (not recommended)")
 
 	pf.BoolVarP(&upgradeKubelets, "upgrade-kubelets", "", true, "Upgrade self-hosted kubelets")
+	pf.StringVarP(&fromPhase, "from-phase", "", "", "Start from a given phase, skipping the previous ones")
 }
 
 func runClusterApply(cmd *cobra.Command, args []string) {
@@ -66,8 +74,9 @@ func runClusterApply(cmd *cobra.Command, args []string) {
 		SkipComponents:           skipComponents,
 		SkipPreUpdateHealthCheck: skipPreUpdateHealthCheck,
 		SkipControlPlaneUpdate:   skipControlPlaneUpdate,
+		FromPhase:                fromPhase,
 		Verbose:                  verbose,
-		ConfigPath:               viper.GetString("lokocfg"),
+		ConfigPaths:              lokocfgPaths(),
 		ValuesPath:               viper.GetString("lokocfg-vars"),
 	}
 
@@ -45,10 +45,10 @@ func runClusterCertificateRotate(cmd *cobra.Command, args []string) {
 	})
 
 	options := cluster.CertificateRotateOptions{
-		Confirm:    confirm,
-		Verbose:    verbose,
-		ConfigPath: viper.GetString("lokocfg"),
-		ValuesPath: viper.GetString("lokocfg-vars"),
+		Confirm:     confirm,
+		Verbose:     verbose,
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
 	}
 
 	if err := cluster.RotateCertificates(contextLogger, options); err != nil {
@@ -44,10 +44,10 @@ func runClusterDestroy(cmd *cobra.Command, args []string) {
 	})
 
 	options := cluster.DestroyOptions{
-		Confirm:    confirm,
-		Verbose:    verbose,
-		ConfigPath: viper.GetString("lokocfg"),
-		ValuesPath: viper.GetString("lokocfg-vars"),
+		Confirm:     confirm,
+		Verbose:     verbose,
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
 	}
 
 	if err := cluster.Destroy(contextLogger, options); err != nil {
@@ -0,0 +1,84 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+	"github.com/kinvolk/lokomotive/pkg/sshutil"
+)
+
+var (
+	etcdRestoreHosts []string
+	sshUser          string
+	sshInsecure      bool
+)
+
+var clusterEtcdRestoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Short: "Restore etcd database from a snapshot",
+	Long: `Restore etcd database from a snapshot.
+The snapshot can be either a local file or an s3://<bucket>/<key> URL printed by
+'lokoctl cluster etcd snapshot'.
+
+Restore connects to all controller nodes over SSH, using keys from the SSH agent,
+stops etcd on all of them, re-seeds the data directories from the snapshot and
+starts etcd again. The existing data directories are kept as backups on the nodes.
+All changes made to the cluster after the snapshot was taken are lost.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runClusterEtcdRestore,
+}
+
+func init() { //nolint:gochecknoinits
+	clusterEtcdCmd.AddCommand(clusterEtcdRestoreCmd)
+
+	pf := clusterEtcdRestoreCmd.PersistentFlags()
+	pf.BoolVarP(&verbose, "verbose", "v", false, "Show output from Terraform")
+	pf.BoolVarP(&confirm, "confirm", "", false, "Restore etcd without asking for confirmation")
+	pf.StringSliceVarP(&etcdRestoreHosts, "controller-address", "", nil,
+		"Addresses of the controller nodes to connect to (default: etcd endpoint hostnames)")
+	pf.StringVarP(&sshUser, "ssh-user", "", sshutil.DefaultUser, "User to log in as on the controller nodes")
+	pf.BoolVarP(&sshInsecure, "ssh-insecure-ignore-host-key", "", false,
+		"Do not verify SSH host keys of the controller nodes against known_hosts file")
+}
+
+func runClusterEtcdRestore(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl cluster etcd restore",
+		"args":    args,
+	})
+
+	options := cluster.EtcdRestoreOptions{
+		Confirm:     confirm,
+		Verbose:     verbose,
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
+		Snapshot:    args[0],
+		Hosts:       etcdRestoreHosts,
+		SSH: sshutil.Config{
+			User:                  sshUser,
+			InsecureIgnoreHostKey: sshInsecure,
+		},
+	}
+
+	if err := cluster.EtcdRestore(contextLogger, options); err != nil {
+		contextLogger.Fatalf("Restoring etcd failed: %v", err)
+	}
+
+	contextLogger.Info("Etcd restored successfully")
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+	"github.com/kinvolk/lokomotive/pkg/sshutil"
+)
+
+var (
+	etcdSnapshotFile  string
+	etcdSnapshotHosts []string
+)
+
+var clusterEtcdSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Take a snapshot of etcd database",
+	Long: `Take a snapshot of etcd database.
+The snapshot is taken on a controller node using etcdctl. lokoctl connects to the
+controller nodes over SSH, using keys from the SSH agent, and copies the snapshot from
+the first node where taking it succeeds.
+
+If the S3 backend is configured, the snapshot is uploaded to the backend bucket, next to
+the Terraform state, using server-side encryption. Otherwise, it is stored in the
+etcd-snapshots directory in the assets directory. Use --file to store the snapshot in a specific local file.`,
+	Run: runClusterEtcdSnapshot,
+}
+
+func init() { //nolint:gochecknoinits
+	clusterEtcdCmd.AddCommand(clusterEtcdSnapshotCmd)
+
+	pf := clusterEtcdSnapshotCmd.PersistentFlags()
+	pf.BoolVarP(&verbose, "verbose", "v", false, "Show output from Terraform")
+	pf.StringVarP(&etcdSnapshotFile, "file", "", "", "Store the snapshot in a given local file")
+	pf.StringSliceVarP(&etcdSnapshotHosts, "controller-address", "", nil,
+		"Addresses of the controller nodes to connect to (default: etcd endpoint hostnames)")
+	pf.StringVarP(&sshUser, "ssh-user", "", sshutil.DefaultUser, "User to log in as on the controller nodes")
+	pf.BoolVarP(&sshInsecure, "ssh-insecure-ignore-host-key", "", false,
+		"Do not verify SSH host keys of the controller nodes against known_hosts file")
+}
+
+func runClusterEtcdSnapshot(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl cluster etcd snapshot",
+		"args":    args,
+	})
+
+	options := cluster.EtcdSnapshotOptions{
+		Verbose:     verbose,
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
+		File:        etcdSnapshotFile,
+		Hosts:       etcdSnapshotHosts,
+		SSH: sshutil.Config{
+			User:                  sshUser,
+			InsecureIgnoreHostKey: sshInsecure,
+		},
+	}
+
+	result, err := cluster.EtcdSnapshot(contextLogger, options)
+	if err != nil {
+		contextLogger.Fatalf("Taking etcd snapshot failed: %v", err)
+	}
+
+	if err := printResult(result); err != nil {
+		contextLogger.Fatalf("Printing snapshot details failed: %v", err)
+	}
+}
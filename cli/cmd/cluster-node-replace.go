@@ -0,0 +1,78 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+	"github.com/kinvolk/lokomotive/pkg/sshutil"
+)
+
+var clusterNodeReplaceCmd = &cobra.Command{
+	Use:   "replace NODE_NAME",
+	Short: "Reprovision a worker node",
+	Long: `Reprovision a worker node.
+The node is cordoned and drained, kubelet on the node is stopped over SSH, using keys from
+the SSH agent, and the node is removed from the cluster. Then Terraform resources of the
+machine are tainted and the cluster is applied, which reinstalls the operating system on
+the machine. The command waits until the reinstalled node joins the cluster and becomes ready.
+
+On bare metal, the machine is reinstalled using PXE boot, so pxe_commands must be configured.
+On Tinkerbell without sandbox, the machine is rebooted over SSH to run the new workflow.
+
+Supported only on bare metal and Tinkerbell platforms. On Tinkerbell, the node may also be
+given by its IP address.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runClusterNodeReplace,
+}
+
+func init() { //nolint:gochecknoinits
+	clusterNodeCmd.AddCommand(clusterNodeReplaceCmd)
+
+	pf := clusterNodeReplaceCmd.PersistentFlags()
+	pf.BoolVarP(&confirm, "confirm", "", false, "Replace node without asking for confirmation")
+	pf.BoolVarP(&verbose, "verbose", "v", false, "Show output from Terraform")
+	pf.BoolVarP(&forceDrain, "force-drain", "", false, "Evict pods not managed by any controller when draining the node")
+	pf.StringVarP(&sshUser, "ssh-user", "", sshutil.DefaultUser, "User to log in as on the node")
+	pf.BoolVarP(&sshInsecure, "ssh-insecure-ignore-host-key", "", false,
+		"Do not verify SSH host key of the node against known_hosts file")
+}
+
+func runClusterNodeReplace(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl cluster node replace",
+		"args":    args,
+	})
+
+	options := cluster.NodeReplaceOptions{
+		Confirm:     confirm,
+		ForceDrain:  forceDrain,
+		Verbose:     verbose,
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
+		Name:        args[0],
+		SSH: sshutil.Config{
+			User:                  sshUser,
+			InsecureIgnoreHostKey: sshInsecure,
+		},
+	}
+
+	if err := cluster.ReplaceNode(contextLogger, options); err != nil {
+		contextLogger.Fatalf("Replacing node failed: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+)
+
+var planReportFile string
+
+var clusterPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show changes which would be made by cluster apply",
+	Long: `Show changes which would be made by cluster apply.
+Runs Terraform plan for the infrastructure, dry-runs the controlplane Helm releases upgrade
+and compares rendered components with the deployed ones. Nothing is changed in the cluster.
+All changes are printed as a single report. Use --output json or --output yaml
+to get a machine-readable report.`,
+	Run: runClusterPlan,
+}
+
+//nolint:gochecknoinits
+func init() {
+	clusterCmd.AddCommand(clusterPlanCmd)
+	pf := clusterPlanCmd.PersistentFlags()
+	pf.BoolVarP(&verbose, "verbose", "v", false, "Show output from Terraform")
+	pf.BoolVarP(&upgradeKubelets, "upgrade-kubelets", "", true, "Include self-hosted kubelets in the plan")
+	pf.StringVarP(&planReportFile, "report-file", "", "", "Write the report to a file instead of standard output")
+}
+
+func runClusterPlan(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl cluster plan",
+		"args":    args,
+	})
+
+	options := cluster.PlanOptions{
+		UpgradeKubelets: upgradeKubelets,
+		Verbose:         verbose,
+		ConfigPaths:     lokocfgPaths(),
+		ValuesPath:      viper.GetString("lokocfg-vars"),
+	}
+
+	report, err := cluster.Plan(contextLogger, options)
+	if err != nil {
+		contextLogger.Fatalf("Planning cluster changes failed: %v", err)
+	}
+
+	if planReportFile == "" {
+		if err := printResult(report); err != nil {
+			contextLogger.Fatalf("Printing report failed: %v", err)
+		}
+
+		return
+	}
+
+	f, err := os.OpenFile(planReportFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		contextLogger.Fatalf("Opening report file %q failed: %v", planReportFile, err)
+	}
+
+	if err := writeResult(f, viper.GetString("output"), report); err != nil {
+		contextLogger.Fatalf("Writing report to %q failed: %v", planReportFile, err)
+	}
+
+	if err := f.Close(); err != nil {
+		contextLogger.Fatalf("Closing report file %q failed: %v", planReportFile, err)
+	}
+}
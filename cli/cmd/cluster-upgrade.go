@@ -0,0 +1,78 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+)
+
+var forceDrain bool
+
+var clusterUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade the controlplane of a Lokomotive cluster",
+	Long: `Upgrade the controlplane of a Lokomotive cluster to the version shipped with lokoctl.
+
+Deployed controlplane chart versions are detected from the Helm release history and
+the Kubernetes version skew policy is checked before making any changes. Infrastructure
+changes planned by Terraform and the chart versions are shown before asking for confirmation.
+
+Terraform changes are applied first, as they generate values for the controlplane charts.
+Controlplane charts are then upgraded one by one and their workloads are restarted and
+waited for.
+
+Kubelets are upgraded last, node by node. Each node is cordoned and drained before its
+kubelet is replaced and uncordoned once the new kubelet is ready.
+
+If the upgrade gets interrupted, run the command again to resume it.`,
+	Run: runClusterUpgrade,
+}
+
+func init() { //nolint:gochecknoinits
+	clusterCmd.AddCommand(clusterUpgradeCmd)
+
+	pf := clusterUpgradeCmd.PersistentFlags()
+	pf.BoolVarP(&confirm, "confirm", "", false, "Upgrade cluster without asking for confirmation")
+	pf.BoolVarP(&verbose, "verbose", "v", false, "Show output from Terraform")
+	pf.BoolVarP(&upgradeKubelets, "upgrade-kubelets", "", true, "Upgrade kubelets node by node")
+	pf.BoolVarP(&forceDrain, "force-drain", "", false, "Evict pods not managed by any controller when draining nodes")
+}
+
+func runClusterUpgrade(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl cluster upgrade",
+		"args":    args,
+	})
+
+	options := cluster.UpgradeOptions{
+		Confirm:         confirm,
+		UpgradeKubelets: upgradeKubelets,
+		ForceDrain:      forceDrain,
+		Verbose:         verbose,
+		ConfigPaths:     lokocfgPaths(),
+		ValuesPath:      viper.GetString("lokocfg-vars"),
+		PrintPlan: func(plan *cluster.UpgradePlan) error {
+			return printResult(plan)
+		},
+	}
+
+	if err := cluster.Upgrade(contextLogger, options); err != nil {
+		contextLogger.Fatalf("Upgrading cluster failed: %v", err)
+	}
+}
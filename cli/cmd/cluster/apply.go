@@ -20,6 +20,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/kinvolk/lokomotive/internal"
+	"github.com/kinvolk/lokomotive/pkg/config"
 	"github.com/kinvolk/lokomotive/pkg/helm"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 	"github.com/kinvolk/lokomotive/pkg/lokomotive"
@@ -33,9 +34,12 @@ type ApplyOptions struct {
 	SkipComponents           bool
 	SkipPreUpdateHealthCheck bool
 	SkipControlPlaneUpdate   bool
-	Verbose                  bool
-	ConfigPath               string
-	ValuesPath               string
+	// FromPhase is the name of the phase to start from. If empty, apply resumes
+	// from the last unfinished phase or starts from the beginning.
+	FromPhase   string
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
 }
 
 func removeKubeletChart(charts []helm.LokomotiveChart) []helm.LokomotiveChart {
@@ -55,12 +59,15 @@ func removeKubeletChart(charts []helm.LokomotiveChart) []helm.LokomotiveChart {
 
 // Apply applies cluster configuration together with components.
 //
-//nolint:funlen
+// Apply runs in phases, see ApplyPhases(). Progress is recorded in the asset directory,
+// so when apply fails, the next run with unchanged configuration resumes from the failed phase.
+//
+//nolint:funlen,gocognit
 func Apply(contextLogger *log.Entry, options ApplyOptions) error {
 	cc := clusterConfig{
-		verbose:    options.Verbose,
-		configPath: options.ConfigPath,
-		valuesPath: options.ValuesPath,
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
 	}
 
 	c, err := cc.initialize(contextLogger)
@@ -73,6 +80,43 @@ func Apply(contextLogger *log.Entry, options ApplyOptions) error {
 		return fmt.Errorf("checking if cluster exists: %w", err)
 	}
 
+	checksum, err := config.Checksum(options.ConfigPaths, options.ValuesPath)
+	if err != nil {
+		return fmt.Errorf("calculating configuration checksum: %w", err)
+	}
+
+	progress, err := loadApplyProgress(c.assetDir)
+	if err != nil {
+		return fmt.Errorf("loading apply progress: %w", err)
+	}
+
+	start, err := startPhase(ApplyPhases(), progress, checksum, options.FromPhase)
+	if err != nil {
+		return fmt.Errorf("selecting phase to start from: %w", err)
+	}
+
+	resuming := start > 0 && progress.resumable(checksum)
+
+	switch {
+	case options.FromPhase != "":
+		fmt.Printf("\nStarting cluster apply from phase %q.\n", options.FromPhase)
+	case resuming:
+		fmt.Printf("\nPrevious cluster apply did not finish, resuming from phase %q.\n", ApplyPhases()[start])
+	case len(progress.CompletedPhases) > 0:
+		fmt.Printf("\nConfiguration changed since the previous unfinished cluster apply, starting from the beginning.\n")
+	}
+
+	if resuming {
+		// The cluster may have been created by the unfinished apply, so rely on the state
+		// from before it started.
+		exists = progress.ClusterExisted
+	} else {
+		progress = &applyProgress{
+			ConfigChecksum: checksum,
+			ClusterExisted: exists,
+		}
+	}
+
 	// Prepare for getting kubeconfig.
 	kg := kubeconfigGetter{
 		platformRequired: true,
@@ -81,9 +125,22 @@ func Apply(contextLogger *log.Entry, options ApplyOptions) error {
 
 	var kubeconfig []byte
 
+	// Kubeconfig is only available once the infrastructure is created, so fetch it lazily.
+	getKubeconfig := func() error {
+		if kubeconfig != nil {
+			return nil
+		}
+
+		kubeconfig, err = kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
+		if err != nil {
+			return fmt.Errorf("getting kubeconfig: %v", err)
+		}
+
+		return nil
+	}
+
 	// Prepare controlplane updater.
 	cu := controlplaneUpdater{
-		kubeconfig:    kubeconfig,
 		assetDir:      c.assetDir,
 		contextLogger: *contextLogger,
 		ex:            c.terraformExecutor,
@@ -100,7 +157,9 @@ func Apply(contextLogger *log.Entry, options ApplyOptions) error {
 		}
 	}
 
-	if exists && !options.Confirm {
+	// Changes to the infrastructure are only made when running the infrastructure phase,
+	// so ask for the confirmation only then.
+	if exists && !options.Confirm && start <= phaseIndex(ApplyPhaseInfrastructure) {
 		// TODO: We could plan to a file and use it when installing.
 		if err := c.terraformExecutor.Plan(); err != nil {
 			return fmt.Errorf("reconciling cluster state: %v", err)
@@ -113,73 +172,152 @@ func Apply(contextLogger *log.Entry, options ApplyOptions) error {
 		}
 	}
 
-	if exists && !options.SkipPreUpdateHealthCheck && !c.platform.Meta().Managed {
-		var err error
-
-		cu.kubeconfig, err = kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
-		if err != nil {
-			return fmt.Errorf("getting kubeconfig: %v", err)
-		}
-
-		for _, c := range charts {
-			if err := cu.ensureComponent(c.Name, c.Namespace); err != nil {
-				return fmt.Errorf("ensuring controlplane component %q: %w", c.Name, err)
-			}
-		}
-	}
-
-	if err := c.platform.Apply(&c.terraformExecutor); err != nil {
-		return fmt.Errorf("applying platform: %v", err)
-	}
+	phases := []applyPhase{
+		{
+			name: ApplyPhaseHealthCheck,
+			run: func() error {
+				if !exists || options.SkipPreUpdateHealthCheck || c.platform.Meta().Managed {
+					return nil
+				}
 
-	fmt.Printf("\nYour configurations are stored in %s\n", c.assetDir)
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
 
-	kubeconfig, err = kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
-	if err != nil {
-		return fmt.Errorf("getting kubeconfig: %v", err)
-	}
+				cu.kubeconfig = kubeconfig
 
-	// Update all the pre installed namespaces with lokomotive specific label.
-	// `lokomotive.kinvolk.io/name: <namespace_name>`.
-	if err := updateInstalledNamespaces(kubeconfig); err != nil {
-		return fmt.Errorf("updating installed namespace: %v", err)
-	}
-
-	// Do controlplane upgrades only if cluster already exists and it is not a managed platform.
-	if exists && !options.SkipControlPlaneUpdate && !c.platform.Meta().Managed {
-		fmt.Printf("\nEnsuring that cluster controlplane is up to date.\n")
+				for _, c := range charts {
+					if err := cu.ensureComponent(c.Name, c.Namespace); err != nil {
+						return fmt.Errorf("ensuring controlplane component %q: %w", c.Name, err)
+					}
+				}
 
-		if err := c.upgradeControlPlane(contextLogger, kubeconfig, options.UpgradeKubelets); err != nil {
-			return fmt.Errorf("running controlplane upgrade: %v", err)
-		}
-	}
-
-	if ph, ok := c.platform.(platform.PlatformWithPostApplyHook); ok {
-		if err := ph.PostApplyHook(kubeconfig); err != nil {
-			return fmt.Errorf("running platform post install hook: %v", err)
-		}
-	}
+				return nil
+			},
+		},
+		{
+			name: ApplyPhaseInfrastructure,
+			run: func() error {
+				if err := c.platform.Apply(&c.terraformExecutor); err != nil {
+					return fmt.Errorf("applying platform: %v", err)
+				}
 
-	if err := verifyCluster(kubeconfig, c.platform.Meta().ExpectedNodes); err != nil {
-		return fmt.Errorf("verifying cluster: %v", err)
-	}
+				fmt.Printf("\nYour configurations are stored in %s\n", c.assetDir)
 
-	if options.SkipComponents {
-		return nil
-	}
+				// Kubeconfig may change when the infrastructure gets updated.
+				kubeconfig = nil
 
-	componentObjects, err := componentNamesToObjects(selectComponentNames(nil, *c.lokomotiveConfig.RootConfig))
-	if err != nil {
-		return fmt.Errorf("getting component objects: %w", err)
+				return nil
+			},
+		},
+		{
+			name: ApplyPhaseNamespaces,
+			run: func() error {
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
+
+				// Update all the pre installed namespaces with lokomotive specific label.
+				// `lokomotive.kinvolk.io/name: <namespace_name>`.
+				if err := updateInstalledNamespaces(kubeconfig); err != nil {
+					return fmt.Errorf("updating installed namespace: %v", err)
+				}
+
+				return nil
+			},
+		},
+		{
+			name: ApplyPhaseControlPlane,
+			run: func() error {
+				// Do controlplane upgrades only if cluster already exists and it is not a managed platform.
+				if !exists || options.SkipControlPlaneUpdate || c.platform.Meta().Managed {
+					return nil
+				}
+
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
+
+				fmt.Printf("\nEnsuring that cluster controlplane is up to date.\n")
+
+				if err := c.upgradeControlPlane(contextLogger, kubeconfig, options.UpgradeKubelets); err != nil {
+					return fmt.Errorf("running controlplane upgrade: %v", err)
+				}
+
+				return nil
+			},
+		},
+		{
+			name: ApplyPhasePostApplyHook,
+			run: func() error {
+				ph, ok := c.platform.(platform.PlatformWithPostApplyHook)
+				if !ok {
+					return nil
+				}
+
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
+
+				if err := ph.PostApplyHook(kubeconfig); err != nil {
+					return fmt.Errorf("running platform post install hook: %v", err)
+				}
+
+				return nil
+			},
+		},
+		{
+			name: ApplyPhaseVerify,
+			run: func() error {
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
+
+				if err := verifyCluster(kubeconfig, c.platform.Meta().ExpectedNodes); err != nil {
+					return fmt.Errorf("verifying cluster: %v", err)
+				}
+
+				return nil
+			},
+		},
+		{
+			name: ApplyPhaseComponents,
+			run: func() error {
+				if options.SkipComponents {
+					return nil
+				}
+
+				if err := getKubeconfig(); err != nil {
+					return err
+				}
+
+				componentObjects, err := componentNamesToObjects(selectComponentNames(nil, *c.lokomotiveConfig.RootConfig))
+				if err != nil {
+					return fmt.Errorf("getting component objects: %w", err)
+				}
+
+				contextLogger.Println("Applying component configuration")
+
+				if err := applyComponents(c.lokomotiveConfig, kubeconfig, componentObjects); err != nil {
+					return fmt.Errorf("applying component configuration: %v", err)
+				}
+
+				return nil
+			},
+		},
 	}
 
-	contextLogger.Println("Applying component configuration")
+	return runApplyPhases(phases, start, progress, c.assetDir)
+}
 
-	if err := applyComponents(c.lokomotiveConfig, kubeconfig, componentObjects); err != nil {
-		return fmt.Errorf("applying component configuration: %v", err)
+func phaseIndex(name string) int {
+	for i, p := range ApplyPhases() {
+		if p == name {
+			return i
+		}
 	}
 
-	return nil
+	return -1
 }
 
 func verifyCluster(kubeconfig []byte, expectedNodes int) error {
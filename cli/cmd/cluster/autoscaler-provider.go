@@ -0,0 +1,143 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tinkerbell/tink/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc"
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc/protos"
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/inventory"
+	tinkerbellautoscaler "github.com/kinvolk/lokomotive/pkg/autoscaler/tinkerbell"
+	"github.com/kinvolk/lokomotive/pkg/config"
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	"github.com/kinvolk/lokomotive/pkg/platform/tinkerbell"
+)
+
+// autoscalerProviderRefreshTimeout is the time given to the provider to load the initial state
+// from the inventory.
+const autoscalerProviderRefreshTimeout = time.Minute
+
+// AutoscalerProviderOptions controls ServeAutoscalerProvider() behavior.
+type AutoscalerProviderOptions struct {
+	ConfigPaths     []string
+	ValuesPath      string
+	ListenAddress   string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+}
+
+// ServeAutoscalerProvider serves the Cluster Autoscaler external gRPC cloud provider, which
+// scales Tinkerbell worker pools with autoscaling configured, until the listener fails.
+// Clients are required to authenticate using TLS certificates signed by the given client CA.
+func ServeAutoscalerProvider(contextLogger *log.Entry, options AutoscalerProviderOptions) error {
+	// The provider can provision machines, so refuse to serve it without mutual TLS.
+	tlsConfig, err := externalgrpc.TLSConfig(options.TLSCertFile, options.TLSKeyFile, options.TLSClientCAFile)
+	if err != nil {
+		return fmt.Errorf("creating TLS configuration: %w", err)
+	}
+
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
+	if diags.HasErrors() {
+		for _, diagnostic := range diags {
+			contextLogger.Error(diagnostic.Error())
+		}
+
+		return diags
+	}
+
+	p, diags := getConfiguredPlatform(lokoConfig, true)
+	if diags.HasErrors() {
+		for _, diagnostic := range diags {
+			contextLogger.Error(diagnostic.Error())
+		}
+
+		return diags
+	}
+
+	tc, ok := p.(*tinkerbell.Config)
+	if !ok {
+		return errors.New("autoscaler provider is supported only on Tinkerbell platform")
+	}
+
+	nodeGroups, err := tinkerbellautoscaler.NodeGroups(tc)
+	if err != nil {
+		return fmt.Errorf("creating node groups: %w", err)
+	}
+
+	if len(nodeGroups) == 0 {
+		return errors.New("no worker pools with autoscaling configured")
+	}
+
+	pools := []string{}
+	for _, ng := range nodeGroups {
+		pools = append(pools, ng.Name)
+	}
+
+	kg := kubeconfigGetter{
+		platformRequired: true,
+		clusterConfig: clusterConfig{
+			configPaths: options.ConfigPaths,
+			valuesPath:  options.ValuesPath,
+		},
+	}
+
+	kubeconfig, err := kg.getKubeconfig(contextLogger, lokoConfig)
+	if err != nil {
+		return fmt.Errorf("getting kubeconfig: %w", err)
+	}
+
+	cs, err := k8sutil.NewClientset(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("creating Kubernetes clientset: %w", err)
+	}
+
+	conn, err := client.GetConnection()
+	if err != nil {
+		return fmt.Errorf("connecting to Tinkerbell server: %w", err)
+	}
+
+	inv := tinkerbellautoscaler.NewInventory(conn, cs.CoreV1().Nodes(), tc.Name, pools)
+	provider := inventory.NewProvider(inv, nodeGroups)
+
+	ctx, cancel := context.WithTimeout(context.Background(), autoscalerProviderRefreshTimeout)
+	defer cancel()
+
+	if err := provider.Refresh(ctx); err != nil {
+		return fmt.Errorf("loading inventory: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", options.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("listening on %q: %w", options.ListenAddress, err)
+	}
+
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	protos.RegisterCloudProviderServer(s, externalgrpc.NewServer(provider))
+
+	contextLogger.Infof("Serving worker pools %v on %s", pools, listener.Addr())
+
+	return s.Serve(listener)
+}
@@ -24,10 +24,10 @@ type certificateRotator struct {
 
 // CertificateRotateOptions contains the options for the RotateCertificates function.
 type CertificateRotateOptions struct {
-	Confirm    bool
-	Verbose    bool
-	ConfigPath string
-	ValuesPath string
+	Confirm     bool
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
 }
 
 // RotateCertificates replaces all certificates in a cluster.
@@ -35,9 +35,9 @@ type CertificateRotateOptions struct {
 // idempotent.
 func RotateCertificates(contextLogger *log.Entry, options CertificateRotateOptions) error {
 	cc := clusterConfig{
-		verbose:    options.Verbose,
-		configPath: options.ConfigPath,
-		valuesPath: options.ValuesPath,
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
 	}
 
 	c, err := cc.initialize(contextLogger)
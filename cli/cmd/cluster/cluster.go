@@ -42,20 +42,21 @@ import (
 type cluster struct {
 	terraformExecutor terraform.Executor
 	platform          platform.Platform
+	backend           backend
 	lokomotiveConfig  *config.Config
 	assetDir          string
 }
 
 type clusterConfig struct {
-	verbose    bool
-	configPath string
-	valuesPath string
+	verbose     bool
+	configPaths []string
+	valuesPath  string
 }
 
 // initialize does common initialization actions between cluster operations
 // and returns created objects to the caller for further use.
 func (cc clusterConfig) initialize(contextLogger *log.Entry) (*cluster, error) {
-	lokoConfig, diags := config.LoadConfig(cc.configPath, cc.valuesPath)
+	lokoConfig, diags := config.LoadConfig(cc.configPaths, cc.valuesPath)
 	if diags.HasErrors() {
 		return nil, diags
 	}
@@ -77,7 +78,8 @@ func (cc clusterConfig) initialize(contextLogger *log.Entry) (*cluster, error) {
 		return nil, fmt.Errorf("loading platform configuration")
 	}
 
-	// Get the configured backend for the cluster. Backend types currently supported: local, s3.
+	// Get the configured backend for the cluster. Backend types currently supported: local, s3, azurerm, gcs,
+	// consul, pg and remote.
 	b, diags := getConfiguredBackend(lokoConfig)
 	if diags.HasErrors() {
 		for _, diagnostic := range diags {
@@ -110,6 +112,7 @@ func (cc clusterConfig) initialize(contextLogger *log.Entry) (*cluster, error) {
 	return &cluster{
 		terraformExecutor: *ex,
 		platform:          p,
+		backend:           b,
 		lokomotiveConfig:  lokoConfig,
 		assetDir:          assetDir,
 	}, nil
@@ -123,15 +126,36 @@ func (c *cluster) upgradeControlPlane(contextLogger *log.Entry, kubeconfig []byt
 		assetDir:      c.assetDir,
 		contextLogger: *contextLogger,
 		ex:            c.terraformExecutor,
+		installed:     map[string]bool{},
 	}
 
 	if err := c.unpackControlplaneCharts(); err != nil {
 		return fmt.Errorf("unpacking controlplane assets: %w", err)
 	}
 
+	// Like in 'cluster upgrade', kubelet is upgraded after the other charts and it is
+	// not rolled back on failure.
+	charts := rollbackCharts(c.platform.Meta().ControlplaneCharts)
+
+	if err := cu.upgradeWithRollback(charts, func() error {
+		for _, cpChart := range charts {
+			if err := cu.upgradeComponent(cpChart.Name, cpChart.Namespace); err != nil {
+				return fmt.Errorf("upgrading controlplane component %q: %w", cpChart.Name, err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Skip kubelet if the user doesn't want to upgrade it.
+	if !upgradeKubelets {
+		return nil
+	}
+
 	for _, cpChart := range c.platform.Meta().ControlplaneCharts {
-		// Skip kubelet if the user doesn't want to upgrade it.
-		if cpChart.Name == platform.KubeletChartName && !upgradeKubelets {
+		if cpChart.Name != platform.KubeletChartName {
 			continue
 		}
 
@@ -182,6 +206,10 @@ func (cc clusterConfig) initializeTerraform(p platform.Platform, b backend) (*te
 		Verbose:    cc.verbose,
 	}
 
+	if eb, ok := b.(envBackend); ok {
+		conf.EnvVariables = eb.Env()
+	}
+
 	ex, err := terraform.NewExecutor(conf)
 	if err != nil {
 		return nil, fmt.Errorf("creating Terraform executor: %w", err)
@@ -216,6 +244,9 @@ type controlplaneUpdater struct {
 	assetDir      string
 	contextLogger log.Entry
 	ex            terraform.Executor
+	// installed records releases installed by the updater, so only those get uninstalled
+	// when rolling back. Installed releases are not recorded if nil.
+	installed map[string]bool
 }
 
 func (c controlplaneUpdater) getControlplaneChart(name, namespace string) (*chart.Chart, error) {
@@ -247,6 +278,12 @@ func (c controlplaneUpdater) getControlplaneValues(name string) (map[string]inte
 }
 
 func (c controlplaneUpdater) upgradeComponent(component, namespace string) error {
+	return c.upgradeComponentWithValues(component, namespace, nil)
+}
+
+// upgradeComponentWithValues upgrades given controlplane component, overriding top-level
+// chart values generated by Terraform with given values.
+func (c controlplaneUpdater) upgradeComponentWithValues(component, namespace string, overrides map[string]interface{}) error { //nolint:lll
 	actionConfig, err := util.HelmActionConfig(namespace, c.kubeconfig)
 	if err != nil {
 		return fmt.Errorf("initializing Helm action: %w", err)
@@ -262,6 +299,10 @@ func (c controlplaneUpdater) upgradeComponent(component, namespace string) error
 		return fmt.Errorf("getting chart values from Terraform: %w", err)
 	}
 
+	for k, v := range overrides {
+		values[k] = v
+	}
+
 	exists, err := util.ReleaseExists(*actionConfig, component)
 	if err != nil {
 		return fmt.Errorf("checking if controlplane component is installed: %w", err)
@@ -282,6 +323,10 @@ func (c controlplaneUpdater) upgradeComponent(component, namespace string) error
 			return fmt.Errorf("installing controlplane component: %w", err)
 		}
 
+		if c.installed != nil {
+			c.installed[component] = true
+		}
+
 		fmt.Println("Done.")
 	}
 
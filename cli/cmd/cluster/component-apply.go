@@ -16,6 +16,8 @@ package cluster
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 
@@ -27,14 +29,14 @@ import (
 // ComponentApplyOptions controls ComponentApply() behavior.
 type ComponentApplyOptions struct {
 	KubeconfigPath string
-	ConfigPath     string
+	ConfigPaths    []string
 	ValuesPath     string
 }
 
 // ComponentApply implements 'lokoctl component apply' separated from CLI
 // dependencies.
 func ComponentApply(contextLogger *log.Entry, componentsList []string, options ComponentApplyOptions) error {
-	lokoConfig, diags := config.LoadConfig(options.ConfigPath, options.ValuesPath)
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
 	if diags.HasErrors() {
 		return diags
 	}
@@ -48,8 +50,8 @@ func ComponentApply(contextLogger *log.Entry, componentsList []string, options C
 		platformRequired: false,
 		path:             options.KubeconfigPath,
 		clusterConfig: clusterConfig{
-			configPath: options.ConfigPath,
-			valuesPath: options.ValuesPath,
+			configPaths: options.ConfigPaths,
+			valuesPath:  options.ValuesPath,
 		},
 	}
 
@@ -69,24 +71,82 @@ func ComponentApply(contextLogger *log.Entry, componentsList []string, options C
 
 // applyComponents reads the configuration of given components and applies them to the cluster pointer
 // by given kubeconfig file content.
+//
+// Components are installed in the order of their dependencies. Components which do not depend
+// on each other are installed concurrently.
 func applyComponents(lokoConfig *config.Config, kubeconfig []byte, componentObjects []components.Component) error {
+	// Load all configurations first, as dependencies may depend on the configuration.
 	for _, component := range componentObjects {
-		componentName := component.Metadata().Name
-		fmt.Printf("Applying component '%s'...\n", componentName)
-
-		componentConfigBody := lokoConfig.LoadComponentConfigBody(componentName)
+		componentConfigBody := lokoConfig.LoadComponentConfigBody(component.Metadata().Name)
 
-		if diags := component.LoadConfig(componentConfigBody, lokoConfig.EvalContext); diags.HasErrors() {
+		if diags := loadComponentConfig(lokoConfig, component, componentConfigBody); diags.HasErrors() {
 			fmt.Printf("%v\n", diags)
 
 			return diags
 		}
+	}
 
-		if err := util.InstallComponent(component, kubeconfig); err != nil {
-			return fmt.Errorf("installing component %q: %w", componentName, err)
+	batches, err := util.InstallOrder(componentObjects)
+	if err != nil {
+		return fmt.Errorf("ordering components: %w", err)
+	}
+
+	for _, batch := range batches {
+		if err := applyComponentsBatch(kubeconfig, batch); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// applyComponentsBatch concurrently installs given components, which do not depend on each other.
+// Components sharing a namespace are installed one by one, as installing updates the namespace.
+func applyComponentsBatch(kubeconfig []byte, batch []components.Component) error {
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(batch))
+	namespaceLocks := map[string]*sync.Mutex{}
+
+	for _, component := range batch {
+		namespaceLocks[component.Metadata().Namespace.Name] = &sync.Mutex{}
+	}
+
+	for i, component := range batch {
+		wg.Add(1)
+
+		go func(i int, component components.Component) {
+			defer wg.Done()
+
+			lock := namespaceLocks[component.Metadata().Namespace.Name]
+			lock.Lock()
+			defer lock.Unlock()
+
+			componentName := component.Metadata().Name
+			fmt.Printf("Applying component '%s'...\n", componentName)
+
+			if err := util.InstallComponent(component, kubeconfig); err != nil {
+				errs[i] = fmt.Errorf("installing component %q: %w", componentName, err)
+
+				return
+			}
+
+			fmt.Printf("Successfully applied component '%s' configuration!\n", componentName)
+		}(i, component)
+	}
+
+	wg.Wait()
+
+	failed := []string{}
+
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
 
-		fmt.Printf("Successfully applied component '%s' configuration!\n", componentName)
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
 	}
 
 	return nil
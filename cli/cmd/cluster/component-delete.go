@@ -30,14 +30,14 @@ type ComponentDeleteOptions struct {
 	Confirm         bool
 	DeleteNamespace bool
 	KubeconfigPath  string
-	ConfigPath      string
+	ConfigPaths     []string
 	ValuesPath      string
 }
 
 // ComponentDelete implements 'lokoctl component delete' separated from CLI
 // dependencies.
 func ComponentDelete(contextLogger *log.Entry, componentsList []string, options ComponentDeleteOptions) error {
-	lokoConfig, diags := config.LoadConfig(options.ConfigPath, options.ValuesPath)
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
 	if diags.HasErrors() {
 		return diags
 	}
@@ -64,8 +64,8 @@ func ComponentDelete(contextLogger *log.Entry, componentsList []string, options
 		platformRequired: false,
 		path:             options.KubeconfigPath,
 		clusterConfig: clusterConfig{
-			configPath: options.ConfigPath,
-			valuesPath: options.ValuesPath,
+			configPaths: options.ConfigPaths,
+			valuesPath:  options.ValuesPath,
 		},
 	}
 
@@ -76,6 +76,10 @@ func ComponentDelete(contextLogger *log.Entry, componentsList []string, options
 		return fmt.Errorf("suitable kubeconfig file not found. Did you run 'lokoctl cluster apply' ?")
 	}
 
+	if err := checkDependents(lokoConfig, kubeconfig, componentsToDelete); err != nil {
+		return err
+	}
+
 	if err := deleteComponents(kubeconfig, componentObjects, options.DeleteNamespace); err != nil {
 		return fmt.Errorf("deleting components: %w", err)
 	}
@@ -114,8 +118,92 @@ func componentNamesToObjects(componentNames []string) ([]components.Component, e
 	return c, nil
 }
 
+// checkDependents returns an error if any of the installed components, which are not being deleted,
+// depends on any of the components to delete.
+func checkDependents(lokoConfig *config.Config, kubeconfig []byte, componentsToDelete []string) error {
+	deleted := map[string]bool{}
+
+	for _, name := range componentsToDelete {
+		deleted[name] = true
+	}
+
+	installed := []components.Component{}
+
+	for _, name := range ListComponents().Components {
+		if deleted[name] {
+			continue
+		}
+
+		component, err := componentConfig(name)
+		if err != nil {
+			return fmt.Errorf("getting component %q: %w", name, err)
+		}
+
+		// Dependencies may depend on the component configuration. Installed components removed
+		// from the configuration may have been installed with any configuration.
+		if body := lokoConfig.LoadComponentConfigBody(name); body != nil {
+			if diags := loadComponentConfig(lokoConfig, component, body); diags.HasErrors() {
+				return diags
+			}
+		} else {
+			component = unconfiguredComponent{component}
+		}
+
+		actionConfig, err := util.HelmActionConfig(component.Metadata().Namespace.Name, kubeconfig)
+		if err != nil {
+			return fmt.Errorf("initializing Helm action: %w", err)
+		}
+
+		exists, err := util.ReleaseExists(*actionConfig, name)
+		if err != nil {
+			return fmt.Errorf("checking if component %q is installed: %w", name, err)
+		}
+
+		if exists {
+			installed = append(installed, component)
+		}
+	}
+
+	for _, name := range componentsToDelete {
+		if dependents := util.Dependents(name, installed); len(dependents) > 0 {
+			return fmt.Errorf("component %q is required by installed components %s, delete them first",
+				name, strings.Join(dependents, ", "))
+		}
+	}
+
+	return nil
+}
+
+// unconfiguredComponent is a component with unknown configuration, which depends on all
+// components it may depend on.
+type unconfiguredComponent struct {
+	components.Component
+}
+
+// Metadata returns metadata of the component with conditional dependencies included
+// in dependencies.
+func (u unconfiguredComponent) Metadata() components.Metadata {
+	m := u.Component.Metadata()
+	m.Dependencies = append(m.Dependencies, m.ConditionalDependencies...)
+
+	return m
+}
+
+// deleteComponents deletes given components in the reverse order of installation, so
+// components are deleted before the components they depend on.
 func deleteComponents(kubeconfig []byte, componentObjects []components.Component, deleteNamespace bool) error {
-	for _, compObj := range componentObjects {
+	batches, err := util.InstallOrder(componentObjects)
+	if err != nil {
+		return fmt.Errorf("ordering components: %w", err)
+	}
+
+	ordered := []components.Component{}
+
+	for i := len(batches) - 1; i >= 0; i-- {
+		ordered = append(ordered, batches[i]...)
+	}
+
+	for _, compObj := range ordered {
 		fmt.Printf("Deleting component '%s'...\n", compObj.Metadata().Name)
 
 		if err := util.UninstallComponent(compObj, kubeconfig, deleteNamespace); err != nil {
@@ -0,0 +1,39 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/components"
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+)
+
+func TestUnconfiguredComponentDependsOnConditionalDependencies(t *testing.T) {
+	component, err := componentConfig("dex")
+	if err != nil {
+		t.Fatalf("Getting component: %v", err)
+	}
+
+	if d := util.Dependents("cert-manager", []components.Component{component}); len(d) != 0 {
+		t.Fatalf("Dex without ingress should not depend on cert-manager, got %v", d)
+	}
+
+	installed := []components.Component{unconfiguredComponent{component}}
+
+	if d := util.Dependents("cert-manager", installed); len(d) != 1 || d[0] != "dex" {
+		t.Fatalf("Dex with unknown configuration should depend on cert-manager, got %v", d)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kinvolk/lokomotive/pkg/components"
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/config"
+)
+
+// ComponentDiffOptions controls ComponentDiff() behavior.
+type ComponentDiffOptions struct {
+	KubeconfigPath string
+	ConfigPaths    []string
+	ValuesPath     string
+}
+
+// ComponentChanges is a list of changes to the component releases.
+type ComponentChanges []ReleaseChanges
+
+// HasChanges returns true if any of the releases has any object changes.
+func (c ComponentChanges) HasChanges() bool {
+	for _, release := range c {
+		if len(release.Changes) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrintTable prints unified diffs of all changed objects.
+func (c ComponentChanges) PrintTable(out io.Writer) error {
+	for _, release := range c {
+		for _, change := range release.Changes {
+			if _, err := fmt.Fprint(out, change.Diff); err != nil {
+				return fmt.Errorf("writing diff: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ComponentDiff compares rendered manifests of selected components with the Helm
+// releases deployed in the cluster and returns the differences.
+func ComponentDiff(contextLogger *log.Entry, componentsList []string, options ComponentDiffOptions) (ComponentChanges, error) { //nolint:lll
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	componentObjects, err := componentNamesToObjects(selectComponentNames(componentsList, *lokoConfig.RootConfig))
+	if err != nil {
+		return nil, fmt.Errorf("getting component objects: %w", err)
+	}
+
+	kg := kubeconfigGetter{
+		platformRequired: false,
+		path:             options.KubeconfigPath,
+		clusterConfig: clusterConfig{
+			configPaths: options.ConfigPaths,
+			valuesPath:  options.ValuesPath,
+		},
+	}
+
+	kubeconfig, err := kg.getKubeconfig(contextLogger, lokoConfig)
+	if err != nil {
+		contextLogger.Debugf("Error in finding kubeconfig file: %s", err)
+
+		return nil, fmt.Errorf("suitable kubeconfig file not found. Did you run 'lokoctl cluster apply' ?")
+	}
+
+	return diffComponents(lokoConfig, kubeconfig, componentObjects)
+}
+
+// diffComponents compares rendered manifests of given components with the deployed releases.
+// If kubeconfig is nil, all components are treated as not installed.
+func diffComponents(lokoConfig *config.Config, kubeconfig []byte, componentObjects []components.Component) (ComponentChanges, error) { //nolint:lll
+	releases := ComponentChanges{}
+
+	for _, component := range componentObjects {
+		componentName := component.Metadata().Name
+
+		componentConfigBody := lokoConfig.LoadComponentConfigBody(componentName)
+
+		if diags := loadComponentConfig(lokoConfig, component, componentConfigBody); diags.HasErrors() {
+			return nil, diags
+		}
+
+		// Metadata may depend on the configuration, e.g. the namespace, so read it after loading it.
+		namespace := component.Metadata().Namespace.Name
+
+		desired, err := util.ComponentManifest(component)
+		if err != nil {
+			return nil, fmt.Errorf("rendering component %q: %w", componentName, err)
+		}
+
+		rc := ReleaseChanges{
+			Name:      componentName,
+			Namespace: namespace,
+		}
+
+		current := ""
+
+		if kubeconfig != nil {
+			actionConfig, err := util.HelmActionConfig(namespace, kubeconfig)
+			if err != nil {
+				return nil, fmt.Errorf("initializing Helm action for component %q: %w", componentName, err)
+			}
+
+			if current, rc.Installed, err = util.ReleaseManifest(actionConfig, componentName); err != nil {
+				return nil, fmt.Errorf("getting deployed release manifest of component %q: %w", componentName, err)
+			}
+		}
+
+		if rc.Changes, err = util.DiffManifests(current, desired); err != nil {
+			return nil, fmt.Errorf("comparing manifests of component %q: %w", componentName, err)
+		}
+
+		releases = append(releases, rc)
+	}
+
+	return releases, nil
+}
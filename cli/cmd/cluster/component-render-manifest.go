@@ -16,6 +16,8 @@ package cluster
 
 import (
 	"fmt"
+	"io"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 
@@ -24,33 +26,46 @@ import (
 
 // ComponentRenderManifestOptions controls ComponentRenderManifest() behavior.
 type ComponentRenderManifestOptions struct {
-	ConfigPath string
-	ValuesPath string
+	ConfigPaths []string
+	ValuesPath  string
 }
 
-// ComponentRenderManifest prints selected components manifests.
+// ComponentManifests contains rendered manifests of a single component.
+type ComponentManifests struct {
+	Name string `json:"name"`
+	// Manifests maps file names to their content.
+	Manifests map[string]string `json:"manifests"`
+}
+
+// RenderedManifests is a list of rendered components.
+type RenderedManifests []ComponentManifests
+
+// ComponentRenderManifest returns selected components manifests.
 //
 //nolint:lll
-func ComponentRenderManifest(contextLogger *log.Entry, componentsList []string, options ComponentRenderManifestOptions) error {
-	lokoConfig, diags := config.LoadConfig(options.ConfigPath, options.ValuesPath)
+func ComponentRenderManifest(contextLogger *log.Entry, componentsList []string, options ComponentRenderManifestOptions) (RenderedManifests, error) {
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
 	if diags.HasErrors() {
 		for _, diagnostic := range diags {
 			contextLogger.Error(diagnostic.Error())
 		}
 
-		return diags
+		return nil, diags
 	}
 
 	componentsToRender := selectComponentNames(componentsList, *lokoConfig.RootConfig)
 
-	if err := renderComponentManifests(lokoConfig, componentsToRender); err != nil {
-		return fmt.Errorf("rendering component manifests: %w", err)
+	rendered, err := renderComponentManifests(lokoConfig, componentsToRender)
+	if err != nil {
+		return nil, fmt.Errorf("rendering component manifests: %w", err)
 	}
 
-	return nil
+	return rendered, nil
 }
 
-func renderComponentManifests(lokoConfig *config.Config, componentNames []string) error {
+func renderComponentManifests(lokoConfig *config.Config, componentNames []string) (RenderedManifests, error) {
+	rendered := RenderedManifests{}
+
 	for _, componentName := range componentNames {
 		contextLogger := log.WithFields(log.Fields{
 			"component": componentName,
@@ -58,28 +73,48 @@ func renderComponentManifests(lokoConfig *config.Config, componentNames []string
 
 		component, err := componentConfig(componentName)
 		if err != nil {
-			return fmt.Errorf("getting component %q: %w", componentName, err)
+			return nil, fmt.Errorf("getting component %q: %w", componentName, err)
 		}
 
 		componentConfigBody := lokoConfig.LoadComponentConfigBody(componentName)
 
-		if diags := component.LoadConfig(componentConfigBody, lokoConfig.EvalContext); diags.HasErrors() {
+		if diags := loadComponentConfig(lokoConfig, component, componentConfigBody); diags.HasErrors() {
 			for _, diagnostic := range diags {
 				contextLogger.Error(diagnostic.Error())
 			}
 
-			return diags
+			return nil, diags
 		}
 
 		manifests, err := component.RenderManifests()
 		if err != nil {
-			return fmt.Errorf("rendering manifest of component %q: %w", componentName, err)
+			return nil, fmt.Errorf("rendering manifest of component %q: %w", componentName, err)
+		}
+
+		rendered = append(rendered, ComponentManifests{
+			Name:      componentName,
+			Manifests: manifests,
+		})
+	}
+
+	return rendered, nil
+}
+
+// PrintTable prints manifests of all components as a YAML stream, with each file
+// preceded by a comment with it's name.
+func (r RenderedManifests) PrintTable(out io.Writer) error {
+	for _, component := range r {
+		fmt.Fprintf(out, "# manifests for component %s\n", component.Name)
+
+		filenames := []string{}
+		for filename := range component.Manifests {
+			filenames = append(filenames, filename)
 		}
 
-		fmt.Printf("# manifests for component %s\n", componentName)
+		sort.Strings(filenames)
 
-		for filename, manifest := range manifests {
-			fmt.Printf("\n---\n# %s\n%s", filename, manifest)
+		for _, filename := range filenames {
+			fmt.Fprintf(out, "\n---\n# %s\n%s", filename, component.Manifests[filename])
 		}
 	}
 
@@ -16,6 +16,10 @@ package cluster
 
 import (
 	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
 
 	"github.com/kinvolk/lokomotive/pkg/components"
 	awsebscsidriver "github.com/kinvolk/lokomotive/pkg/components/aws-ebs-csi-driver"
@@ -41,6 +45,7 @@ import (
 	rookceph "github.com/kinvolk/lokomotive/pkg/components/rook-ceph"
 	"github.com/kinvolk/lokomotive/pkg/components/velero"
 	webui "github.com/kinvolk/lokomotive/pkg/components/web-ui"
+	"github.com/kinvolk/lokomotive/pkg/config"
 )
 
 func componentsConfigs() map[string]components.Component {
@@ -82,6 +87,32 @@ func AvailableComponents() []string {
 	return c
 }
 
+// ComponentList is a list of names of all available components.
+type ComponentList struct {
+	Components []string `json:"components"`
+}
+
+// ListComponents returns sorted list of all available components.
+func ListComponents() ComponentList {
+	comps := AvailableComponents()
+	sort.Strings(comps)
+
+	return ComponentList{
+		Components: comps,
+	}
+}
+
+// PrintTable prints the list of available components.
+func (l ComponentList) PrintTable(out io.Writer) error {
+	fmt.Fprintln(out, "Available components:")
+
+	for _, name := range l.Components {
+		fmt.Fprintln(out, "\t", name)
+	}
+
+	return nil
+}
+
 func componentConfig(name string) (components.Component, error) {
 	c, ok := componentsConfigs()[name]
 	if !ok {
@@ -90,3 +121,21 @@ func componentConfig(name string) (components.Component, error) {
 
 	return c, nil
 }
+
+// loadComponentConfig loads given configuration body into the component. If the component
+// implements components.ComponentWithPlatform and the cluster is configured, it gets the
+// platform configuration first.
+func loadComponentConfig(lokoConfig *config.Config, component components.Component, body *hcl.Body) hcl.Diagnostics {
+	if c, ok := component.(components.ComponentWithPlatform); ok {
+		p, diags := getConfiguredPlatform(lokoConfig, false)
+		if diags.HasErrors() {
+			return diags
+		}
+
+		if p != nil {
+			c.SetPlatform(p)
+		}
+	}
+
+	return component.LoadConfig(body, lokoConfig.EvalContext)
+}
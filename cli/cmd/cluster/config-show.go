@@ -0,0 +1,53 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kinvolk/lokomotive/pkg/config"
+)
+
+// ConfigShowOptions controls ConfigShow() behavior.
+type ConfigShowOptions struct {
+	ConfigPaths []string
+	ValuesPath  string
+}
+
+// ConfigShow writes the merged configuration to given writer.
+func ConfigShow(contextLogger *log.Entry, w io.Writer, options ConfigShowOptions) error {
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
+	if diags.HasErrors() {
+		for _, diagnostic := range diags {
+			contextLogger.Error(diagnostic.Error())
+		}
+
+		return diags
+	}
+
+	output, err := lokoConfig.Show()
+	if err != nil {
+		return fmt.Errorf("showing configuration: %w", err)
+	}
+
+	if _, err := w.Write(output); err != nil {
+		return fmt.Errorf("writing configuration: %w", err)
+	}
+
+	return nil
+}
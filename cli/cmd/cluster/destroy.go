@@ -22,18 +22,18 @@ import (
 
 // DestroyOptions controls Destroy() behavior.
 type DestroyOptions struct {
-	Confirm    bool
-	Verbose    bool
-	ConfigPath string
-	ValuesPath string
+	Confirm     bool
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
 }
 
 // Destroy destroys cluster infrastructure.
 func Destroy(contextLogger *log.Entry, options DestroyOptions) error {
 	cc := clusterConfig{
-		verbose:    options.Verbose,
-		configPath: options.ConfigPath,
-		valuesPath: options.ValuesPath,
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
 	}
 
 	c, err := cc.initialize(contextLogger)
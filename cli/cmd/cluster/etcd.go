@@ -0,0 +1,531 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/s3"
+	"github.com/kinvolk/lokomotive/pkg/etcd"
+	"github.com/kinvolk/lokomotive/pkg/sshutil"
+)
+
+const (
+	// etcdSnapshotsDir is the directory where etcd snapshots are stored, both in the
+	// assets directory and in the backend bucket.
+	etcdSnapshotsDir = "etcd-snapshots"
+
+	// etcdSnapshotTimeFormat is used to generate names of snapshot files.
+	etcdSnapshotTimeFormat = "20060102T150405Z"
+
+	// remoteRestoreDirTemplate is a template for the temporary directory, accessible only by
+	// root, where the snapshot is uploaded to on the controller nodes.
+	remoteRestoreDirTemplate = "/tmp/lokoctl-etcd-restore.XXXXXX"
+
+	// remoteSnapshotDir is the directory, accessible only by root, where the snapshot is
+	// saved on the controller node before copying it.
+	remoteSnapshotDir = "/tmp/lokoctl-etcd-snapshot"
+
+	// Period after which we assume that restored etcd cluster will not become healthy.
+	etcdRestoreHealthTimeout = 5 * time.Minute
+	// Number of seconds to wait between checking health of restored etcd cluster.
+	etcdRestoreHealthInterval = 10 * time.Second
+)
+
+// etcdRestoreScript re-seeds etcd data directory from the snapshot uploaded to the node,
+// which path is given as the first argument. It uses the configuration of etcd.service,
+// so the restored member keeps it's identity and peer URLs.
+const etcdRestoreScript = `set -euo pipefail
+snapshot="$1"
+source /etc/kubernetes/etcd.env
+if [ -d "${ETCD_DATA_DIR}" ]; then
+  mv "${ETCD_DATA_DIR}" "${ETCD_DATA_DIR}-bkp-$(date +%s)"
+fi
+parent_dir=$(dirname "${ETCD_DATA_DIR}")
+docker run --rm \
+  -e ETCDCTL_API=3 \
+  -v "${snapshot}:/snapshot.db:ro" \
+  -v "${parent_dir}:${parent_dir}:rw" \
+  "${IMAGE_URL}:${IMAGE_TAG}" \
+  etcdctl snapshot restore /snapshot.db \
+    --name "${ETCD_NAME}" \
+    --initial-cluster "${ETCD_INITIAL_CLUSTER}" \
+    --initial-advertise-peer-urls "${ETCD_INITIAL_ADVERTISE_PEER_URLS}" \
+    --data-dir "${ETCD_DATA_DIR}"
+chown -R "${USER}:${USER}" "${ETCD_DATA_DIR}"
+chmod 0700 "${ETCD_DATA_DIR}"
+rm -f /var/lib/etcd-last-fail
+`
+
+// etcdctlCommand runs etcdctl using the etcd image and the etcd client certificates of
+// the controller node, connecting to the local etcd member.
+const etcdctlCommand = `docker run --rm --net host \
+  -e ETCDCTL_API=3 \
+  -v /etc/ssl/etcd:/etc/ssl/etcd:ro \
+  -v ` + remoteSnapshotDir + `:` + remoteSnapshotDir + `:rw \
+  "${IMAGE_URL}:${IMAGE_TAG}" \
+  etcdctl \
+    --endpoints "${ETCD_ADVERTISE_CLIENT_URLS}" \
+    --cacert /etc/ssl/etcd/etcd-client-ca.crt \
+    --cert /etc/ssl/etcd/etcd-client.crt \
+    --key /etc/ssl/etcd/etcd-client.key`
+
+// etcdSnapshotScript saves the snapshot of the local etcd member on the controller node.
+const etcdSnapshotScript = `set -euo pipefail
+source /etc/kubernetes/etcd.env
+rm -rf ` + remoteSnapshotDir + `
+install -d -m 0700 ` + remoteSnapshotDir + `
+` + etcdctlCommand + ` snapshot save ` + remoteSnapshotDir + `/snapshot.db
+`
+
+// etcdHealthScript fails, unless all members of the etcd cluster are healthy.
+const etcdHealthScript = `set -euo pipefail
+source /etc/kubernetes/etcd.env
+install -d -m 0700 ` + remoteSnapshotDir + `
+` + etcdctlCommand + ` endpoint health --cluster
+`
+
+// objectStorer is implemented by backends, which can store objects other than Terraform state.
+type objectStorer interface {
+	Store(dir string) (*s3.Store, error)
+}
+
+// EtcdSnapshotOptions controls EtcdSnapshot() behavior.
+type EtcdSnapshotOptions struct {
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
+	// File is the local path to store the snapshot in. If empty, the snapshot is stored
+	// in the backend bucket if supported, otherwise in the assets directory.
+	File string
+	// Hosts are addresses of the controller nodes to connect to over SSH. If empty,
+	// hostnames of etcd endpoints are used. The snapshot is taken on the first node,
+	// where it succeeds.
+	Hosts []string
+	SSH   sshutil.Config
+}
+
+// EtcdSnapshotResult describes the taken snapshot.
+type EtcdSnapshotResult struct {
+	// Location is a local path or URL of the stored snapshot.
+	Location string `json:"location"`
+	// Host is the controller node the snapshot was taken on.
+	Host string `json:"host"`
+	// Size is the size of the snapshot in bytes.
+	Size int64 `json:"size"`
+}
+
+// PrintTable prints the snapshot details.
+func (r *EtcdSnapshotResult) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+
+	fmt.Fprintf(w, "Location:\t%s\n", r.Location)
+	fmt.Fprintf(w, "Host:\t%s\n", r.Host)
+	fmt.Fprintf(w, "Size (bytes):\t%d\n", r.Size)
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+// EtcdSnapshot takes a snapshot of the etcd database of the cluster.
+func EtcdSnapshot(contextLogger *log.Entry, options EtcdSnapshotOptions) (*EtcdSnapshotResult, error) {
+	cc := clusterConfig{
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
+	}
+
+	c, err := cc.initialize(contextLogger)
+	if err != nil {
+		return nil, fmt.Errorf("initializing: %w", err)
+	}
+
+	return c.snapshotEtcd(contextLogger, options)
+}
+
+// EtcdRestoreOptions controls EtcdRestore() behavior.
+type EtcdRestoreOptions struct {
+	Confirm     bool
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
+	// Snapshot is the local path or S3 URL of the snapshot to restore.
+	Snapshot string
+	// Hosts are addresses of the controller nodes to connect to over SSH. If empty,
+	// hostnames of etcd endpoints are used.
+	Hosts []string
+	SSH   sshutil.Config
+}
+
+// EtcdRestore re-seeds etcd members of the cluster from given snapshot. All data written
+// to etcd after the snapshot has been taken is lost.
+func EtcdRestore(contextLogger *log.Entry, options EtcdRestoreOptions) error {
+	cc := clusterConfig{
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
+	}
+
+	c, err := cc.initialize(contextLogger)
+	if err != nil {
+		return fmt.Errorf("initializing: %w", err)
+	}
+
+	if !options.Confirm && !askForConfirmation(
+		"Restoring etcd will replace all cluster data with the snapshot. Do you want to proceed?") {
+		contextLogger.Println("Etcd restore cancelled")
+
+		return nil
+	}
+
+	return c.restoreEtcd(contextLogger, options)
+}
+
+// clusterAssetsDir returns the directory with cluster assets generated by Terraform.
+func (c *cluster) clusterAssetsDir() string {
+	return filepath.Join(c.assetDir, "cluster-assets")
+}
+
+// controllerHosts returns given controller node addresses or, if none are given,
+// hostnames of etcd endpoints from the assets directory.
+func (c *cluster) controllerHosts(hosts []string) ([]string, error) {
+	if c.platform.Meta().Managed {
+		return nil, errors.New("etcd is not accessible on managed platforms")
+	}
+
+	if len(hosts) > 0 {
+		return hosts, nil
+	}
+
+	endpoints, err := etcd.AssetDirEndpoints(c.clusterAssetsDir())
+	if err != nil {
+		return nil, fmt.Errorf("getting etcd endpoints: %w", err)
+	}
+
+	return etcdHosts(endpoints)
+}
+
+// snapshotStore returns the object store of the configured backend, if the backend supports it.
+func (c *cluster) snapshotStore() (*s3.Store, error) {
+	storer, ok := c.backend.(objectStorer)
+	if !ok {
+		return nil, nil
+	}
+
+	return storer.Store(etcdSnapshotsDir)
+}
+
+func (c *cluster) snapshotEtcd(contextLogger *log.Entry, options EtcdSnapshotOptions) (*EtcdSnapshotResult, error) { //nolint:lll
+	hosts, err := c.controllerHosts(options.Hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	var store *s3.Store
+
+	file := options.File
+
+	name := fmt.Sprintf("etcd-%s.db", time.Now().UTC().Format(etcdSnapshotTimeFormat))
+
+	if file == "" {
+		if store, err = c.snapshotStore(); err != nil {
+			return nil, fmt.Errorf("creating backend object store: %w", err)
+		}
+
+		file = filepath.Join(c.assetDir, etcdSnapshotsDir, name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	// Write the snapshot to a temporary file first, so partial snapshot is never stored.
+	f, err := ioutil.TempFile(filepath.Dir(file), ".etcd-snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary snapshot file: %w", err)
+	}
+
+	defer os.Remove(f.Name()) //nolint:errcheck
+
+	contextLogger.Info("Taking etcd snapshot")
+
+	host, err := takeSnapshot(contextLogger, hosts, options.SSH, f)
+	if err != nil {
+		f.Close() //nolint:errcheck,gosec
+
+		return nil, fmt.Errorf("taking snapshot: %w", err)
+	}
+
+	result := &EtcdSnapshotResult{
+		Host: host,
+	}
+
+	if result.Size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		f.Close() //nolint:errcheck,gosec
+
+		return nil, fmt.Errorf("getting snapshot size: %w", err)
+	}
+
+	if store != nil {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close() //nolint:errcheck,gosec
+
+			return nil, fmt.Errorf("rewinding snapshot file: %w", err)
+		}
+
+		contextLogger.Info("Uploading etcd snapshot to backend bucket")
+
+		result.Location, err = store.Upload(name, f)
+		f.Close() //nolint:errcheck,gosec
+
+		if err != nil {
+			return nil, fmt.Errorf("uploading snapshot: %w", err)
+		}
+
+		return result, nil
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("closing snapshot file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), file); err != nil {
+		return nil, fmt.Errorf("moving snapshot to %q: %w", file, err)
+	}
+
+	result.Location = file
+
+	return result, nil
+}
+
+// takeSnapshot takes the snapshot on the first controller node, where it succeeds, and
+// writes it to given file. It returns the host the snapshot was taken on.
+func takeSnapshot(contextLogger *log.Entry, hosts []string, config sshutil.Config, f *os.File) (string, error) {
+	var err error
+
+	for _, host := range hosts {
+		if err = snapshotOnHost(host, config, f); err == nil {
+			return host, nil
+		}
+
+		contextLogger.Warnf("Taking etcd snapshot on %q failed: %v", host, err)
+	}
+
+	return "", fmt.Errorf("taking snapshot failed on all controller nodes, last error: %w", err)
+}
+
+// snapshotOnHost saves the snapshot on the controller node using etcdctl and copies it
+// to given file.
+func snapshotOnHost(host string, config sshutil.Config, f *os.File) error {
+	// Discard content written by a previous failed attempt.
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("truncating snapshot file: %w", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding snapshot file: %w", err)
+	}
+
+	n, err := sshutil.Dial(host, config)
+	if err != nil {
+		return fmt.Errorf("connecting to controller node: %w", err)
+	}
+
+	defer n.Close() //nolint:errcheck
+
+	// The snapshot contains all cluster secrets, so always remove it from the node.
+	defer n.Run("sudo rm -rf "+remoteSnapshotDir, nil) //nolint:errcheck
+
+	if _, err := n.Run("sudo bash -s", strings.NewReader(etcdSnapshotScript)); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+
+	if err := n.Output("sudo cat "+remoteSnapshotDir+"/snapshot.db", f); err != nil {
+		return fmt.Errorf("copying snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// openSnapshot returns a reader for a snapshot stored locally or in the backend bucket.
+func (c *cluster) openSnapshot(snapshot string) (*os.File, error) {
+	if !strings.HasPrefix(snapshot, s3.URLScheme+"://") {
+		return os.Open(snapshot) //nolint:gosec
+	}
+
+	store, err := c.snapshotStore()
+	if err != nil {
+		return nil, fmt.Errorf("creating backend object store: %w", err)
+	}
+
+	if store == nil {
+		return nil, fmt.Errorf("restoring snapshot from %q requires the %q backend", snapshot, s3.Name)
+	}
+
+	f, err := ioutil.TempFile("", "lokoctl-etcd-snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary snapshot file: %w", err)
+	}
+
+	// The file is unlinked right away, it will be removed when closed.
+	if err := os.Remove(f.Name()); err != nil {
+		f.Close() //nolint:errcheck,gosec
+
+		return nil, fmt.Errorf("removing temporary snapshot file: %w", err)
+	}
+
+	if err := store.Download(snapshot, f); err != nil {
+		f.Close() //nolint:errcheck,gosec
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// etcdHosts returns hostnames of etcd endpoints.
+func etcdHosts(endpoints []string) ([]string, error) {
+	hosts := []string{}
+
+	for _, e := range endpoints {
+		u, err := url.Parse(e)
+		if err != nil {
+			return nil, fmt.Errorf("parsing etcd endpoint %q: %w", e, err)
+		}
+
+		hosts = append(hosts, u.Hostname())
+	}
+
+	return hosts, nil
+}
+
+func (c *cluster) restoreEtcd(contextLogger *log.Entry, options EtcdRestoreOptions) error {
+	hosts, err := c.controllerHosts(options.Hosts)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := c.openSnapshot(options.Snapshot)
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+
+	defer snapshot.Close() //nolint:errcheck
+
+	nodes := []*sshutil.Client{}
+
+	defer func() {
+		for _, n := range nodes {
+			n.Close() //nolint:errcheck,gosec
+		}
+	}()
+
+	// Connect to all nodes before changing anything, so restore is not interrupted halfway
+	// because of unreachable node.
+	for _, host := range hosts {
+		n, err := sshutil.Dial(host, options.SSH)
+		if err != nil {
+			return fmt.Errorf("connecting to controller node: %w", err)
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	snapshotPaths := map[*sshutil.Client]string{}
+
+	for _, n := range nodes {
+		contextLogger.Infof("Uploading snapshot to %q", n.Host())
+
+		dir, err := n.Run("sudo mktemp -d "+remoteRestoreDirTemplate, nil)
+		if err != nil {
+			return fmt.Errorf("creating snapshot directory: %w", err)
+		}
+
+		dir = strings.TrimSpace(dir)
+
+		// The snapshot contains all cluster secrets, so always remove it from the node.
+		defer n.Run("sudo rm -rf "+dir, nil) //nolint:errcheck
+
+		snapshotPaths[n] = dir + "/snapshot.db"
+
+		if _, err := snapshot.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("rewinding snapshot file: %w", err)
+		}
+
+		upload := fmt.Sprintf("sudo bash -c 'umask 077 && cat > %s'", snapshotPaths[n])
+
+		if _, err := n.Run(upload, snapshot); err != nil {
+			return fmt.Errorf("uploading snapshot: %w", err)
+		}
+	}
+
+	// All members must be stopped before restoring, as restored members form a new cluster.
+	for _, n := range nodes {
+		contextLogger.Infof("Stopping etcd on %q", n.Host())
+
+		if _, err := n.Run("sudo systemctl stop etcd", nil); err != nil {
+			return fmt.Errorf("stopping etcd: %w", err)
+		}
+	}
+
+	for _, n := range nodes {
+		contextLogger.Infof("Restoring etcd data on %q", n.Host())
+
+		if _, err := n.Run("sudo bash -s -- "+snapshotPaths[n], strings.NewReader(etcdRestoreScript)); err != nil {
+			return fmt.Errorf("restoring etcd data: %w", err)
+		}
+	}
+
+	for _, n := range nodes {
+		contextLogger.Infof("Starting etcd on %q", n.Host())
+
+		if _, err := n.Run("sudo systemctl start etcd", nil); err != nil {
+			return fmt.Errorf("starting etcd: %w", err)
+		}
+	}
+
+	contextLogger.Info("Waiting for etcd cluster to become healthy")
+
+	return waitForEtcd(contextLogger, nodes[0])
+}
+
+// waitForEtcd waits until all etcd members are healthy, checking their health from given
+// controller node, as etcd is often reachable only from the cluster network.
+func waitForEtcd(contextLogger *log.Entry, node *sshutil.Client) error {
+	return wait.PollImmediate(etcdRestoreHealthInterval, etcdRestoreHealthTimeout, func() (bool, error) {
+		if _, err := node.Run("sudo bash -s", strings.NewReader(etcdHealthScript)); err != nil {
+			contextLogger.Debugf("Etcd cluster is not healthy yet: %v", err)
+
+			return false, nil
+		}
+
+		return true, nil
+	})
+}
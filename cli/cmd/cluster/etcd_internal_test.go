@@ -0,0 +1,34 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEtcdHosts(t *testing.T) {
+	endpoints := []string{"https://foo-etcd0.example.com:2379", "https://10.0.0.2:2379"}
+
+	hosts, err := etcdHosts(endpoints)
+	if err != nil {
+		t.Fatalf("Getting hosts from valid endpoints should succeed, got: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"foo-etcd0.example.com", "10.0.0.2"}, hosts); diff != "" {
+		t.Fatalf("Unexpected hosts (-want +got)\n%s", diff)
+	}
+}
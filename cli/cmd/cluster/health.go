@@ -15,41 +15,120 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"io"
+	"path/filepath"
+	"sort"
 	"text/tabwriter"
+	"time"
 
+	"github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/kinvolk/lokomotive/pkg/components"
+	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/config"
+	"github.com/kinvolk/lokomotive/pkg/etcd"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 	"github.com/kinvolk/lokomotive/pkg/lokomotive"
+	"github.com/kinvolk/lokomotive/pkg/platform"
 )
 
 // HealthOptions controls Health() behavior.
 type HealthOptions struct {
-	ConfigPath string
-	ValuesPath string
+	ConfigPaths []string
+	ValuesPath  string
+	// Wait is the maximum time to wait for the cluster to become healthy.
+	// If zero, the health is checked only once.
+	Wait time.Duration
 }
 
-// Health prints cluster health status.
-//
-//nolint:funlen
-func Health(contextLogger *log.Entry, options HealthOptions) error {
-	lokoConfig, diags := config.LoadConfig(options.ConfigPath, options.ValuesPath)
+// healthPollInterval is how often the health is re-checked when waiting for the cluster to become healthy.
+const healthPollInterval = 10 * time.Second
+
+// HealthResult describes the health of the cluster.
+type HealthResult struct {
+	// Healthy is true when all expected nodes are registered and ready, all Helm releases
+	// are deployed and all their workloads are ready.
+	Healthy      bool                       `json:"healthy"`
+	Nodes        []lokomotive.NodeReadiness `json:"nodes"`
+	MissingNodes int                        `json:"missingNodes"`
+	// Etcd is nil on managed platforms, which do not give access to etcd.
+	Etcd         *EtcdHealth        `json:"etcd,omitempty"`
+	ControlPlane ControlPlaneHealth `json:"controlPlane"`
+	Components   []ReleaseHealth    `json:"components"`
+}
+
+// EtcdHealth describes the health of etcd.
+type EtcdHealth struct {
+	// Healthy is true when the API server reports etcd as ready and all reachable
+	// members are healthy.
+	Healthy bool `json:"healthy"`
+	// Reachable is false when no etcd member can be reached from lokoctl, e.g. when etcd
+	// only accepts connections from the cluster network. Then only the health reported
+	// by the API server is known.
+	Reachable bool                `json:"reachable"`
+	Members   []etcd.MemberHealth `json:"members"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// ControlPlaneHealth describes the health of the self-hosted controlplane.
+type ControlPlaneHealth struct {
+	Releases  []ReleaseHealth             `json:"releases"`
+	Workloads []lokomotive.WorkloadHealth `json:"workloads"`
+}
+
+// ReleaseHealth describes the status of a Helm release and it's workloads.
+type ReleaseHealth struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status is the Helm release status. Empty if the release is not installed.
+	Status    string                      `json:"status"`
+	Workloads []lokomotive.WorkloadHealth `json:"workloads,omitempty"`
+}
+
+// Healthy returns true if the release is deployed and all it's workloads are ready.
+func (r ReleaseHealth) Healthy() bool {
+	return r.Status == string(release.StatusDeployed) && workloadsReady(r.Workloads)
+}
+
+func workloadsReady(workloads []lokomotive.WorkloadHealth) bool {
+	for _, w := range workloads {
+		if !w.Ready {
+			return false
+		}
+	}
+
+	return true
+}
+
+// healthChecker gathers health information about the cluster.
+type healthChecker struct {
+	kubeconfig []byte
+	platform   platform.Platform
+	cluster    *lokomotive.Cluster
+	components []components.Component
+}
+
+// Health returns cluster health status.
+func Health(contextLogger *log.Entry, options HealthOptions) (*HealthResult, error) {
+	lokoConfig, diags := config.LoadConfig(options.ConfigPaths, options.ValuesPath)
 	if diags.HasErrors() {
 		for _, diagnostic := range diags {
 			contextLogger.Error(diagnostic.Error())
 		}
 
-		return diags
+		return nil, diags
 	}
 
 	kg := kubeconfigGetter{
 		platformRequired: true,
 		clusterConfig: clusterConfig{
-			configPath: options.ConfigPath,
-			valuesPath: options.ValuesPath,
+			configPaths: options.ConfigPaths,
+			valuesPath:  options.ValuesPath,
 		},
 	}
 
@@ -57,60 +136,384 @@ func Health(contextLogger *log.Entry, options HealthOptions) error {
 	if err != nil {
 		contextLogger.Debugf("Error in finding kubeconfig file: %s", err)
 
-		return fmt.Errorf("suitable kubeconfig file not found. Did you run 'lokoctl cluster apply' ?")
+		return nil, fmt.Errorf("suitable kubeconfig file not found. Did you run 'lokoctl cluster apply' ?")
 	}
 
 	cs, err := k8sutil.NewClientset(kubeconfig)
 	if err != nil {
-		return fmt.Errorf("creating Kubernetes client: %w", err)
+		return nil, fmt.Errorf("creating Kubernetes client: %w", err)
 	}
 
 	// We can skip error checking here, as getKubeconfig() already checks it.
 	p, _ := getConfiguredPlatform(lokoConfig, true)
 
-	cluster := lokomotive.NewCluster(cs, p.Meta().ExpectedNodes)
+	componentObjects, err := componentNamesToObjects(selectComponentNames(nil, *lokoConfig.RootConfig))
+	if err != nil {
+		return nil, fmt.Errorf("getting component objects: %w", err)
+	}
+
+	for _, component := range componentObjects {
+		componentName := component.Metadata().Name
+
+		if diags := loadComponentConfig(lokoConfig, component, lokoConfig.LoadComponentConfigBody(componentName)); diags.HasErrors() { //nolint:lll
+			return nil, diags
+		}
+	}
+
+	hc := &healthChecker{
+		kubeconfig: kubeconfig,
+		platform:   p,
+		cluster:    lokomotive.NewCluster(cs, p.Meta().ExpectedNodes),
+		components: componentObjects,
+	}
+
+	if options.Wait == 0 {
+		return hc.check()
+	}
+
+	var result *HealthResult
+
+	// Errors are not propagated from the condition function, as they might be
+	// caused by the cluster not being fully functional yet.
+	err = wait.PollImmediate(healthPollInterval, options.Wait, func() (bool, error) {
+		r, err := hc.check()
+		if err != nil {
+			contextLogger.Debugf("Checking cluster health failed: %v", err)
+
+			return false, nil
+		}
+
+		result = r
+
+		if !r.Healthy {
+			contextLogger.Info("Cluster is not healthy yet, waiting")
+		}
+
+		return r.Healthy, nil
+	})
+
+	if result == nil {
+		return nil, fmt.Errorf("waiting for cluster health: %w", err)
+	}
+
+	return result, nil
+}
+
+func (hc *healthChecker) check() (*HealthResult, error) {
+	ns, err := hc.cluster.GetNodeStatus()
+	if err != nil {
+		return nil, fmt.Errorf("getting node status: %w", err)
+	}
+
+	result := &HealthResult{
+		Healthy:      ns.Ready(),
+		Nodes:        ns.Nodes(),
+		MissingNodes: ns.MissingNodes(),
+		ControlPlane: ControlPlaneHealth{
+			Releases:  []ReleaseHealth{},
+			Workloads: []lokomotive.WorkloadHealth{},
+		},
+		Components: []ReleaseHealth{},
+	}
+
+	if !result.Healthy {
+		return result, nil
+	}
+
+	// Managed platforms do not give access to etcd and the controlplane.
+	if !hc.platform.Meta().Managed {
+		if result.Etcd, err = hc.etcdHealth(); err != nil {
+			return nil, fmt.Errorf("getting etcd health: %w", err)
+		}
+
+		if result.ControlPlane, err = hc.controlPlaneHealth(); err != nil {
+			return nil, fmt.Errorf("getting controlplane health: %w", err)
+		}
+	}
+
+	if result.Components, err = hc.componentsHealth(); err != nil {
+		return nil, fmt.Errorf("getting components health: %w", err)
+	}
+
+	result.Healthy = workloadsReady(result.ControlPlane.Workloads)
+
+	if result.Etcd != nil {
+		result.Healthy = result.Healthy && result.Etcd.Healthy
+	}
+
+	for _, releases := range [][]ReleaseHealth{result.ControlPlane.Releases, result.Components} {
+		for _, r := range releases {
+			result.Healthy = result.Healthy && r.Healthy()
+		}
+	}
+
+	return result, nil
+}
+
+// etcdHealth checks etcd readiness through the API server and additionally probes etcd
+// members directly, using client certificates from the assets directory. Members are
+// often reachable only from the cluster network, so failing to reach them is not an error.
+func (hc *healthChecker) etcdHealth() (*EtcdHealth, error) {
+	eh := &EtcdHealth{
+		Members: []etcd.MemberHealth{},
+	}
+
+	_, err := hc.cluster.KubeClient.Discovery().RESTClient().Get().AbsPath("/readyz/etcd").DoRaw(context.TODO())
+	if err != nil {
+		eh.Error = fmt.Sprintf("API server reports etcd as not ready: %v", err)
+	}
+
+	eh.Healthy = err == nil
+
+	assetDir, err := homedir.Expand(hc.platform.Meta().AssetDir)
+	if err != nil {
+		return nil, fmt.Errorf("expanding path %q: %w", hc.platform.Meta().AssetDir, err)
+	}
+
+	clusterAssetsDir := filepath.Join(assetDir, "cluster-assets")
+
+	endpoints, err := etcd.AssetDirEndpoints(clusterAssetsDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting etcd endpoints: %w", err)
+	}
+
+	client, err := etcd.NewClient(endpoints, etcd.AssetDirTLSFiles(clusterAssetsDir))
+	if err != nil {
+		return nil, fmt.Errorf("creating etcd client: %w", err)
+	}
+
+	members, err := client.Health()
+	if err != nil {
+		log.Debugf("Etcd members are not reachable: %v", err)
+
+		return eh, nil
+	}
+
+	eh.Reachable = true
+	eh.Members = members
+
+	for _, m := range members {
+		eh.Healthy = eh.Healthy && m.Healthy
+	}
+
+	return eh, nil
+}
+
+// controlPlaneHealth checks the status of controlplane Helm releases and of the
+// workloads listed in the platform metadata.
+func (hc *healthChecker) controlPlaneHealth() (ControlPlaneHealth, error) {
+	cp := ControlPlaneHealth{
+		Releases:  []ReleaseHealth{},
+		Workloads: []lokomotive.WorkloadHealth{},
+	}
+
+	meta := hc.platform.Meta()
+
+	for _, c := range meta.ControlplaneCharts {
+		rh, err := hc.releaseHealth(c.Name, c.Namespace)
+		if err != nil {
+			return cp, fmt.Errorf("checking controlplane release %q: %w", c.Name, err)
+		}
+
+		cp.Releases = append(cp.Releases, rh)
+	}
+
+	for kind, workloads := range map[string][]platform.Workload{
+		lokomotive.KindDeployment: meta.Deployments,
+		lokomotive.KindDaemonSet:  meta.DaemonSets,
+	} {
+		for _, w := range workloads {
+			wh, err := hc.cluster.WorkloadHealth(kind, w.Namespace, w.Name)
+			if err != nil {
+				return cp, fmt.Errorf("checking controlplane workload: %w", err)
+			}
+
+			cp.Workloads = append(cp.Workloads, wh)
+		}
+	}
+
+	sortWorkloads(cp.Workloads)
+
+	return cp, nil
+}
 
-	ns, err := cluster.GetNodeStatus()
+// componentsHealth checks the status of Helm releases of all configured components and
+// readiness of workloads found in the rendered component manifests.
+func (hc *healthChecker) componentsHealth() ([]ReleaseHealth, error) {
+	releases := []ReleaseHealth{}
+
+	for _, component := range hc.components {
+		name := component.Metadata().Name
+		namespace := component.Metadata().Namespace.Name
+
+		rh, err := hc.releaseHealth(name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("checking component %q: %w", name, err)
+		}
+
+		if rh.Workloads, err = hc.componentWorkloadsHealth(component); err != nil {
+			return nil, fmt.Errorf("checking workloads of component %q: %w", name, err)
+		}
+
+		releases = append(releases, rh)
+	}
+
+	return releases, nil
+}
+
+func (hc *healthChecker) releaseHealth(name, namespace string) (ReleaseHealth, error) {
+	rh := ReleaseHealth{
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	actionConfig, err := util.HelmActionConfig(namespace, hc.kubeconfig)
+	if err != nil {
+		return rh, fmt.Errorf("initializing Helm action: %w", err)
+	}
+
+	status, err := util.ReleaseStatus(actionConfig, name)
 	if err != nil {
-		return fmt.Errorf("getting node status: %w", err)
+		return rh, fmt.Errorf("getting release status: %w", err)
 	}
 
-	ns.PrettyPrint()
+	rh.Status = string(status)
 
-	if !ns.Ready() {
-		return fmt.Errorf("cluster is not completely ready")
+	return rh, nil
+}
+
+func (hc *healthChecker) componentWorkloadsHealth(component components.Component) ([]lokomotive.WorkloadHealth, error) { //nolint:lll
+	manifest, err := util.ComponentManifest(component)
+	if err != nil {
+		return nil, fmt.Errorf("rendering manifests: %w", err)
 	}
 
-	components, err := cluster.Health()
+	objects, err := k8sutil.LoadManifests(map[string]string{"manifest": manifest})
 	if err != nil {
-		return fmt.Errorf("getting Lokomotive cluster health: %w", err)
+		return nil, fmt.Errorf("parsing manifests: %w", err)
+	}
+
+	workloads := []lokomotive.WorkloadHealth{}
+
+	for _, o := range objects {
+		switch o.Kind() {
+		case lokomotive.KindDeployment, lokomotive.KindDaemonSet, lokomotive.KindStatefulSet:
+		default:
+			continue
+		}
+
+		// Objects without namespace are created in the release namespace.
+		namespace := o.Namespace()
+		if namespace == "" {
+			namespace = component.Metadata().Namespace.Name
+		}
+
+		wh, err := hc.cluster.WorkloadHealth(o.Kind(), namespace, o.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		workloads = append(workloads, wh)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	sortWorkloads(workloads)
+
+	return workloads, nil
+}
+
+func sortWorkloads(workloads []lokomotive.WorkloadHealth) {
+	sort.Slice(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+
+		return a.Name < b.Name
+	})
+}
+
+// PrintTable prints the health status as human-readable tables.
+func (r *HealthResult) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
 
 	// Print the header.
-	fmt.Fprintln(w, "Name\tStatus\tMessage\tError\t")
+	fmt.Fprintln(w, "\nNode\tReady\tReason\tMessage\t")
 
 	// An empty line between header and the body.
 	fmt.Fprintln(w, "\t\t\t\t")
 
-	for _, component := range components {
-		// The client-go library defines only one `ComponenetConditionType` at the moment,
-		// which is `ComponentHealthy`. However, iterating over the list keeps this from
-		// breaking in case client-go adds another `ComponentConditionType`.
-		for _, condition := range component.Conditions {
-			line := fmt.Sprintf(
-				"%s\t%s\t%s\t%s\t",
-				component.Name, condition.Status, condition.Message, condition.Error,
-			)
+	for _, node := range r.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", node.Name, node.Ready, node.Reason, node.Message)
+	}
 
-			fmt.Fprintln(w, line)
+	if r.MissingNodes > 0 {
+		fmt.Fprintf(w, "%d nodes are missing\n", r.MissingNodes)
+	}
+
+	if r.Etcd != nil {
+		printEtcdHealth(w, r.Etcd)
+	}
+
+	releases := append(append([]ReleaseHealth{}, r.ControlPlane.Releases...), r.Components...)
+
+	if len(releases) > 0 {
+		fmt.Fprintln(w, "\nRelease\tNamespace\tStatus\t")
+		fmt.Fprintln(w, "\t\t\t")
+
+		for _, rh := range releases {
+			status := rh.Status
+			if status == "" {
+				status = "not installed"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", rh.Name, rh.Namespace, status)
 		}
+	}
 
-		if err := w.Flush(); err != nil {
-			return fmt.Errorf("flushing output: %w", err)
+	workloads := append([]lokomotive.WorkloadHealth{}, r.ControlPlane.Workloads...)
+	for _, rh := range r.Components {
+		workloads = append(workloads, rh.Workloads...)
+	}
+
+	if len(workloads) > 0 {
+		fmt.Fprintln(w, "\nKind\tNamespace\tName\tReady\tMessage\t")
+		fmt.Fprintln(w, "\t\t\t\t\t")
+
+		for _, wh := range workloads {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t\n", wh.Kind, wh.Namespace, wh.Name, wh.Ready, wh.Message)
 		}
 	}
 
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
 	return nil
 }
+
+func printEtcdHealth(w io.Writer, eh *EtcdHealth) {
+	fmt.Fprintf(w, "\nEtcd healthy:\t%t\n", eh.Healthy)
+
+	if eh.Error != "" {
+		fmt.Fprintf(w, "Etcd error:\t%s\n", eh.Error)
+	}
+
+	if !eh.Reachable {
+		fmt.Fprintln(w, "Etcd members are unreachable from this machine, showing health reported by the API server.")
+
+		return
+	}
+
+	fmt.Fprintln(w, "\nEtcd member\tEndpoint\tHealthy\tLeader\tRaft index\tLag\tDB size (bytes)\tError\t")
+	fmt.Fprintln(w, "\t\t\t\t\t\t\t\t")
+
+	for _, m := range eh.Members {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%d\t%d\t%d\t%s\t\n", m.Name, m.Endpoint, m.Healthy, m.Leader,
+			m.RaftIndex, m.RaftIndexLag, m.DBSize, m.Error)
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/sshutil"
+)
+
+const (
+	// nodeReplaceTimeout is the time given to the reprovisioned machine to install the OS
+	// and join the cluster.
+	nodeReplaceTimeout = 30 * time.Minute
+
+	// Number of seconds to wait between checks if the replaced node is ready.
+	nodeReadyRetryInterval = 10 * time.Second
+)
+
+// stopKubeletScript stops both the bootstrap and the self-hosted kubelet on the node, so the
+// node does not register in the cluster again after it is deleted. Masking is not persistent,
+// so the reinstalled machine starts the kubelet normally.
+const stopKubeletScript = `set -euo pipefail
+systemctl mask --runtime --now kubelet.service
+docker rm -f kubelet || true
+docker ps -q --filter label=io.kubernetes.container.name=kubelet | xargs -r docker rm -f
+`
+
+// rebootCommand reboots the machine without waiting for the reboot, so the command returns
+// before the SSH connection is closed.
+const rebootCommand = "sudo systemctl reboot --no-block"
+
+// NodeReplaceOptions controls ReplaceNode() behavior.
+type NodeReplaceOptions struct {
+	Confirm     bool
+	ForceDrain  bool
+	Verbose     bool
+	ConfigPaths []string
+	ValuesPath  string
+	Name        string
+	SSH         sshutil.Config
+}
+
+// ReplaceNode reprovisions a single worker machine of the cluster.
+//
+// The node is cordoned and drained, kubelet on the node is stopped over SSH and the node
+// is removed from the cluster. Then platform specific resources of the machine are tainted
+// and the cluster is applied, which reinstalls the machine, rebooting it if the platform
+// requires it. ReplaceNode returns once the reinstalled node joins the cluster and becomes ready.
+//
+//nolint:funlen
+func ReplaceNode(contextLogger *log.Entry, options NodeReplaceOptions) error {
+	cc := clusterConfig{
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
+	}
+
+	c, err := cc.initialize(contextLogger)
+	if err != nil {
+		return fmt.Errorf("initializing: %w", err)
+	}
+
+	p, ok := c.platform.(platform.PlatformWithNodeReplace)
+	if !ok {
+		return errors.New("replacing nodes is not supported on the configured platform")
+	}
+
+	exists, err := clusterExists(c.terraformExecutor)
+	if err != nil {
+		return fmt.Errorf("checking if cluster exists: %w", err)
+	}
+
+	if !exists {
+		return errors.New("cannot replace node of a non-existent cluster, use 'lokoctl cluster apply' instead")
+	}
+
+	kg := kubeconfigGetter{
+		platformRequired: true,
+		clusterConfig:    cc,
+	}
+
+	kubeconfig, err := kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
+	if err != nil {
+		return fmt.Errorf("getting kubeconfig: %w", err)
+	}
+
+	cs, err := k8sutil.NewClientset(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("creating Kubernetes clientset: %w", err)
+	}
+
+	ctx := context.TODO()
+
+	registered := true
+
+	node, err := cs.CoreV1().Nodes().Get(ctx, options.Name, metav1.GetOptions{})
+
+	switch {
+	case k8serrors.IsNotFound(err):
+		contextLogger.Warnf("Node %q is not registered in the cluster, skipping draining", options.Name)
+
+		registered = false
+		node = &corev1.Node{}
+	case err != nil:
+		return fmt.Errorf("getting node %q: %w", options.Name, err)
+	}
+
+	addresses := nodeAddresses(node)
+
+	replacement, err := p.NodeReplacement(options.Name, addresses)
+	if err != nil {
+		return fmt.Errorf("preparing node replacement: %w", err)
+	}
+
+	replaced := replacedNode{
+		name:      options.Name,
+		addresses: addresses,
+		bootID:    node.Status.NodeInfo.BootID,
+	}
+
+	host := nodeSSHHost(node, options.Name)
+
+	message := fmt.Sprintf("Node %q will be drained and its machine will be reinstalled. Do you want to proceed?", options.Name)
+	if !options.Confirm && !askForConfirmation(message) {
+		contextLogger.Println("Node replacement cancelled")
+
+		return nil
+	}
+
+	if registered {
+		contextLogger.Infof("Draining node %q", options.Name)
+
+		drainOptions := k8sutil.DrainOptions{
+			Force: options.ForceDrain,
+			Out:   contextLogger.WriterLevel(log.DebugLevel),
+		}
+
+		if err := k8sutil.DrainNode(ctx, cs, options.Name, drainOptions); err != nil {
+			return err
+		}
+
+		if err := stopKubelet(contextLogger, host, node, options.SSH); err != nil {
+			return err
+		}
+
+		if err := cs.CoreV1().Nodes().Delete(ctx, options.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("deleting node %q: %w", options.Name, err)
+		}
+	}
+
+	replaced.deletedAt = time.Now()
+
+	if err := c.terraformExecutor.Execute(replacement.Steps...); err != nil {
+		return fmt.Errorf("tainting machine resources: %w", err)
+	}
+
+	if err := c.platform.Apply(&c.terraformExecutor); err != nil {
+		return fmt.Errorf("applying platform: %w", err)
+	}
+
+	if replacement.Reboot {
+		contextLogger.Infof("Rebooting machine %q to start the installation", host)
+
+		if err := runOnNode(host, rebootCommand, options.SSH, nil); err != nil {
+			return fmt.Errorf("rebooting machine, reboot it manually to start the installation: %w", err)
+		}
+	}
+
+	contextLogger.Infof("Waiting for node %q to become ready", options.Name)
+
+	if err := waitForReplacedNode(ctx, cs, replaced, nodeReplaceTimeout); err != nil {
+		return fmt.Errorf("waiting for node %q: %w", options.Name, err)
+	}
+
+	contextLogger.Infof("Node %q replaced successfully", options.Name)
+
+	return nil
+}
+
+// stopKubelet stops kubelet on given node, so it does not register again once deleted.
+// If the node is not ready, its kubelet is most likely not running, so failing to reach
+// the node is not an error.
+func stopKubelet(contextLogger *log.Entry, host string, node *corev1.Node, config sshutil.Config) error {
+	contextLogger.Infof("Stopping kubelet on %q", host)
+
+	err := runOnNode(host, "sudo bash -s", config, strings.NewReader(stopKubeletScript))
+
+	switch {
+	case err == nil:
+		return nil
+	case !nodeReady(node):
+		contextLogger.Warnf("Stopping kubelet on not ready node failed, continuing: %v", err)
+
+		return nil
+	default:
+		return fmt.Errorf("stopping kubelet: %w", err)
+	}
+}
+
+// runOnNode runs given command on given host over SSH, with optional standard input.
+func runOnNode(host, command string, config sshutil.Config, stdin io.Reader) error {
+	n, err := sshutil.Dial(host, config)
+	if err != nil {
+		return fmt.Errorf("connecting to node: %w", err)
+	}
+
+	defer n.Close() //nolint:errcheck
+
+	_, err = n.Run(command, stdin)
+
+	return err
+}
+
+// nodeSSHHost returns the address to connect to given node over SSH. Nodes, which are
+// not registered, are reached using given name.
+func nodeSSHHost(node *corev1.Node, name string) string {
+	for _, a := range node.Status.Addresses {
+		if a.Type == corev1.NodeInternalIP {
+			return a.Address
+		}
+	}
+
+	return name
+}
+
+func nodeAddresses(node *corev1.Node) []string {
+	addresses := []string{}
+
+	for _, a := range node.Status.Addresses {
+		addresses = append(addresses, a.Address)
+	}
+
+	return addresses
+}
+
+// replacedNode identifies the node being replaced.
+type replacedNode struct {
+	name      string
+	addresses []string
+	// bootID is the boot ID of the node before replacement, if it was registered.
+	bootID string
+	// deletedAt is the time the node was removed from the cluster.
+	deletedAt time.Time
+}
+
+// waitForReplacedNode waits until the reinstalled machine of given node registers and becomes
+// ready. The node is matched by name or one of the addresses and it must be created after the old
+// node was deleted, with a different boot ID, so the node is not the old one registered again.
+func waitForReplacedNode(ctx context.Context, cs kubernetes.Interface, replaced replacedNode, timeout time.Duration) error { //nolint:lll
+	return wait.PollImmediate(nodeReadyRetryInterval, timeout, func() (bool, error) {
+		nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			// Transient API errors should not abort waiting.
+			return false, nil
+		}
+
+		for i := range nodes.Items {
+			n := &nodes.Items[i]
+
+			if !nodeMatches(n, replaced.name, replaced.addresses) || !nodeReinstalled(n, replaced) {
+				continue
+			}
+
+			return nodeReady(n), nil
+		}
+
+		return false, nil
+	})
+}
+
+func nodeMatches(node *corev1.Node, name string, addresses []string) bool {
+	if node.Name == name {
+		return true
+	}
+
+	for _, a := range nodeAddresses(node) {
+		for _, b := range addresses {
+			if a == b {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// nodeReinstalled returns true if given node is not the replaced node registered again.
+func nodeReinstalled(node *corev1.Node, replaced replacedNode) bool {
+	if replaced.bootID != "" && node.Status.NodeInfo.BootID == replaced.bootID {
+		return false
+	}
+
+	// Creation timestamps have second precision.
+	return !node.CreationTimestamp.Time.Before(replaced.deletedAt.Truncate(time.Second))
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// deletedAt is the time the replaced node was deleted in tests.
+var deletedAt = time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func testNode(name, address string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(deletedAt.Add(time.Minute)),
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: address},
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				BootID: "new",
+			},
+		},
+	}
+}
+
+func TestWaitForReplacedNode(t *testing.T) {
+	reregisteredNode := testNode("foo", "10.0.0.2", corev1.ConditionTrue)
+	reregisteredNode.Status.NodeInfo.BootID = "old"
+
+	cases := map[string]struct {
+		node        *corev1.Node
+		name        string
+		addresses   []string
+		bootID      string
+		expectError bool
+	}{
+		"ready_node_with_same_name": {
+			node: testNode("foo", "10.0.0.2", corev1.ConditionTrue),
+			name: "foo",
+		},
+		"ready_node_with_same_address": {
+			node:      testNode("bar", "10.0.0.2", corev1.ConditionTrue),
+			name:      "foo",
+			addresses: []string{"10.0.0.2"},
+		},
+		"not_ready_node": {
+			node:        testNode("foo", "10.0.0.2", corev1.ConditionFalse),
+			name:        "foo",
+			expectError: true,
+		},
+		"other_node": {
+			node:        testNode("bar", "10.0.0.3", corev1.ConditionTrue),
+			name:        "foo",
+			addresses:   []string{"10.0.0.2"},
+			expectError: true,
+		},
+		"old_node_registered_again": {
+			node:        reregisteredNode,
+			name:        "foo",
+			bootID:      "old",
+			expectError: true,
+		},
+		"node_created_before_deletion": {
+			node: func() *corev1.Node {
+				n := testNode("foo", "10.0.0.2", corev1.ConditionTrue)
+				n.CreationTimestamp = metav1.NewTime(deletedAt.Add(-time.Hour))
+
+				return n
+			}(),
+			name:        "foo",
+			expectError: true,
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			t.Parallel()
+
+			cs := fake.NewSimpleClientset(c.node)
+
+			replaced := replacedNode{
+				name:      c.name,
+				addresses: c.addresses,
+				bootID:    c.bootID,
+				deletedAt: deletedAt,
+			}
+
+			err := waitForReplacedNode(context.TODO(), cs, replaced, time.Millisecond)
+
+			if c.expectError && err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if !c.expectError && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
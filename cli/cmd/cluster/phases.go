@@ -0,0 +1,179 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Names of the phases of cluster apply, in the order they are executed.
+const (
+	ApplyPhaseHealthCheck    = "health-check"
+	ApplyPhaseInfrastructure = "infrastructure"
+	ApplyPhaseNamespaces     = "namespaces"
+	ApplyPhaseControlPlane   = "controlplane"
+	ApplyPhasePostApplyHook  = "post-apply-hook"
+	ApplyPhaseVerify         = "verify"
+	ApplyPhaseComponents     = "components"
+
+	// applyProgressFile is the file in the asset directory, where progress of cluster apply is stored.
+	applyProgressFile = "apply-progress.json"
+)
+
+// ApplyPhases returns the names of the phases of cluster apply, in execution order.
+func ApplyPhases() []string {
+	return []string{
+		ApplyPhaseHealthCheck,
+		ApplyPhaseInfrastructure,
+		ApplyPhaseNamespaces,
+		ApplyPhaseControlPlane,
+		ApplyPhasePostApplyHook,
+		ApplyPhaseVerify,
+		ApplyPhaseComponents,
+	}
+}
+
+// applyPhase is a single named step of cluster apply.
+type applyPhase struct {
+	name string
+	run  func() error
+}
+
+// applyProgress is the progress of an unfinished cluster apply, persisted in the asset
+// directory, so failed apply can be resumed from the last successful phase.
+type applyProgress struct {
+	// ConfigChecksum is the checksum of the configuration used by the unfinished apply.
+	// Progress is only resumed when the configuration has not changed.
+	ConfigChecksum string `json:"configChecksum"`
+	// ClusterExisted is true if the cluster existed before the unfinished apply started.
+	ClusterExisted bool `json:"clusterExisted"`
+	// CompletedPhases are the names of the phases which finished successfully.
+	CompletedPhases []string `json:"completedPhases"`
+}
+
+func applyProgressPath(assetDir string) string {
+	return filepath.Join(assetDir, applyProgressFile)
+}
+
+// loadApplyProgress reads the progress of the unfinished apply from the asset directory.
+// If there is no unfinished apply, empty progress is returned.
+func loadApplyProgress(assetDir string) (*applyProgress, error) {
+	progress := &applyProgress{}
+
+	content, err := ioutil.ReadFile(applyProgressPath(assetDir))
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading apply progress: %w", err)
+	}
+
+	if err := json.Unmarshal(content, progress); err != nil {
+		return nil, fmt.Errorf("parsing apply progress file %q: %w", applyProgressPath(assetDir), err)
+	}
+
+	return progress, nil
+}
+
+func (p *applyProgress) save(assetDir string) error {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding apply progress: %w", err)
+	}
+
+	if err := ioutil.WriteFile(applyProgressPath(assetDir), content, 0o600); err != nil {
+		return fmt.Errorf("writing apply progress: %w", err)
+	}
+
+	return nil
+}
+
+func (p *applyProgress) completed(phase string) bool {
+	for _, c := range p.CompletedPhases {
+		if c == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resumable returns true if the progress comes from an unfinished apply of the same configuration.
+func (p *applyProgress) resumable(checksum string) bool {
+	return len(p.CompletedPhases) > 0 && p.ConfigChecksum == checksum
+}
+
+// startPhase returns the index of the phase from which cluster apply should start.
+//
+// If fromPhase is given, apply starts from it. Otherwise, if the progress is resumable,
+// apply starts from the first phase which has not been completed.
+func startPhase(phases []string, progress *applyProgress, checksum, fromPhase string) (int, error) {
+	if fromPhase != "" {
+		for i, p := range phases {
+			if p == fromPhase {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("unknown phase %q, valid phases are: %v", fromPhase, phases)
+	}
+
+	if !progress.resumable(checksum) {
+		return 0, nil
+	}
+
+	for i, p := range phases {
+		if !progress.completed(p) {
+			return i, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// runApplyPhases runs given phases starting from the given index, recording progress in the
+// asset directory after each phase. Once all phases complete, the progress file is removed.
+func runApplyPhases(phases []applyPhase, start int, progress *applyProgress, assetDir string) error {
+	// Phases before the start one are treated as completed, so when running from
+	// a given phase fails, the next run resumes from the failed phase.
+	progress.CompletedPhases = []string{}
+
+	for _, p := range phases[:start] {
+		progress.CompletedPhases = append(progress.CompletedPhases, p.name)
+	}
+
+	for _, p := range phases[start:] {
+		if err := p.run(); err != nil {
+			return fmt.Errorf("running phase %q: %w", p.name, err)
+		}
+
+		progress.CompletedPhases = append(progress.CompletedPhases, p.name)
+
+		if err := progress.save(assetDir); err != nil {
+			return fmt.Errorf("saving progress after phase %q: %w", p.name, err)
+		}
+	}
+
+	if err := os.Remove(applyProgressPath(assetDir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing apply progress file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStartPhase(t *testing.T) {
+	phases := []string{"a", "b", "c"}
+
+	cases := map[string]struct {
+		progress      *applyProgress
+		fromPhase     string
+		expectedStart int
+		expectError   bool
+	}{
+		"no_progress": {
+			progress:      &applyProgress{},
+			expectedStart: 0,
+		},
+		"resume_unfinished_apply": {
+			progress:      &applyProgress{ConfigChecksum: "foo", CompletedPhases: []string{"a", "b"}},
+			expectedStart: 2,
+		},
+		"changed_configuration": {
+			progress:      &applyProgress{ConfigChecksum: "bar", CompletedPhases: []string{"a", "b"}},
+			expectedStart: 0,
+		},
+		"from_phase_overrides_progress": {
+			progress:      &applyProgress{ConfigChecksum: "foo", CompletedPhases: []string{"a", "b"}},
+			fromPhase:     "a",
+			expectedStart: 0,
+		},
+		"from_phase": {
+			progress:      &applyProgress{},
+			fromPhase:     "c",
+			expectedStart: 2,
+		},
+		"unknown_phase": {
+			progress:    &applyProgress{},
+			fromPhase:   "d",
+			expectError: true,
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			start, err := startPhase(phases, c.progress, "foo", c.fromPhase)
+			if c.expectError {
+				if err == nil {
+					t.Fatalf("Expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if start != c.expectedStart {
+				t.Fatalf("Expected start phase %d, got %d", c.expectedStart, start)
+			}
+		})
+	}
+}
+
+func TestApplyPhasesAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+
+	for _, p := range ApplyPhases() {
+		if seen[p] {
+			t.Fatalf("Phase %q is defined more than once", p)
+		}
+
+		seen[p] = true
+	}
+}
+
+func testPhases(ran *[]string, failing string) []applyPhase {
+	phases := []applyPhase{}
+
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+
+		phases = append(phases, applyPhase{
+			name: name,
+			run: func() error {
+				if name == failing {
+					return errors.New("failed")
+				}
+
+				*ran = append(*ran, name)
+
+				return nil
+			},
+		})
+	}
+
+	return phases
+}
+
+func TestRunApplyPhasesRecordsProgress(t *testing.T) {
+	assetDir, err := ioutil.TempDir("", "lokoctl-tests")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(assetDir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	ran := []string{}
+	progress := &applyProgress{ConfigChecksum: "foo"}
+
+	if err := runApplyPhases(testPhases(&ran, "b"), 0, progress, assetDir); err == nil {
+		t.Fatalf("Running failing phase should return error")
+	}
+
+	progress, err = loadApplyProgress(assetDir)
+	if err != nil {
+		t.Fatalf("Loading progress: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"a"}, progress.CompletedPhases); diff != "" {
+		t.Fatalf("Unexpected completed phases (-want +got)\n%s", diff)
+	}
+
+	start, err := startPhase([]string{"a", "b", "c"}, progress, "foo", "")
+	if err != nil {
+		t.Fatalf("Selecting start phase: %v", err)
+	}
+
+	if err := runApplyPhases(testPhases(&ran, ""), start, progress, assetDir); err != nil {
+		t.Fatalf("Resuming phases should succeed, got: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"a", "b", "c"}, ran); diff != "" {
+		t.Fatalf("Unexpected phases run (-want +got)\n%s", diff)
+	}
+
+	if _, err := os.Stat(applyProgressPath(assetDir)); !os.IsNotExist(err) {
+		t.Fatalf("Progress file should be removed after all phases complete, got: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// PlanOptions controls Plan() behavior.
+type PlanOptions struct {
+	UpgradeKubelets bool
+	Verbose         bool
+	ConfigPaths     []string
+	ValuesPath      string
+}
+
+// PlanReport describes all changes, which 'lokoctl cluster apply' would make to the cluster.
+type PlanReport struct {
+	// Infrastructure contains resources, which would be changed by Terraform.
+	Infrastructure []terraform.ResourceChange `json:"infrastructure"`
+	// ControlPlane contains changes to the controlplane Helm releases.
+	ControlPlane []ReleaseChanges `json:"controlPlane"`
+	// Components contains changes to the component Helm releases.
+	Components ComponentChanges `json:"components"`
+}
+
+// ReleaseChanges describes changes to the objects of a single Helm release.
+type ReleaseChanges struct {
+	Name      string              `json:"name"`
+	Namespace string              `json:"namespace"`
+	Installed bool                `json:"installed"`
+	Changes   []util.ObjectChange `json:"changes"`
+}
+
+// PrintTable prints a summary of all planned changes.
+func (r *PlanReport) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+
+	fmt.Fprintln(w, "Infrastructure changes:")
+
+	if len(r.Infrastructure) == 0 {
+		fmt.Fprintln(w, "\tNo changes.")
+	}
+
+	for _, rc := range r.Infrastructure {
+		fmt.Fprintf(w, "\t%s\t%s\n", strings.Join(rc.Actions, ", "), rc.Address)
+	}
+
+	printReleasesChanges(w, "Controlplane changes:", r.ControlPlane)
+	printReleasesChanges(w, "Components changes:", r.Components)
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flushing output: %w", err)
+	}
+
+	return nil
+}
+
+func printReleasesChanges(w io.Writer, title string, releases []ReleaseChanges) {
+	fmt.Fprintf(w, "\n%s\n", title)
+
+	changed := false
+
+	for _, release := range releases {
+		if len(release.Changes) == 0 {
+			continue
+		}
+
+		changed = true
+
+		fmt.Fprintf(w, "\t%s/%s:\n", release.Namespace, release.Name)
+
+		for _, change := range release.Changes {
+			fmt.Fprintf(w, "\t\t%s\t%s\t%s\n", change.Action, change.Kind, change.Name)
+		}
+	}
+
+	if !changed {
+		fmt.Fprintln(w, "\tNo changes.")
+	}
+}
+
+// Plan calculates changes, which applying the cluster configuration would make, without
+// changing anything in the cluster.
+//
+// Controlplane changes are calculated using values from the current Terraform state, so if
+// the infrastructure plan changes those values, it won't be reflected in the controlplane changes.
+func Plan(contextLogger *log.Entry, options PlanOptions) (*PlanReport, error) {
+	cc := clusterConfig{
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
+	}
+
+	c, err := cc.initialize(contextLogger)
+	if err != nil {
+		return nil, fmt.Errorf("initializing: %w", err)
+	}
+
+	exists, err := clusterExists(c.terraformExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("checking if cluster exists: %w", err)
+	}
+
+	report := &PlanReport{
+		ControlPlane: []ReleaseChanges{},
+		Components:   ComponentChanges{},
+	}
+
+	if report.Infrastructure, err = c.terraformExecutor.PlanChanges(); err != nil {
+		return nil, fmt.Errorf("planning infrastructure changes: %w", err)
+	}
+
+	componentObjects, err := componentNamesToObjects(selectComponentNames(nil, *c.lokomotiveConfig.RootConfig))
+	if err != nil {
+		return nil, fmt.Errorf("getting component objects: %w", err)
+	}
+
+	// Without existing cluster, there is nothing to compare with, so everything will be created.
+	if !exists {
+		if report.Components, err = diffComponents(c.lokomotiveConfig, nil, componentObjects); err != nil {
+			return nil, fmt.Errorf("planning components changes: %w", err)
+		}
+
+		return report, nil
+	}
+
+	kg := kubeconfigGetter{
+		platformRequired: true,
+		clusterConfig:    cc,
+	}
+
+	kubeconfig, err := kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting kubeconfig: %w", err)
+	}
+
+	if !c.platform.Meta().Managed {
+		if report.ControlPlane, err = c.planControlPlane(contextLogger, kubeconfig, options.UpgradeKubelets); err != nil {
+			return nil, fmt.Errorf("planning controlplane changes: %w", err)
+		}
+	}
+
+	if report.Components, err = diffComponents(c.lokomotiveConfig, kubeconfig, componentObjects); err != nil {
+		return nil, fmt.Errorf("planning components changes: %w", err)
+	}
+
+	return report, nil
+}
+
+// planControlPlane calculates changes, which upgradeControlPlane would make.
+func (c *cluster) planControlPlane(contextLogger *log.Entry, kubeconfig []byte, upgradeKubelets bool) ([]ReleaseChanges, error) { //nolint:lll
+	cu := controlplaneUpdater{
+		kubeconfig:    kubeconfig,
+		assetDir:      c.assetDir,
+		contextLogger: *contextLogger,
+		ex:            c.terraformExecutor,
+	}
+
+	if err := c.unpackControlplaneCharts(); err != nil {
+		return nil, fmt.Errorf("unpacking controlplane assets: %w", err)
+	}
+
+	charts := c.platform.Meta().ControlplaneCharts
+	if !upgradeKubelets {
+		charts = removeKubeletChart(charts)
+	}
+
+	releases := []ReleaseChanges{}
+
+	for _, cpChart := range charts {
+		rc, err := cu.planComponent(cpChart.Name, cpChart.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("planning controlplane component %q: %w", cpChart.Name, err)
+		}
+
+		releases = append(releases, rc)
+	}
+
+	return releases, nil
+}
+
+// planComponent renders given controlplane chart in dry-run mode and compares the result
+// with the deployed release.
+func (c controlplaneUpdater) planComponent(component, namespace string) (ReleaseChanges, error) {
+	rc := ReleaseChanges{
+		Name:      component,
+		Namespace: namespace,
+	}
+
+	actionConfig, err := util.HelmActionConfig(namespace, c.kubeconfig)
+	if err != nil {
+		return rc, fmt.Errorf("initializing Helm action: %w", err)
+	}
+
+	helmChart, err := c.getControlplaneChart(component, namespace)
+	if err != nil {
+		return rc, fmt.Errorf("loading chart from assets: %w", err)
+	}
+
+	values, err := c.getControlplaneValues(component)
+	if err != nil {
+		return rc, fmt.Errorf("getting chart values from Terraform: %w", err)
+	}
+
+	current, exists, err := util.ReleaseManifest(actionConfig, component)
+	if err != nil {
+		return rc, fmt.Errorf("getting deployed release manifest: %w", err)
+	}
+
+	rc.Installed = exists
+
+	desired := ""
+
+	if exists {
+		upgrade := action.NewUpgrade(actionConfig)
+		upgrade.DryRun = true
+
+		rel, err := upgrade.Run(component, helmChart, values)
+		if err != nil {
+			return rc, fmt.Errorf("dry-running upgrade: %w", err)
+		}
+
+		desired = rel.Manifest
+	} else {
+		install := action.NewInstall(actionConfig)
+		install.ReleaseName = component
+		install.Namespace = namespace
+		install.DryRun = true
+
+		rel, err := install.Run(helmChart, values)
+		if err != nil {
+			return rc, fmt.Errorf("dry-running install: %w", err)
+		}
+
+		desired = rel.Manifest
+	}
+
+	if rc.Changes, err = util.DiffManifests(current, desired); err != nil {
+		return rc, fmt.Errorf("comparing manifests: %w", err)
+	}
+
+	return rc, nil
+}
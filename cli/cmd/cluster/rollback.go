@@ -0,0 +1,206 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/helm"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+)
+
+// Possible outcomes of rolling back a single controlplane release.
+const (
+	rollbackUnchanged   = "unchanged"
+	rollbackRolledBack  = "rolled back"
+	rollbackUninstalled = "uninstalled"
+	rollbackKept        = "kept, as it was not installed by the upgrade"
+)
+
+// chartRevision is a revision of the controlplane release recorded before the upgrade.
+// Revision 0 means the release was not installed.
+type chartRevision struct {
+	name      string
+	namespace string
+	revision  int
+	// created is true if the release was installed by the upgrade.
+	created bool
+}
+
+// rollbackCharts returns given controlplane charts, which are rolled back automatically
+// when the upgrade fails. The kubelet chart is excluded, as it is not rolled back.
+func rollbackCharts(charts []helm.LokomotiveChart) []helm.LokomotiveChart {
+	rc := []helm.LokomotiveChart{}
+
+	for _, chart := range charts {
+		if chart.Name != platform.KubeletChartName {
+			rc = append(rc, chart)
+		}
+	}
+
+	return rc
+}
+
+// upgradeWithRollback records the revisions of given controlplane releases and runs
+// the upgrade. If the upgrade fails, all releases are rolled back to the recorded revisions
+// in reverse order, so the cluster does not end up running mixed controlplane versions.
+func (c controlplaneUpdater) upgradeWithRollback(charts []helm.LokomotiveChart, upgrade func() error) error {
+	revisions, err := c.recordRevisions(charts)
+	if err != nil {
+		return fmt.Errorf("recording controlplane revisions: %w", err)
+	}
+
+	upgradeErr := upgrade()
+	if upgradeErr == nil {
+		return nil
+	}
+
+	fmt.Printf("\nControlplane upgrade failed: %v\n", upgradeErr)
+
+	if err := c.rollback(revisions); err != nil {
+		return fmt.Errorf("%w; automatic rollback failed, cluster may run mixed controlplane versions: %v", upgradeErr, err)
+	}
+
+	return fmt.Errorf("%w; controlplane has been rolled back to the revisions from before the upgrade", upgradeErr)
+}
+
+func (c controlplaneUpdater) recordRevisions(charts []helm.LokomotiveChart) ([]chartRevision, error) {
+	revisions := []chartRevision{}
+
+	for _, chart := range charts {
+		actionConfig, err := util.HelmActionConfig(chart.Namespace, c.kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("initializing Helm action: %w", err)
+		}
+
+		revision, err := releaseRevision(actionConfig, chart.Name)
+		if err != nil {
+			return nil, fmt.Errorf("getting revision of controlplane component %q: %w", chart.Name, err)
+		}
+
+		revisions = append(revisions, chartRevision{
+			name:      chart.Name,
+			namespace: chart.Namespace,
+			revision:  revision,
+		})
+	}
+
+	return revisions, nil
+}
+
+// rollback rolls back given releases to the recorded revisions in reverse order. Rolling back
+// continues when a release fails to roll back, so as many releases as possible are restored.
+func (c controlplaneUpdater) rollback(revisions []chartRevision) error {
+	fmt.Println("Rolling back controlplane components to revisions from before the upgrade...")
+
+	failed := []string{}
+
+	for i := len(revisions) - 1; i >= 0; i-- {
+		r := revisions[i]
+		r.created = c.installed[r.name]
+
+		fmt.Printf("Rolling back controlplane component '%s'... ", r.name)
+
+		actionConfig, err := util.HelmActionConfig(r.namespace, c.kubeconfig)
+		if err != nil {
+			fmt.Printf("Failed: initializing Helm action: %v\n", err)
+
+			failed = append(failed, r.name)
+
+			continue
+		}
+
+		outcome, err := rollbackRelease(actionConfig, r)
+		if err != nil {
+			fmt.Printf("Failed: %v\n", err)
+
+			failed = append(failed, r.name)
+
+			continue
+		}
+
+		if outcome == rollbackRolledBack {
+			outcome = fmt.Sprintf("%s to revision %d", outcome, r.revision)
+		}
+
+		fmt.Printf("Done, %s.\n", outcome)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("rolling back controlplane components failed: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// releaseRevision returns the latest revision of the given release or 0 if the release
+// is not installed.
+func releaseRevision(actionConfig *action.Configuration, name string) (int, error) {
+	history, err := helm.GetHistory(action.NewHistory(actionConfig), name, 1)
+	if err == driver.ErrReleaseNotFound {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("getting release history: %w", err)
+	}
+
+	if len(history) == 0 {
+		return 0, nil
+	}
+
+	return history[0].Version, nil
+}
+
+// rollbackRelease restores the recorded revision of the release. Releases, which were
+// installed by the upgrade get uninstalled. Releases, which were not installed before the
+// upgrade, but were not created by it either, are kept.
+func rollbackRelease(actionConfig *action.Configuration, r chartRevision) (string, error) {
+	current, err := releaseRevision(actionConfig, r.name)
+	if err != nil {
+		return "", err
+	}
+
+	if current == r.revision {
+		return rollbackUnchanged, nil
+	}
+
+	if r.revision == 0 {
+		if !r.created {
+			return rollbackKept, nil
+		}
+
+		if _, err := action.NewUninstall(actionConfig).Run(r.name); err != nil {
+			return "", fmt.Errorf("uninstalling release: %w", err)
+		}
+
+		return rollbackUninstalled, nil
+	}
+
+	rollback := action.NewRollback(actionConfig)
+	rollback.Version = r.revision
+	rollback.MaxHistory = 10
+
+	if err := rollback.Run(r.name); err != nil {
+		return "", fmt.Errorf("rolling back release to revision %d: %w", r.revision, err)
+	}
+
+	return rollbackRolledBack, nil
+}
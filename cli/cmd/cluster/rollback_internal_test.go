@@ -0,0 +1,166 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/kinvolk/lokomotive/pkg/helm"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+)
+
+func testActionConfig(t *testing.T, name string, revisions int) *action.Configuration {
+	t.Helper()
+
+	actionConfig := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(format string, v ...interface{}) {},
+	}
+
+	for i := 1; i <= revisions; i++ {
+		status := release.StatusSuperseded
+		if i == revisions {
+			status = release.StatusDeployed
+		}
+
+		r := &release.Release{
+			Name:      name,
+			Namespace: "kube-system",
+			Version:   i,
+			Info:      &release.Info{Status: status},
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{
+					Name:       name,
+					Version:    "0.1.0",
+					APIVersion: chart.APIVersionV2,
+				},
+			},
+		}
+
+		if err := actionConfig.Releases.Create(r); err != nil {
+			t.Fatalf("Creating release: %v", err)
+		}
+	}
+
+	return actionConfig
+}
+
+func TestRollbackRelease(t *testing.T) {
+	cases := map[string]struct {
+		installed        int
+		recorded         int
+		created          bool
+		expectedOutcome  string
+		expectedRevision int
+	}{
+		"unchanged_release": {
+			installed:        2,
+			recorded:         2,
+			expectedOutcome:  rollbackUnchanged,
+			expectedRevision: 2,
+		},
+		"upgraded_release": {
+			installed:        3,
+			recorded:         2,
+			expectedOutcome:  rollbackRolledBack,
+			expectedRevision: 4,
+		},
+		"newly_installed_release": {
+			installed:        1,
+			recorded:         0,
+			created:          true,
+			expectedOutcome:  rollbackUninstalled,
+			expectedRevision: 0,
+		},
+		"release_not_installed_by_upgrade": {
+			installed:        1,
+			recorded:         0,
+			expectedOutcome:  rollbackKept,
+			expectedRevision: 1,
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			name := "foo"
+			actionConfig := testActionConfig(t, name, c.installed)
+
+			outcome, err := rollbackRelease(actionConfig, chartRevision{
+				name:      name,
+				namespace: "kube-system",
+				revision:  c.recorded,
+				created:   c.created,
+			})
+			if err != nil {
+				t.Fatalf("Rolling back release should succeed, got: %v", err)
+			}
+
+			if outcome != c.expectedOutcome {
+				t.Fatalf("Expected outcome %q, got %q", c.expectedOutcome, outcome)
+			}
+
+			revision, err := releaseRevision(actionConfig, name)
+			if err != nil {
+				t.Fatalf("Getting release revision: %v", err)
+			}
+
+			if revision != c.expectedRevision {
+				t.Fatalf("Expected revision %d after rollback, got %d", c.expectedRevision, revision)
+			}
+		})
+	}
+}
+
+func TestRollbackReleaseMissingRevision(t *testing.T) {
+	name := "foo"
+	actionConfig := testActionConfig(t, name, 1)
+
+	if _, err := rollbackRelease(actionConfig, chartRevision{name: name, revision: 5}); err == nil {
+		t.Fatalf("Rolling back to non-existing revision should fail")
+	}
+}
+
+func TestRollbackChartsExcludesKubelet(t *testing.T) {
+	charts := []helm.LokomotiveChart{
+		{Name: "kube-apiserver", Namespace: "kube-system"},
+		{Name: platform.KubeletChartName, Namespace: "kube-system"},
+		{Name: "calico", Namespace: "kube-system"},
+	}
+
+	rc := rollbackCharts(charts)
+
+	if len(rc) != 2 {
+		t.Fatalf("Expected 2 charts to roll back, got %v", rc)
+	}
+
+	for _, c := range rc {
+		if c.Name == platform.KubeletChartName {
+			t.Fatalf("Kubelet chart should not be rolled back, got %v", rc)
+		}
+	}
+}
@@ -0,0 +1,630 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/helm"
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	"github.com/kinvolk/lokomotive/pkg/lokomotive"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+const (
+	// kubernetesVersionChart is the controlplane chart, which app version determines
+	// the Kubernetes version of the cluster.
+	kubernetesVersionChart = "kube-apiserver"
+
+	// maxKubeletSkew is the maximum number of minor versions kubelet may be older than kube-apiserver.
+	//
+	// See https://kubernetes.io/releases/version-skew-policy/#kubelet.
+	maxKubeletSkew = 2
+
+	kubeletSelector              = "k8s-app=kubelet"
+	controllerNodeLabel          = "node.kubernetes.io/controller"
+	templateGenerationLabel      = "pod-template-generation"
+	templateGenerationAnnotation = "deprecated.daemonset.template.generation"
+
+	// upgradeCordonedAnnotation marks nodes cordoned by the upgrade, so they can be uncordoned
+	// when the interrupted upgrade is resumed, without touching nodes cordoned by the user.
+	upgradeCordonedAnnotation = "lokomotive.kinvolk.io/upgrade-cordoned"
+
+	// Number of seconds to wait between checks if the replaced kubelet pod is ready.
+	kubeletReadyRetryInterval = 5 * time.Second
+)
+
+// UpgradeOptions controls Upgrade() behavior.
+type UpgradeOptions struct {
+	Confirm         bool
+	UpgradeKubelets bool
+	ForceDrain      bool
+	Verbose         bool
+	ConfigPaths     []string
+	ValuesPath      string
+	// PrintPlan is called with the planned upgrade before asking for confirmation.
+	PrintPlan func(plan *UpgradePlan) error
+}
+
+// UpgradePlan describes changes, which Upgrade is going to make.
+type UpgradePlan struct {
+	// Infrastructure contains resources, which will be changed by Terraform before
+	// upgrading the charts.
+	Infrastructure []terraform.ResourceChange `json:"infrastructure"`
+	Charts         []ChartVersion             `json:"charts"`
+}
+
+// PrintTable prints infrastructure changes and deployed and target versions of
+// controlplane charts.
+func (p *UpgradePlan) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+
+	fmt.Fprintln(w, "\nInfrastructure changes:")
+
+	if len(p.Infrastructure) == 0 {
+		fmt.Fprintln(w, "\tNo changes.")
+	}
+
+	for _, rc := range p.Infrastructure {
+		fmt.Fprintf(w, "\t%s\t%s\n", strings.Join(rc.Actions, ", "), rc.Address)
+	}
+
+	fmt.Fprintln(w, "\nChart\tNamespace\tCurrent\tTarget\t")
+	fmt.Fprintln(w, "\t\t\t\t")
+
+	for _, v := range p.Charts {
+		current := v.Current
+		if current == "" {
+			current = "not installed"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", v.Name, v.Namespace, current, v.Target)
+	}
+
+	fmt.Fprintln(w)
+
+	return w.Flush()
+}
+
+// ChartVersion describes the deployed and the target version of a controlplane chart.
+type ChartVersion struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Current is the chart version of the latest release revision. Empty if not installed.
+	Current           string `json:"current"`
+	CurrentAppVersion string `json:"currentAppVersion"`
+	Target            string `json:"target"`
+	TargetAppVersion  string `json:"targetAppVersion"`
+}
+
+// UpToDate returns true if the deployed chart version is the target version.
+func (cv ChartVersion) UpToDate() bool {
+	return cv.Current == cv.Target
+}
+
+// Upgrade upgrades the controlplane of the cluster to the version shipped with lokoctl.
+//
+// Charts are upgraded one by one in the order defined by the platform. Workloads of each
+// upgraded chart are restarted and waited for. Kubelets are upgraded last, node by node,
+// draining each node before replacing the kubelet.
+//
+// Upgrade can be safely re-run when interrupted. Charts already at the target version
+// are not restarted again and only outdated kubelets are replaced.
+//
+//nolint:funlen
+func Upgrade(contextLogger *log.Entry, options UpgradeOptions) error {
+	cc := clusterConfig{
+		verbose:     options.Verbose,
+		configPaths: options.ConfigPaths,
+		valuesPath:  options.ValuesPath,
+	}
+
+	c, err := cc.initialize(contextLogger)
+	if err != nil {
+		return fmt.Errorf("initializing: %w", err)
+	}
+
+	if c.platform.Meta().Managed {
+		return errors.New("upgrading controlplane is not supported on managed platforms")
+	}
+
+	exists, err := clusterExists(c.terraformExecutor)
+	if err != nil {
+		return fmt.Errorf("checking if cluster exists: %w", err)
+	}
+
+	if !exists {
+		return errors.New("cannot upgrade a non-existent cluster, use 'lokoctl cluster apply' instead")
+	}
+
+	kg := kubeconfigGetter{
+		platformRequired: true,
+		clusterConfig:    cc,
+	}
+
+	kubeconfig, err := kg.getKubeconfig(contextLogger, c.lokomotiveConfig)
+	if err != nil {
+		return fmt.Errorf("getting kubeconfig: %w", err)
+	}
+
+	cs, err := k8sutil.NewClientset(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("creating Kubernetes clientset: %w", err)
+	}
+
+	versions, err := controlPlaneVersions(kubeconfig, c.platform.Meta().ControlplaneCharts)
+	if err != nil {
+		return fmt.Errorf("detecting controlplane versions: %w", err)
+	}
+
+	kubeletVersions, err := nodeKubeletVersions(cs)
+	if err != nil {
+		return fmt.Errorf("getting kubelet versions: %w", err)
+	}
+
+	if err := checkVersionSkew(versions, kubeletVersions); err != nil {
+		return fmt.Errorf("checking version skew: %w", err)
+	}
+
+	// Terraform generates the values for the new controlplane charts, so it is applied
+	// before upgrading them. Show the changes, so they are not applied unnoticed.
+	infrastructure, err := c.terraformExecutor.PlanChanges()
+	if err != nil {
+		return fmt.Errorf("planning infrastructure changes: %w", err)
+	}
+
+	if options.PrintPlan != nil {
+		if err := options.PrintPlan(&UpgradePlan{Infrastructure: infrastructure, Charts: versions}); err != nil {
+			return fmt.Errorf("printing upgrade plan: %w", err)
+		}
+	}
+
+	if !options.Confirm && !askForConfirmation("Do you want to proceed with cluster upgrade?") {
+		contextLogger.Println("Cluster upgrade cancelled")
+
+		return nil
+	}
+
+	if err := c.platform.Apply(&c.terraformExecutor); err != nil {
+		return fmt.Errorf("applying platform: %w", err)
+	}
+
+	if err := c.unpackControlplaneCharts(); err != nil {
+		return fmt.Errorf("unpacking controlplane assets: %w", err)
+	}
+
+	cu := controlplaneUpdater{
+		kubeconfig:    kubeconfig,
+		assetDir:      c.assetDir,
+		contextLogger: *contextLogger,
+		ex:            c.terraformExecutor,
+		installed:     map[string]bool{},
+	}
+
+	// Kubelets are replaced node by node, so rolling them back automatically could disrupt
+	// the workloads again. The kubelet upgrade is resumed by re-running the upgrade instead.
+	charts := rollbackCharts(c.platform.Meta().ControlplaneCharts)
+
+	if err := cu.upgradeWithRollback(charts, func() error {
+		return upgradeCharts(contextLogger, cu, cs, versions)
+	}); err != nil {
+		return err
+	}
+
+	if options.UpgradeKubelets && hasChart(versions, platform.KubeletChartName) {
+		if err := upgradeKubelets(contextLogger, cu, cs, options.ForceDrain); err != nil {
+			return fmt.Errorf("upgrading kubelets, re-run the upgrade to resume: %w", err)
+		}
+	}
+
+	return verifyCluster(kubeconfig, c.platform.Meta().ExpectedNodes)
+}
+
+// upgradeCharts upgrades given controlplane charts in order, rolling out their workloads.
+// The kubelet chart is skipped, as it is upgraded separately.
+func upgradeCharts(contextLogger *log.Entry, cu controlplaneUpdater, cs kubernetes.Interface, versions []ChartVersion) error { //nolint:lll
+	for _, v := range versions {
+		if v.Name == platform.KubeletChartName {
+			continue
+		}
+
+		if err := cu.upgradeComponent(v.Name, v.Namespace); err != nil {
+			return fmt.Errorf("upgrading controlplane component %q: %w", v.Name, err)
+		}
+
+		// Workloads are restarted even if Helm did not change their pod templates, so changes to
+		// Secrets and ConfigMaps are picked up as well. Charts which were already at the target
+		// version, e.g. when resuming the upgrade, are not restarted again.
+		if v.UpToDate() {
+			continue
+		}
+
+		if err := rolloutReleaseWorkloads(contextLogger, cs, cu.kubeconfig, v.Name, v.Namespace); err != nil {
+			return fmt.Errorf("rolling out workloads of controlplane component %q: %w", v.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func hasChart(versions []ChartVersion, name string) bool {
+	for _, v := range versions {
+		if v.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// controlPlaneVersions returns deployed and target versions of given controlplane charts,
+// in the same order. Deployed versions are read from the Helm history.
+func controlPlaneVersions(kubeconfig []byte, charts []helm.LokomotiveChart) ([]ChartVersion, error) {
+	versions := []ChartVersion{}
+
+	for _, c := range charts {
+		cv := ChartVersion{
+			Name:      c.Name,
+			Namespace: c.Namespace,
+		}
+
+		target, err := platform.ControlPlaneChart(c.Name)
+		if err != nil {
+			return nil, fmt.Errorf("loading chart %q from assets: %w", c.Name, err)
+		}
+
+		cv.Target = target.Metadata.Version
+		cv.TargetAppVersion = target.Metadata.AppVersion
+
+		actionConfig, err := util.HelmActionConfig(c.Namespace, kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("initializing Helm action: %w", err)
+		}
+
+		history, err := helm.GetHistory(action.NewHistory(actionConfig), c.Name, 1)
+		if err != nil && err != driver.ErrReleaseNotFound {
+			return nil, fmt.Errorf("getting history of release %q: %w", c.Name, err)
+		}
+
+		if len(history) > 0 && history[0].Chart != nil && history[0].Chart.Metadata != nil {
+			cv.Current = history[0].Chart.Metadata.Version
+			cv.CurrentAppVersion = history[0].Chart.Metadata.AppVersion
+		}
+
+		versions = append(versions, cv)
+	}
+
+	return versions, nil
+}
+
+// nodeKubeletVersions returns kubelet versions reported by the nodes, indexed by node name.
+func nodeKubeletVersions(cs kubernetes.Interface) (map[string]string, error) {
+	nodes, err := cs.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	versions := map[string]string{}
+
+	for _, n := range nodes.Items {
+		versions[n.Name] = n.Status.NodeInfo.KubeletVersion
+	}
+
+	return versions, nil
+}
+
+// checkVersionSkew verifies that the upgrade from the deployed Kubernetes version to the target
+// version is supported. Kubernetes can only be upgraded one minor version at a time and kubelets
+// must not be too old for the target kube-apiserver version.
+func checkVersionSkew(versions []ChartVersion, kubeletVersions map[string]string) error {
+	var cv *ChartVersion
+
+	for i := range versions {
+		if versions[i].Name == kubernetesVersionChart {
+			cv = &versions[i]
+		}
+	}
+
+	if cv == nil || cv.CurrentAppVersion == "" {
+		return fmt.Errorf("could not determine deployed Kubernetes version from release %q", kubernetesVersionChart)
+	}
+
+	current, err := version.NewVersion(cv.CurrentAppVersion)
+	if err != nil {
+		return fmt.Errorf("parsing deployed Kubernetes version %q: %w", cv.CurrentAppVersion, err)
+	}
+
+	target, err := version.NewVersion(cv.TargetAppVersion)
+	if err != nil {
+		return fmt.Errorf("parsing target Kubernetes version %q: %w", cv.TargetAppVersion, err)
+	}
+
+	if target.LessThan(current) {
+		return fmt.Errorf("downgrading Kubernetes from %s to %s is not supported", current, target)
+	}
+
+	cs, ts := current.Segments(), target.Segments()
+
+	if cs[0] != ts[0] || ts[1]-cs[1] > 1 {
+		return fmt.Errorf("upgrading Kubernetes from %s to %s skips minor versions, upgrade one minor "+
+			"version at a time using intermediate lokoctl releases", current, target)
+	}
+
+	nodes := []string{}
+	for n := range kubeletVersions {
+		nodes = append(nodes, n)
+	}
+
+	sort.Strings(nodes)
+
+	for _, n := range nodes {
+		kv, err := version.NewVersion(kubeletVersions[n])
+		if err != nil {
+			return fmt.Errorf("parsing kubelet version %q of node %q: %w", kubeletVersions[n], n, err)
+		}
+
+		if ks := kv.Segments(); ks[0] != ts[0] || ts[1]-ks[1] > maxKubeletSkew {
+			return fmt.Errorf("kubelet version %s on node %q is not supported by Kubernetes %s, "+
+				"upgrade kubelets first", kv, n, target)
+		}
+	}
+
+	return nil
+}
+
+// rolloutReleaseWorkloads restarts all Deployments and DaemonSets of given release
+// and waits until they are fully rolled out.
+func rolloutReleaseWorkloads(contextLogger *log.Entry, cs kubernetes.Interface, kubeconfig []byte, name, namespace string) error { //nolint:lll
+	actionConfig, err := util.HelmActionConfig(namespace, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("initializing Helm action: %w", err)
+	}
+
+	manifest, _, err := util.ReleaseManifest(actionConfig, name)
+	if err != nil {
+		return fmt.Errorf("getting release manifest: %w", err)
+	}
+
+	objects, err := k8sutil.LoadManifests(map[string]string{"manifest": manifest})
+	if err != nil {
+		return fmt.Errorf("parsing release manifest: %w", err)
+	}
+
+	ctx := context.TODO()
+
+	for _, o := range objects {
+		ns := o.Namespace()
+		if ns == "" {
+			ns = namespace
+		}
+
+		switch o.Kind() {
+		case lokomotive.KindDeployment:
+			contextLogger.Infof("Rolling out Deployment %s/%s", ns, o.Name())
+
+			if err := k8sutil.RolloutDeployment(ctx, cs.AppsV1().Deployments(ns), o.Name()); err != nil {
+				return fmt.Errorf("rolling out Deployment %s/%s: %w", ns, o.Name(), err)
+			}
+		case lokomotive.KindDaemonSet:
+			contextLogger.Infof("Rolling out DaemonSet %s/%s", ns, o.Name())
+
+			if err := k8sutil.RolloutDaemonSet(ctx, cs.AppsV1().DaemonSets(ns), o.Name()); err != nil {
+				return fmt.Errorf("rolling out DaemonSet %s/%s: %w", ns, o.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// upgradeKubelets upgrades the kubelet chart with OnDelete update strategy and then replaces
+// kubelet pods node by node, draining each node first. At the end, the default update
+// strategy is restored.
+func upgradeKubelets(contextLogger *log.Entry, cu controlplaneUpdater, cs kubernetes.Interface, forceDrain bool) error {
+	name := platform.KubeletChartName
+	namespace := "kube-system"
+
+	if err := cu.upgradeComponentWithValues(name, namespace, map[string]interface{}{
+		"updateStrategy": "OnDelete",
+	}); err != nil {
+		return fmt.Errorf("upgrading kubelet chart: %w", err)
+	}
+
+	if err := rollKubelets(contextLogger, cs, namespace, forceDrain); err != nil {
+		return err
+	}
+
+	if err := cu.upgradeComponent(name, namespace); err != nil {
+		return fmt.Errorf("restoring kubelet update strategy: %w", err)
+	}
+
+	return nil
+}
+
+// outdatedKubeletPods returns kubelet pods, which do not run the given DaemonSet template
+// generation. Pods running on controller nodes are returned first.
+func outdatedKubeletPods(pods []corev1.Pod, generation string, controllers map[string]bool) []corev1.Pod {
+	outdated := []corev1.Pod{}
+
+	for _, p := range pods {
+		if p.Labels[templateGenerationLabel] != generation {
+			outdated = append(outdated, p)
+		}
+	}
+
+	sort.SliceStable(outdated, func(i, j int) bool {
+		a, b := outdated[i].Spec.NodeName, outdated[j].Spec.NodeName
+
+		if controllers[a] != controllers[b] {
+			return controllers[a]
+		}
+
+		return a < b
+	})
+
+	return outdated
+}
+
+//nolint:funlen
+func rollKubelets(contextLogger *log.Entry, cs kubernetes.Interface, namespace string, forceDrain bool) error {
+	ctx := context.TODO()
+
+	ds, err := cs.AppsV1().DaemonSets(namespace).Get(ctx, platform.KubeletChartName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting kubelet DaemonSet: %w", err)
+	}
+
+	generation := ds.Annotations[templateGenerationAnnotation]
+
+	pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: kubeletSelector})
+	if err != nil {
+		return fmt.Errorf("listing kubelet pods: %w", err)
+	}
+
+	nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+
+	controllers := map[string]bool{}
+	userCordoned := map[string]bool{}
+
+	for _, n := range nodes.Items {
+		controllers[n.Name] = n.Labels[controllerNodeLabel] == "true"
+		userCordoned[n.Name] = n.Spec.Unschedulable && n.Annotations[upgradeCordonedAnnotation] == ""
+
+		// Uncordon nodes left cordoned by an interrupted upgrade. Nodes with outdated
+		// kubelet will be cordoned again when they are processed.
+		if n.Annotations[upgradeCordonedAnnotation] != "" {
+			if err := uncordonUpgradedNode(ctx, cs, n.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	outdated := outdatedKubeletPods(pods.Items, generation, controllers)
+
+	contextLogger.Infof("Upgrading kubelet on %d node(s)", len(outdated))
+
+	for _, p := range outdated {
+		node := p.Spec.NodeName
+
+		contextLogger.Infof("Upgrading kubelet on node %q", node)
+
+		if !userCordoned[node] {
+			if err := annotateNode(ctx, cs, node, "true"); err != nil {
+				return err
+			}
+		}
+
+		drainOptions := k8sutil.DrainOptions{
+			Force: forceDrain,
+			Out:   contextLogger.WriterLevel(log.DebugLevel),
+		}
+
+		if err := k8sutil.DrainNode(ctx, cs, node, drainOptions); err != nil {
+			return err
+		}
+
+		if err := cs.CoreV1().Pods(namespace).Delete(ctx, p.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("deleting kubelet pod %q: %w", p.Name, err)
+		}
+
+		if err := waitForKubelet(ctx, cs, namespace, node, generation); err != nil {
+			return fmt.Errorf("waiting for kubelet on node %q: %w", node, err)
+		}
+
+		if !userCordoned[node] {
+			if err := uncordonUpgradedNode(ctx, cs, node); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// waitForKubelet waits until kubelet pod with given template generation is ready on the given node.
+func waitForKubelet(ctx context.Context, cs kubernetes.Interface, namespace, node, generation string) error {
+	return wait.PollImmediate(kubeletReadyRetryInterval, k8sutil.RolloutTimeout, func() (bool, error) {
+		pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: kubeletSelector,
+			FieldSelector: "spec.nodeName=" + node,
+		})
+		if err != nil {
+			// Errors are expected while kubelet is being replaced, so keep retrying.
+			return false, nil
+		}
+
+		for _, p := range pods.Items {
+			if p.Labels[templateGenerationLabel] == generation && p.DeletionTimestamp == nil && podReady(p) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+}
+
+func podReady(p corev1.Pod) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// annotateNode sets or, if value is empty, removes the upgrade annotation on given node.
+func annotateNode(ctx context.Context, cs kubernetes.Interface, node, value string) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, upgradeCordonedAnnotation, value)
+	if value == "" {
+		patch = fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, upgradeCordonedAnnotation)
+	}
+
+	if _, err := cs.CoreV1().Nodes().Patch(ctx, node, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("annotating node %q: %w", node, err)
+	}
+
+	return nil
+}
+
+func uncordonUpgradedNode(ctx context.Context, cs kubernetes.Interface, node string) error {
+	if err := k8sutil.CordonNode(ctx, cs, node, false); err != nil {
+		return fmt.Errorf("uncordoning: %w", err)
+	}
+
+	return annotateNode(ctx, cs, node, "")
+}
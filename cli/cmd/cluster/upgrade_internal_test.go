@@ -0,0 +1,131 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func versions(current, target string) []ChartVersion {
+	return []ChartVersion{
+		{
+			Name:              kubernetesVersionChart,
+			CurrentAppVersion: current,
+			TargetAppVersion:  target,
+		},
+	}
+}
+
+func TestCheckVersionSkew(t *testing.T) {
+	cases := map[string]struct {
+		versions        []ChartVersion
+		kubeletVersions map[string]string
+		expectError     bool
+	}{
+		"patch_upgrade": {
+			versions:        versions("v1.21.2", "v1.21.4"),
+			kubeletVersions: map[string]string{"foo": "v1.21.2"},
+		},
+		"minor_upgrade": {
+			versions:        versions("v1.20.4", "v1.21.4"),
+			kubeletVersions: map[string]string{"foo": "v1.20.4"},
+		},
+		"same_version": {
+			versions: versions("v1.21.4", "v1.21.4"),
+		},
+		"old_kubelets_within_skew": {
+			versions:        versions("v1.21.4", "v1.21.4"),
+			kubeletVersions: map[string]string{"foo": "v1.19.1"},
+		},
+		"downgrade": {
+			versions:    versions("v1.21.4", "v1.20.4"),
+			expectError: true,
+		},
+		"skipping_minor_version": {
+			versions:    versions("v1.19.4", "v1.21.4"),
+			expectError: true,
+		},
+		"major_upgrade": {
+			versions:    versions("v1.21.4", "v2.0.0"),
+			expectError: true,
+		},
+		"kubelet_too_old": {
+			versions:        versions("v1.20.4", "v1.21.4"),
+			kubeletVersions: map[string]string{"foo": "v1.21.4", "bar": "v1.18.1"},
+			expectError:     true,
+		},
+		"unknown_current_version": {
+			versions:    versions("", "v1.21.4"),
+			expectError: true,
+		},
+		"missing_kubernetes_chart": {
+			versions:    []ChartVersion{{Name: "calico"}},
+			expectError: true,
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			err := checkVersionSkew(c.versions, c.kubeletVersions)
+			if c.expectError && err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if !c.expectError && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func kubeletPod(name, node, generation string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{templateGenerationLabel: generation},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+		},
+	}
+}
+
+func TestOutdatedKubeletPods(t *testing.T) {
+	pods := []corev1.Pod{
+		kubeletPod("kubelet-a", "worker-1", "1"),
+		kubeletPod("kubelet-b", "worker-0", "1"),
+		kubeletPod("kubelet-c", "controller-0", "1"),
+		kubeletPod("kubelet-d", "worker-2", "2"),
+	}
+
+	controllers := map[string]bool{"controller-0": true}
+
+	outdated := outdatedKubeletPods(pods, "2", controllers)
+
+	got := []string{}
+	for _, p := range outdated {
+		got = append(got, p.Name)
+	}
+
+	if diff := cmp.Diff([]string{"kubelet-c", "kubelet-b", "kubelet-a"}, got); diff != "" {
+		t.Fatalf("Unexpected outdated pods (-want +got)\n%s", diff)
+	}
+}
@@ -24,14 +24,22 @@ import (
 	"github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/kinvolk/lokomotive/pkg/backend/azurerm"
+	"github.com/kinvolk/lokomotive/pkg/backend/consul"
+	"github.com/kinvolk/lokomotive/pkg/backend/gcs"
 	"github.com/kinvolk/lokomotive/pkg/backend/local"
+	"github.com/kinvolk/lokomotive/pkg/backend/pg"
+	"github.com/kinvolk/lokomotive/pkg/backend/remote"
 	"github.com/kinvolk/lokomotive/pkg/backend/s3"
 	"github.com/kinvolk/lokomotive/pkg/config"
 	"github.com/kinvolk/lokomotive/pkg/platform"
 	"github.com/kinvolk/lokomotive/pkg/platform/aks"
 	"github.com/kinvolk/lokomotive/pkg/platform/aws"
+	"github.com/kinvolk/lokomotive/pkg/platform/azure"
 	"github.com/kinvolk/lokomotive/pkg/platform/baremetal"
 	"github.com/kinvolk/lokomotive/pkg/platform/equinixmetal"
+	"github.com/kinvolk/lokomotive/pkg/platform/gcp"
+	"github.com/kinvolk/lokomotive/pkg/platform/kvmlibvirt"
 	"github.com/kinvolk/lokomotive/pkg/platform/tinkerbell"
 )
 
@@ -51,6 +59,12 @@ type backend interface {
 	Validate() error
 }
 
+// envBackend is implemented by backends, which pass sensitive configuration to Terraform
+// using environment variables instead of rendering it.
+type envBackend interface {
+	Env() map[string]string
+}
+
 // getConfiguredBackend loads a backend from the given configuration file.
 func getConfiguredBackend(lokoConfig *config.Config) (backend, hcl.Diagnostics) {
 	if lokoConfig.RootConfig.Backend == nil {
@@ -59,8 +73,13 @@ func getConfiguredBackend(lokoConfig *config.Config) (backend, hcl.Diagnostics)
 	}
 
 	backends := map[string]backend{
-		s3.Name:    s3.NewConfig(),
-		local.Name: local.NewConfig(),
+		s3.Name:      s3.NewConfig(),
+		local.Name:   local.NewConfig(),
+		azurerm.Name: azurerm.NewConfig(),
+		gcs.Name:     gcs.NewConfig(),
+		consul.Name:  consul.NewConfig(),
+		pg.Name:      pg.NewConfig(),
+		remote.Name:  remote.NewConfig(),
 	}
 
 	backend, ok := backends[lokoConfig.RootConfig.Backend.Name]
@@ -79,7 +98,10 @@ func getPlatform(name string) (platform.Platform, error) {
 	platforms := map[string]platform.Platform{
 		aks.Name:          aks.NewConfig(),
 		aws.Name:          aws.NewConfig(),
+		azure.Name:        azure.NewConfig(),
 		equinixmetal.Name: equinixmetal.NewConfig(),
+		gcp.Name:          gcp.NewConfig(),
+		kvmlibvirt.Name:   kvmlibvirt.NewConfig(),
 		baremetal.Name:    baremetal.NewConfig(),
 		tinkerbell.Name:   tinkerbell.NewConfig(),
 	}
@@ -74,7 +74,7 @@ func prepareKubeconfigSource(t *testing.T, k *kubeconfigSources) (*log.Entry, *c
 		}
 	}
 
-	lokoConfig, diags := config.LoadConfig(tmpDir, "")
+	lokoConfig, diags := config.LoadConfig([]string{tmpDir}, "")
 	if diags.HasErrors() {
 		t.Fatalf("getting lokomotive configuration: %v", err)
 	}
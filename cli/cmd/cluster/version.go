@@ -15,6 +15,9 @@
 package cluster
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/kinvolk/lokomotive/pkg/version"
 )
 
@@ -22,3 +25,15 @@ import (
 func Version() string {
 	return version.Version
 }
+
+// VersionInfo describes the version of lokoctl.
+type VersionInfo struct {
+	Version string `json:"version"`
+}
+
+// PrintTable prints the version.
+func (v VersionInfo) PrintTable(out io.Writer) error {
+	_, err := fmt.Fprintln(out, v.Version)
+
+	return err
+}
@@ -27,7 +27,9 @@ var componentApplyCmd = &cobra.Command{
 	Short: "Deploy or update a component",
 	Long: `Deploy or update a component.
 Deploys a component if not yet present, otherwise updates it.
-When run with no arguments, all components listed in the configuration are applied.`,
+When run with no arguments, all components listed in the configuration are applied.
+Components are applied in the order of their dependencies. Components which do not
+depend on each other are applied concurrently.`,
 	Run: runApply,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 0 {
@@ -60,7 +62,7 @@ func runApply(cmd *cobra.Command, args []string) {
 
 	options := cluster.ComponentApplyOptions{
 		KubeconfigPath: kubeconfigFlag,
-		ConfigPath:     viper.GetString("lokocfg"),
+		ConfigPaths:    lokocfgPaths(),
 		ValuesPath:     viper.GetString("lokocfg-vars"),
 	}
 
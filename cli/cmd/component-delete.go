@@ -26,7 +26,8 @@ var componentDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete an installed component",
 	Long: `Delete a component.
-When run with no arguments, all components listed in the configuration are deleted.`,
+When run with no arguments, all components listed in the configuration are deleted.
+Components which other installed components depend on can't be deleted.`,
 	Run: runDelete,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 0 {
@@ -63,7 +64,7 @@ func runDelete(cmd *cobra.Command, args []string) {
 		Confirm:         confirm,
 		DeleteNamespace: deleteNamespace,
 		KubeconfigPath:  kubeconfigFlag,
-		ConfigPath:      viper.GetString("lokocfg"),
+		ConfigPaths:     lokocfgPaths(),
 		ValuesPath:      viper.GetString("lokocfg-vars"),
 	}
 
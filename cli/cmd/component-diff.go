@@ -0,0 +1,81 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+)
+
+// diffChangesExitCode is returned when differences are found. It differs from the exit code
+// used for errors, so CI systems can distinguish pending changes from failures.
+const diffChangesExitCode = 2
+
+var componentDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show differences between configured and deployed components",
+	Long: `Show differences between configured and deployed components.
+Renders the components and compares each object with the deployed Helm release.
+Objects which would be removed are included as well.
+When run with no arguments, all components listed in the configuration are compared.
+
+Exits with status 2 if any differences are found and with status 1 if comparing fails.`,
+	Run: runDiff,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cluster.AvailableComponents(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	componentCmd.AddCommand(componentDiffCmd)
+	pf := componentDiffCmd.PersistentFlags()
+	addKubeconfigFileFlag(pf)
+	pf.BoolVarP(&debug, "debug", "", false, "Print debug messages")
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl component diff",
+		"args":    args,
+	})
+
+	if debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	options := cluster.ComponentDiffOptions{
+		KubeconfigPath: kubeconfigFlag,
+		ConfigPaths:    lokocfgPaths(),
+		ValuesPath:     viper.GetString("lokocfg-vars"),
+	}
+
+	releases, err := cluster.ComponentDiff(contextLogger, args, options)
+	if err != nil {
+		contextLogger.Fatalf("Comparing components failed: %v", err)
+	}
+
+	if err := printResult(releases); err != nil {
+		contextLogger.Fatalf("Printing differences failed: %v", err)
+	}
+
+	if releases.HasChanges() {
+		// Exit via logger, so redacted output is flushed.
+		log.Exit(diffChangesExitCode)
+	}
+}
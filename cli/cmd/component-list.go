@@ -15,9 +15,6 @@
 package cmd
 
 import (
-	"fmt"
-	"sort"
-
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -43,11 +40,7 @@ func runList(cmd *cobra.Command, args []string) {
 		contextLogger.Fatalf("Unknown argument provided for list")
 	}
 
-	fmt.Println("Available components:")
-
-	comps := cluster.AvailableComponents()
-	sort.Strings(comps)
-	for _, name := range comps {
-		fmt.Println("\t", name)
+	if err := printResult(cluster.ListComponents()); err != nil {
+		contextLogger.Fatalf("Printing components failed: %v", err)
 	}
 }
@@ -39,11 +39,16 @@ func runComponentRender(cmd *cobra.Command, args []string) {
 	})
 
 	options := cluster.ComponentRenderManifestOptions{
-		ConfigPath: viper.GetString("lokocfg"),
-		ValuesPath: viper.GetString("lokocfg-vars"),
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
 	}
 
-	if err := cluster.ComponentRenderManifest(contextLogger, args, options); err != nil {
+	manifests, err := cluster.ComponentRenderManifest(contextLogger, args, options)
+	if err != nil {
 		contextLogger.Fatalf("Rendering component manifests failed: %v", err)
 	}
+
+	if err := printResult(manifests); err != nil {
+		contextLogger.Fatalf("Printing component manifests failed: %v", err)
+	}
 }
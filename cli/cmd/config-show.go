@@ -0,0 +1,57 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
+)
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the merged configuration",
+	Long: `Print the configuration merged from all paths given with --lokocfg flag.
+
+Configuration from each path overrides attributes and blocks defined in
+previous paths. Every attribute is annotated with the file it has been
+taken from. Attribute values are printed as written in the configuration,
+without evaluating variables.`,
+	Run: runConfigShow,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) {
+	contextLogger := log.WithFields(log.Fields{
+		"command": "lokoctl config show",
+		"args":    args,
+	})
+
+	options := cluster.ConfigShowOptions{
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
+	}
+
+	if err := cluster.ConfigShow(contextLogger, os.Stdout, options); err != nil {
+		contextLogger.Fatalf("Showing configuration failed: %v", err)
+	}
+}
@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -22,10 +24,18 @@ import (
 	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
 )
 
+var healthWait time.Duration
+
 var healthCmd = &cobra.Command{
 	Use:   "health",
 	Short: "Get the health of a cluster",
-	Run:   runHealth,
+	Long: `Get the health of a cluster.
+Checks readiness of the nodes, health of etcd, status of the controlplane and component Helm releases
+and readiness of their Deployments, DaemonSets and StatefulSets.
+Etcd health is checked through the API server. Etcd members are additionally probed directly,
+if they are reachable from this machine.
+Use --wait to wait for the cluster to become healthy.`,
+	Run: runHealth,
 }
 
 //nolint:gochecknoinits
@@ -33,6 +43,7 @@ func init() {
 	RootCmd.AddCommand(healthCmd)
 	pf := healthCmd.PersistentFlags()
 	pf.BoolVarP(&debug, "debug", "", false, "Print debug messages")
+	pf.DurationVarP(&healthWait, "wait", "", 0, "Wait up to given duration for the cluster to become healthy")
 }
 
 func runHealth(cmd *cobra.Command, args []string) {
@@ -46,11 +57,21 @@ func runHealth(cmd *cobra.Command, args []string) {
 	}
 
 	options := cluster.HealthOptions{
-		ConfigPath: viper.GetString("lokocfg"),
-		ValuesPath: viper.GetString("lokocfg-vars"),
+		ConfigPaths: lokocfgPaths(),
+		ValuesPath:  viper.GetString("lokocfg-vars"),
+		Wait:        healthWait,
 	}
 
-	if err := cluster.Health(contextLogger, options); err != nil {
+	result, err := cluster.Health(contextLogger, options)
+	if err != nil {
 		contextLogger.Fatalf("Checking cluster health failed: %v", err)
 	}
+
+	if err := printResult(result); err != nil {
+		contextLogger.Fatalf("Printing cluster health failed: %v", err)
+	}
+
+	if !result.Healthy {
+		contextLogger.Fatalf("Checking cluster health failed: cluster is not completely ready")
+	}
 }
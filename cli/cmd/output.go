@@ -0,0 +1,87 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+	outputFormatYAML  = "yaml"
+)
+
+//nolint:gochecknoglobals
+var outputFormats = []string{outputFormatTable, outputFormatJSON, outputFormatYAML}
+
+// tablePrinter is implemented by command results, which can be printed in a human-readable form.
+// All other output formats are produced by marshaling the result, so the JSON field names of the
+// result types define the schema of the machine-readable output.
+type tablePrinter interface {
+	PrintTable(w io.Writer) error
+}
+
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if format == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported output format %q, expected one of: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// printResult prints given command result to standard output in the format selected
+// with the --output flag.
+func printResult(result tablePrinter) error {
+	return writeResult(os.Stdout, viper.GetString("output"), result)
+}
+
+func writeResult(w io.Writer, format string, result tablePrinter) error {
+	var (
+		output []byte
+		err    error
+	)
+
+	switch format {
+	case outputFormatTable:
+		return result.PrintTable(w)
+	case outputFormatJSON:
+		output, err = json.MarshalIndent(result, "", "  ")
+		output = append(output, '\n')
+	case outputFormatYAML:
+		output, err = yaml.Marshal(result)
+	default:
+		return validateOutputFormat(format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshaling result to %s: %w", format, err)
+	}
+
+	if _, err := w.Write(output); err != nil {
+		return fmt.Errorf("writing result: %w", err)
+	}
+
+	return nil
+}
@@ -16,26 +16,60 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/kinvolk/lokomotive/internal/redact"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "lokoctl",
 	Short: "Manage Lokomotive clusters",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat(viper.GetString("output"))
+	},
 }
 
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	// Hide values of sensitive configuration variables in all command output, including
+	// Terraform output and printed results.
+	restoreStdout, err := redact.Redirect(&os.Stdout)
+	if err != nil {
+		log.Fatalf("Redirecting output failed: %v", err)
+	}
+
+	// Make sure all output is printed when exiting on fatal errors.
+	log.RegisterExitHandler(restoreStdout)
+
+	err = RootCmd.Execute()
+
+	restoreStdout()
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 var kubeconfigFlag string
 
+var lokocfgFlag []string
+
+// lokocfgPaths returns configuration paths in the order given with --lokocfg flags. If the flag
+// is not given, a single path can be set using LOKOCFG environment variable.
+func lokocfgPaths() []string {
+	if !RootCmd.PersistentFlags().Changed("lokocfg") && viper.IsSet("lokocfg") {
+		return []string{viper.GetString("lokocfg")}
+	}
+
+	return lokocfgFlag
+}
+
 func addKubeconfigFileFlag(pf *flag.FlagSet) {
 	pf.StringVar(
 		&kubeconfigFlag,
@@ -56,10 +90,18 @@ func init() { //nolint:gochecknoinits
 
 	RootCmd.DisableAutoGenTag = true
 
-	RootCmd.PersistentFlags().String("lokocfg", "./", "Path to lokocfg directory or file")
-	viper.BindPFlag("lokocfg", RootCmd.PersistentFlags().Lookup("lokocfg"))
+	// Hide values of sensitive configuration variables in all log output.
+	log.AddHook(redact.Hook{})
+
+	// String array is used, so paths containing commas are not split.
+	RootCmd.PersistentFlags().StringArrayVar(&lokocfgFlag, "lokocfg", []string{"./"},
+		"Path to lokocfg directory or file. Can be specified multiple times to merge configurations, "+
+			"with later paths taking precedence")
 	RootCmd.PersistentFlags().String("lokocfg-vars", "./lokocfg.vars", "Path to lokocfg.vars file")
 	viper.BindPFlag("lokocfg-vars", RootCmd.PersistentFlags().Lookup("lokocfg-vars"))
+	RootCmd.PersistentFlags().StringP("output", "o", outputFormatTable,
+		fmt.Sprintf("Output format of command results. One of: %s", strings.Join(outputFormats, ", ")))
+	viper.BindPFlag("output", RootCmd.PersistentFlags().Lookup("output"))
 }
 
 func cobraInit() {
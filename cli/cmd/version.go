@@ -15,8 +15,7 @@
 package cmd
 
 import (
-	"fmt"
-
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/kinvolk/lokomotive/cli/cmd/cluster"
@@ -30,6 +29,8 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(cluster.Version())
+		if err := printResult(cluster.VersionInfo{Version: cluster.Version()}); err != nil {
+			log.Fatalf("Printing version failed: %v", err)
+		}
 	},
 }
@@ -3,9 +3,13 @@ module github.com/kinvolk/lokomotive
 go 1.15
 
 require (
+	filippo.io/age v1.0.0-rc.1
+	github.com/apparentlymart/go-cidr v1.1.0
+	github.com/aws/aws-sdk-go v1.27.0
 	github.com/elazarl/goproxy/ext v0.0.0-20210801061803-8e322dfb79c4 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
+	github.com/golang/protobuf v1.4.3
 	github.com/google/go-cmp v0.5.2
 	github.com/hashicorp/go-version v1.2.0
 	github.com/hashicorp/hcl/v2 v2.7.2
@@ -14,6 +18,7 @@ require (
 	github.com/linkerd/linkerd2 v0.5.1-0.20210517230931-5535e9c4edda
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/packethost/packngo v0.2.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/alertmanager v0.20.0
 	github.com/prometheus/client_golang v1.7.1
 	github.com/shirou/gopsutil/v3 v3.21.7
@@ -23,11 +28,17 @@ require (
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.0
+	github.com/tinkerbell/tink v0.0.0-20201109122352-0e8e57332303
 	github.com/zclconf/go-cty v1.7.0
+	github.com/zclconf/go-cty-yaml v1.0.2
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
+	google.golang.org/grpc v1.32.0
+	google.golang.org/protobuf v1.25.0
 	helm.sh/helm/v3 v3.5.1
 	k8s.io/api v0.20.2
 	k8s.io/apimachinery v0.20.2
 	k8s.io/client-go v0.20.2
+	k8s.io/kubectl v0.20.1
 	sigs.k8s.io/yaml v1.2.0
 )
 
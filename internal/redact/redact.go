@@ -0,0 +1,217 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact hides values of sensitive configuration variables in lokoctl
+// output.
+package redact
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Placeholder replaces sensitive values in the output.
+const Placeholder = "(sensitive value)"
+
+// copyBufferSize is the size of chunks in which redirected output is redacted.
+const copyBufferSize = 32 * 1024
+
+var (
+	mu      sync.RWMutex
+	values  = map[string]struct{}{}
+	pattern *regexp.Regexp
+)
+
+// Add registers sensitive values, which should be redacted. Empty values are ignored.
+//
+// Values are only redacted as whole tokens, so short values like numbers do not garble
+// unrelated words containing them.
+func Add(sensitive ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, v := range sensitive {
+		if v != "" {
+			values[v] = struct{}{}
+		}
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	sorted := make([]string, 0, len(values))
+	for v := range values {
+		sorted = append(sorted, v)
+	}
+
+	// Match longer values first, so values containing other values are fully redacted.
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+
+		return sorted[i] < sorted[j]
+	})
+
+	alternatives := make([]string, 0, len(sorted))
+
+	for _, v := range sorted {
+		alternatives = append(alternatives, tokenPattern(v))
+	}
+
+	pattern = regexp.MustCompile(strings.Join(alternatives, "|"))
+}
+
+// tokenPattern returns regular expression matching given value only if it is not a part of
+// a longer word.
+func tokenPattern(v string) string {
+	p := regexp.QuoteMeta(v)
+
+	if isWordChar(v[0]) {
+		p = `\b` + p
+	}
+
+	if isWordChar(v[len(v)-1]) {
+		p += `\b`
+	}
+
+	return p
+}
+
+// isWordChar returns true for characters forming words as understood by \b in regular expressions.
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// String returns given string with all registered sensitive values replaced by Placeholder.
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if pattern == nil {
+		return s
+	}
+
+	return pattern.ReplaceAllLiteralString(s, Placeholder)
+}
+
+// Hook is a logrus hook redacting sensitive values from messages and fields of log entries.
+type Hook struct{}
+
+// Levels implements logrus.Hook interface.
+func (Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements logrus.Hook interface.
+func (Hook) Fire(e *log.Entry) error {
+	e.Message = String(e.Message)
+
+	for k, v := range e.Data {
+		switch value := v.(type) {
+		case string:
+			e.Data[k] = String(value)
+		case error:
+			e.Data[k] = String(value.Error())
+		default:
+			// Sensitive values may also be numbers or booleans.
+			if s := fmt.Sprint(value); String(s) != s {
+				e.Data[k] = String(s)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Redirect replaces given file, e.g. os.Stdout, with a pipe. All data written to the pipe
+// is copied to the original file with sensitive values redacted. Returned function restores
+// the original file once all data written so far is copied. It is safe to call it more than once.
+func Redirect(f **os.File) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating pipe: %w", err)
+	}
+
+	original := *f
+	*f = w
+
+	done := make(chan struct{})
+
+	go func() {
+		if err := copyRedacted(original, r); err != nil {
+			log.Errorf("Copying redacted output failed: %v", err)
+		}
+
+		close(done)
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			*f = original
+
+			w.Close() //nolint:errcheck
+
+			<-done
+
+			r.Close() //nolint:errcheck
+		})
+	}, nil
+}
+
+// copyRedacted copies data from src to dst with sensitive values redacted. Data is redacted
+// in chunks as returned by the reader. When a chunk fills the whole buffer, more data is
+// likely pending, so its last incomplete line is kept until the next read to not split
+// sensitive values between chunks.
+func copyRedacted(dst io.Writer, src io.Reader) error {
+	buf := make([]byte, copyBufferSize)
+	pending := ""
+
+	for {
+		n, err := src.Read(buf)
+
+		chunk := pending + string(buf[:n])
+		pending = ""
+
+		if n == len(buf) {
+			if i := strings.LastIndexByte(chunk, '\n'); i >= 0 {
+				chunk, pending = chunk[:i+1], chunk[i+1:]
+			}
+		}
+
+		if chunk != "" {
+			if _, writeErr := io.WriteString(dst, String(chunk)); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
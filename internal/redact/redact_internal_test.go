@@ -0,0 +1,40 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCopyRedactedValueSplitBetweenChunks(t *testing.T) {
+	Add("chunked-s3cr3t")
+
+	// Place the sensitive value across the chunk boundary.
+	input := strings.Repeat("x", copyBufferSize-20) + "\ntoken: chunked-s3cr3t\n"
+
+	var out bytes.Buffer
+
+	if err := copyRedacted(&out, strings.NewReader(input)); err != nil {
+		t.Fatalf("Copying: %v", err)
+	}
+
+	expected := strings.Repeat("x", copyBufferSize-20) + "\ntoken: " + Placeholder + "\n"
+
+	if out.String() != expected {
+		t.Fatalf("Sensitive value should be redacted, got: %q", out.String()[copyBufferSize-20:])
+	}
+}
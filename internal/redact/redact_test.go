@@ -0,0 +1,128 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kinvolk/lokomotive/internal/redact"
+)
+
+func TestHookRedactsMessageAndFields(t *testing.T) {
+	redact.Add("s3cr3t", "s3cr3t-longer", "")
+
+	var buf bytes.Buffer
+
+	logger := log.New()
+	logger.SetOutput(&buf)
+	logger.AddHook(redact.Hook{})
+
+	logger.WithFields(log.Fields{
+		"token": "s3cr3t-longer",
+	}).WithError(errors.New("invalid s3cr3t")).Error("using s3cr3t")
+
+	out := buf.String()
+
+	if strings.Contains(out, "s3cr3t") {
+		t.Fatalf("Sensitive value should be redacted, got: %s", out)
+	}
+
+	if c := strings.Count(out, redact.Placeholder); c != 3 {
+		t.Fatalf("Expected 3 redacted values, got %d: %s", c, out)
+	}
+}
+
+func TestStringRedactsWholeTokens(t *testing.T) {
+	redact.Add("4242")
+
+	got := redact.String("port: 4242, id: 142420")
+	expected := "port: " + redact.Placeholder + ", id: 142420"
+
+	if got != expected {
+		t.Fatalf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestHookRedactsNonStringFields(t *testing.T) {
+	redact.Add("31337")
+
+	var buf bytes.Buffer
+
+	logger := log.New()
+	logger.SetOutput(&buf)
+	logger.AddHook(redact.Hook{})
+
+	logger.WithField("port", 31337).Info("connecting")
+
+	if out := buf.String(); strings.Contains(out, "31337") {
+		t.Fatalf("Sensitive value should be redacted, got: %s", out)
+	}
+}
+
+func TestRedirectRedactsOutput(t *testing.T) {
+	redact.Add("redirected-s3cr3t")
+
+	f, err := ioutil.TempFile("", "lokomotive-redact")
+	if err != nil {
+		t.Fatalf("Creating temporary file: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.Remove(f.Name()); err != nil {
+			t.Logf("Removing temporary file: %v", err)
+		}
+	})
+
+	out := f
+
+	restore, err := redact.Redirect(&out)
+	if err != nil {
+		t.Fatalf("Redirecting output: %v", err)
+	}
+
+	if out == f {
+		t.Fatalf("Output should be replaced")
+	}
+
+	fmt.Fprintf(out, "token: %s\n", "redirected-s3cr3t")
+
+	restore()
+	restore()
+
+	if out != f {
+		t.Fatalf("Output should be restored")
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Closing temporary file: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("Reading temporary file: %v", err)
+	}
+
+	if expected := "token: " + redact.Placeholder + "\n"; string(content) != expected {
+		t.Fatalf("Expected %q, got %q", expected, string(content))
+	}
+}
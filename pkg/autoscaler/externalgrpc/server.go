@@ -0,0 +1,274 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc/protos"
+)
+
+// ErrNodeGroupNotFound is returned by Provider, when requested node group does not exist.
+var ErrNodeGroupNotFound = errors.New("node group not found")
+
+// NodeGroup describes a group of nodes, which can be scaled by the Cluster Autoscaler.
+type NodeGroup struct {
+	ID      string
+	MinSize int
+	MaxSize int
+}
+
+// Provider is implemented by backends managing node groups served over the external
+// gRPC cloud provider protocol. Nodes are identified by their provider IDs.
+type Provider interface {
+	// Refresh is called before every Cluster Autoscaler main loop and should update
+	// the state of all node groups.
+	Refresh(ctx context.Context) error
+
+	// NodeGroups returns all node groups managed by the provider.
+	NodeGroups() []NodeGroup
+
+	// NodeGroupForNode returns ID of the node group the node with given provider ID
+	// belongs to or empty string, if the node is not managed by the provider.
+	NodeGroupForNode(providerID string) string
+
+	// TargetSize returns the number of nodes the node group should have.
+	TargetSize(id string) (int, error)
+
+	// IncreaseSize adds given number of nodes to the node group.
+	IncreaseSize(ctx context.Context, id string, delta int) error
+
+	// DeleteNodes removes nodes with given provider IDs from the node group.
+	DeleteNodes(ctx context.Context, id string, providerIDs []string) error
+
+	// Nodes returns provider IDs of all nodes in the node group.
+	Nodes(id string) ([]string, error)
+
+	// TemplateNode returns a node object describing a new node of the node group.
+	TemplateNode(id string) (*corev1.Node, error)
+}
+
+// Server translates external gRPC cloud provider calls to Provider calls.
+type Server struct {
+	protos.UnimplementedCloudProviderServer
+
+	provider Provider
+}
+
+// NewServer returns Server serving node groups of the given provider.
+func NewServer(provider Provider) *Server {
+	return &Server{
+		provider: provider,
+	}
+}
+
+// TLSConfig returns server TLS configuration using given certificate and key files.
+// Clients must present a certificate signed by the given CA certificate.
+func TLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, errors.New("server certificate, key and client CA certificate files are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	ca, err := ioutil.ReadFile(caFile) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %q", caFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func toStatus(err error) error {
+	if errors.Is(err, ErrNodeGroupNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (s *Server) nodeGroup(id string) (*protos.NodeGroup, error) {
+	for _, ng := range s.provider.NodeGroups() {
+		if ng.ID == id {
+			return &protos.NodeGroup{
+				Id:      ng.ID,
+				MinSize: int32(ng.MinSize),
+				MaxSize: int32(ng.MaxSize),
+			}, nil
+		}
+	}
+
+	return nil, toStatus(fmt.Errorf("%w: %q", ErrNodeGroupNotFound, id))
+}
+
+// NodeGroups returns all node groups of the provider.
+func (s *Server) NodeGroups(ctx context.Context, req *protos.NodeGroupsRequest) (*protos.NodeGroupsResponse, error) {
+	resp := &protos.NodeGroupsResponse{}
+
+	for _, ng := range s.provider.NodeGroups() {
+		resp.NodeGroups = append(resp.NodeGroups, &protos.NodeGroup{
+			Id:      ng.ID,
+			MinSize: int32(ng.MinSize),
+			MaxSize: int32(ng.MaxSize),
+		})
+	}
+
+	return resp, nil
+}
+
+// NodeGroupForNode returns the node group of the given node. Node group with an empty
+// ID is returned for nodes not managed by the provider.
+func (s *Server) NodeGroupForNode(ctx context.Context, req *protos.NodeGroupForNodeRequest) (*protos.NodeGroupForNodeResponse, error) {
+	id := s.provider.NodeGroupForNode(req.GetNode().GetProviderID())
+	if id == "" {
+		return &protos.NodeGroupForNodeResponse{
+			NodeGroup: &protos.NodeGroup{},
+		}, nil
+	}
+
+	ng, err := s.nodeGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.NodeGroupForNodeResponse{
+		NodeGroup: ng,
+	}, nil
+}
+
+// GPULabel returns an empty label, as GPU nodes are not handled specially.
+func (s *Server) GPULabel(ctx context.Context, req *protos.GPULabelRequest) (*protos.GPULabelResponse, error) {
+	return &protos.GPULabelResponse{}, nil
+}
+
+// GetAvailableGPUTypes returns no GPU types.
+func (s *Server) GetAvailableGPUTypes(ctx context.Context, req *protos.GetAvailableGPUTypesRequest) (*protos.GetAvailableGPUTypesResponse, error) {
+	return &protos.GetAvailableGPUTypesResponse{}, nil
+}
+
+// Cleanup does nothing, as the provider holds no resources on behalf of the Cluster Autoscaler.
+func (s *Server) Cleanup(ctx context.Context, req *protos.CleanupRequest) (*protos.CleanupResponse, error) {
+	return &protos.CleanupResponse{}, nil
+}
+
+// Refresh refreshes the state of the provider.
+func (s *Server) Refresh(ctx context.Context, req *protos.RefreshRequest) (*protos.RefreshResponse, error) {
+	if err := s.provider.Refresh(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &protos.RefreshResponse{}, nil
+}
+
+// NodeGroupTargetSize returns the target size of the node group.
+func (s *Server) NodeGroupTargetSize(ctx context.Context, req *protos.NodeGroupTargetSizeRequest) (*protos.NodeGroupTargetSizeResponse, error) {
+	size, err := s.provider.TargetSize(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &protos.NodeGroupTargetSizeResponse{
+		TargetSize: int32(size),
+	}, nil
+}
+
+// NodeGroupIncreaseSize adds nodes to the node group.
+func (s *Server) NodeGroupIncreaseSize(ctx context.Context, req *protos.NodeGroupIncreaseSizeRequest) (*protos.NodeGroupIncreaseSizeResponse, error) {
+	if req.GetDelta() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "delta must be positive")
+	}
+
+	if err := s.provider.IncreaseSize(ctx, req.GetId(), int(req.GetDelta())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &protos.NodeGroupIncreaseSizeResponse{}, nil
+}
+
+// NodeGroupDeleteNodes removes given nodes from the node group.
+func (s *Server) NodeGroupDeleteNodes(ctx context.Context, req *protos.NodeGroupDeleteNodesRequest) (*protos.NodeGroupDeleteNodesResponse, error) {
+	providerIDs := []string{}
+
+	for _, n := range req.GetNodes() {
+		providerIDs = append(providerIDs, n.GetProviderID())
+	}
+
+	if err := s.provider.DeleteNodes(ctx, req.GetId(), providerIDs); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &protos.NodeGroupDeleteNodesResponse{}, nil
+}
+
+// NodeGroupDecreaseTargetSize always fails, as nodes are provisioned as soon as the
+// size of the node group is increased, so there are no pending nodes to cancel.
+func (s *Server) NodeGroupDecreaseTargetSize(ctx context.Context, req *protos.NodeGroupDecreaseTargetSizeRequest) (*protos.NodeGroupDecreaseTargetSizeResponse, error) {
+	return nil, status.Error(codes.FailedPrecondition, "decreasing target size without deleting nodes is not supported")
+}
+
+// NodeGroupNodes returns all nodes of the node group.
+func (s *Server) NodeGroupNodes(ctx context.Context, req *protos.NodeGroupNodesRequest) (*protos.NodeGroupNodesResponse, error) {
+	providerIDs, err := s.provider.Nodes(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &protos.NodeGroupNodesResponse{}
+
+	for _, id := range providerIDs {
+		resp.Instances = append(resp.Instances, &protos.Instance{
+			Id: id,
+			Status: &protos.InstanceStatus{
+				InstanceState: protos.InstanceStatus_instanceRunning,
+			},
+		})
+	}
+
+	return resp, nil
+}
+
+// NodeGroupTemplateNodeInfo returns a node object describing a new node of the node group.
+func (s *Server) NodeGroupTemplateNodeInfo(ctx context.Context, req *protos.NodeGroupTemplateNodeInfoRequest) (*protos.NodeGroupTemplateNodeInfoResponse, error) {
+	node, err := s.provider.TemplateNode(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &protos.NodeGroupTemplateNodeInfoResponse{
+		NodeInfo: node,
+	}, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc"
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc/protos"
+)
+
+type fakeProvider struct {
+	size int
+}
+
+func (f *fakeProvider) Refresh(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeProvider) NodeGroups() []externalgrpc.NodeGroup {
+	return []externalgrpc.NodeGroup{{ID: "foo", MaxSize: 3}}
+}
+
+func (f *fakeProvider) NodeGroupForNode(providerID string) string {
+	if providerID == "fake://foo-0" {
+		return "foo"
+	}
+
+	return ""
+}
+
+func (f *fakeProvider) check(id string) error {
+	if id != "foo" {
+		return fmt.Errorf("%w: %q", externalgrpc.ErrNodeGroupNotFound, id)
+	}
+
+	return nil
+}
+
+func (f *fakeProvider) TargetSize(id string) (int, error) {
+	return f.size, f.check(id)
+}
+
+func (f *fakeProvider) IncreaseSize(ctx context.Context, id string, delta int) error {
+	f.size += delta
+
+	return f.check(id)
+}
+
+func (f *fakeProvider) DeleteNodes(ctx context.Context, id string, providerIDs []string) error {
+	f.size -= len(providerIDs)
+
+	return f.check(id)
+}
+
+func (f *fakeProvider) Nodes(id string) ([]string, error) {
+	return []string{"fake://foo-0"}, f.check(id)
+}
+
+func (f *fakeProvider) TemplateNode(id string) (*corev1.Node, error) {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo-template",
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+		},
+	}, f.check(id)
+}
+
+func newClient(t *testing.T, p externalgrpc.Provider) protos.CloudProviderClient {
+	t.Helper()
+
+	l := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+
+	protos.RegisterCloudProviderServer(s, externalgrpc.NewServer(p))
+
+	go func() {
+		if err := s.Serve(l); err != nil {
+			t.Logf("Serving: %v", err)
+		}
+	}()
+
+	t.Cleanup(s.Stop)
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return l.Dial()
+	}
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dialing server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := conn.Close(); err != nil {
+			t.Logf("Closing connection: %v", err)
+		}
+	})
+
+	return protos.NewCloudProviderClient(conn)
+}
+
+func TestNodeGroupForNode(t *testing.T) {
+	c := newClient(t, &fakeProvider{})
+	ctx := context.Background()
+
+	resp, err := c.NodeGroupForNode(ctx, &protos.NodeGroupForNodeRequest{
+		Node: &protos.ExternalGrpcNode{ProviderID: "fake://foo-0"},
+	})
+	if err != nil {
+		t.Fatalf("Getting node group for node: %v", err)
+	}
+
+	if ng := resp.GetNodeGroup(); ng.GetId() != "foo" || ng.GetMaxSize() != 3 {
+		t.Fatalf("Expected node group foo with max size 3, got %v", ng)
+	}
+
+	resp, err = c.NodeGroupForNode(ctx, &protos.NodeGroupForNodeRequest{
+		Node: &protos.ExternalGrpcNode{Name: "static"},
+	})
+	if err != nil {
+		t.Fatalf("Getting node group for node: %v", err)
+	}
+
+	if id := resp.GetNodeGroup().GetId(); id != "" {
+		t.Fatalf("Expected no node group for node without provider ID, got %q", id)
+	}
+}
+
+func TestIncreaseSize(t *testing.T) {
+	c := newClient(t, &fakeProvider{})
+	ctx := context.Background()
+
+	if _, err := c.NodeGroupIncreaseSize(ctx, &protos.NodeGroupIncreaseSizeRequest{Id: "foo", Delta: 2}); err != nil {
+		t.Fatalf("Increasing size: %v", err)
+	}
+
+	resp, err := c.NodeGroupTargetSize(ctx, &protos.NodeGroupTargetSizeRequest{Id: "foo"})
+	if err != nil {
+		t.Fatalf("Getting target size: %v", err)
+	}
+
+	if resp.GetTargetSize() != 2 {
+		t.Fatalf("Expected target size 2, got %d", resp.GetTargetSize())
+	}
+
+	_, err = c.NodeGroupIncreaseSize(ctx, &protos.NodeGroupIncreaseSizeRequest{Id: "foo", Delta: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected invalid argument error for negative delta, got: %v", err)
+	}
+}
+
+func TestUnknownNodeGroupReturnsNotFound(t *testing.T) {
+	c := newClient(t, &fakeProvider{})
+
+	_, err := c.NodeGroupNodes(context.Background(), &protos.NodeGroupNodesRequest{Id: "bar"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected not found error, got: %v", err)
+	}
+}
+
+func TestNodeGroupTemplateNodeInfo(t *testing.T) {
+	c := newClient(t, &fakeProvider{})
+
+	resp, err := c.NodeGroupTemplateNodeInfo(context.Background(), &protos.NodeGroupTemplateNodeInfoRequest{Id: "foo"})
+	if err != nil {
+		t.Fatalf("Getting template node info: %v", err)
+	}
+
+	node := resp.GetNodeInfo()
+
+	if node.Name != "foo-template" {
+		t.Fatalf("Expected template node name %q, got %q", "foo-template", node.Name)
+	}
+
+	if cpu := node.Status.Capacity[corev1.ResourceCPU]; cpu.String() != "4" {
+		t.Fatalf("Expected CPU capacity 4, got %s", cpu.String())
+	}
+}
+
+func TestTLSConfigRequiresClientCA(t *testing.T) {
+	if _, err := externalgrpc.TLSConfig("server.crt", "server.key", ""); err == nil {
+		t.Fatalf("Expected error when client CA certificate file is not given")
+	}
+}
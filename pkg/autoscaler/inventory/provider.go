@@ -0,0 +1,295 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inventory implements autoscaling of node groups by provisioning idle machines
+// from an inventory of physical machines.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc"
+)
+
+// Machine is an idle machine, which can be provisioned as a node.
+type Machine struct {
+	// ID identifies the machine in the inventory.
+	ID string
+}
+
+// Slot is a placeholder for a node, which can be added to a node group. Each slot has a
+// fixed provider ID, which is assigned to the node provisioned into it, so the number
+// of slots of a node group is its maximum size.
+type Slot struct {
+	// ID identifies the slot in the inventory.
+	ID string
+
+	// NodeGroup is a name of the node group the slot belongs to.
+	NodeGroup string
+
+	// ProviderID is the provider ID of the node provisioned into the slot.
+	ProviderID string
+
+	// Machine is an ID of the machine provisioned into the slot or empty string, if
+	// the slot is free.
+	Machine string
+}
+
+// Inventory is implemented by the machine provisioning systems.
+type Inventory interface {
+	// Slots returns all slots of the cluster.
+	Slots(ctx context.Context) ([]Slot, error)
+
+	// IdleMachines returns machines, which are not provisioned.
+	IdleMachines(ctx context.Context) ([]Machine, error)
+
+	// Provision provisions given machine into given free slot.
+	Provision(ctx context.Context, slot Slot, machine Machine) error
+
+	// Release frees the slot, returning the machine back to the inventory.
+	Release(ctx context.Context, slot Slot) error
+}
+
+// NodeGroup is a configuration of a node group.
+type NodeGroup struct {
+	// Name of the node group.
+	Name string
+
+	// Template describes a node, which is added to the node group.
+	Template *corev1.Node
+}
+
+// Provider scales node groups by provisioning idle machines from the inventory.
+// It implements externalgrpc.Provider.
+type Provider struct {
+	inventory  Inventory
+	nodeGroups []NodeGroup
+
+	mu    sync.Mutex
+	slots []Slot
+}
+
+// NewProvider creates a provider for given node groups using given inventory.
+// Refresh must be called before using the provider.
+func NewProvider(inventory Inventory, nodeGroups []NodeGroup) *Provider {
+	return &Provider{
+		inventory:  inventory,
+		nodeGroups: nodeGroups,
+	}
+}
+
+// Refresh fetches slots from the inventory.
+func (p *Provider) Refresh(ctx context.Context) error {
+	slots, err := p.inventory.Slots(ctx)
+	if err != nil {
+		return fmt.Errorf("getting slots: %w", err)
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].ProviderID < slots[j].ProviderID
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.slots = slots
+
+	return nil
+}
+
+// NodeGroups returns all configured node groups. The maximum size of each node group is
+// the number of its slots.
+func (p *Provider) NodeGroups() []externalgrpc.NodeGroup {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	nodeGroups := []externalgrpc.NodeGroup{}
+
+	for _, ng := range p.nodeGroups {
+		nodeGroups = append(nodeGroups, externalgrpc.NodeGroup{
+			ID:      ng.Name,
+			MaxSize: len(p.groupSlots(ng.Name)),
+		})
+	}
+
+	return nodeGroups
+}
+
+// NodeGroupForNode returns the node group of the slot with given provider ID.
+func (p *Provider) NodeGroupForNode(providerID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if providerID == "" {
+		return ""
+	}
+
+	for _, s := range p.slots {
+		if s.ProviderID == providerID && p.known(s.NodeGroup) {
+			return s.NodeGroup
+		}
+	}
+
+	return ""
+}
+
+// TargetSize returns the number of provisioned slots of the node group.
+func (p *Provider) TargetSize(id string) (int, error) {
+	nodes, err := p.Nodes(id)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(nodes), nil
+}
+
+// Nodes returns provider IDs of provisioned slots of the node group.
+func (p *Provider) Nodes(id string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.known(id) {
+		return nil, fmt.Errorf("%w: %q", externalgrpc.ErrNodeGroupNotFound, id)
+	}
+
+	providerIDs := []string{}
+
+	for _, s := range p.groupSlots(id) {
+		if s.Machine != "" {
+			providerIDs = append(providerIDs, s.ProviderID)
+		}
+	}
+
+	return providerIDs, nil
+}
+
+// IncreaseSize provisions idle machines into free slots of the node group.
+func (p *Provider) IncreaseSize(ctx context.Context, id string, delta int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.known(id) {
+		return fmt.Errorf("%w: %q", externalgrpc.ErrNodeGroupNotFound, id)
+	}
+
+	free := []int{}
+
+	for i, s := range p.slots {
+		if s.NodeGroup == id && s.Machine == "" {
+			free = append(free, i)
+		}
+	}
+
+	if delta > len(free) {
+		return fmt.Errorf("node group %q has only %d free slots, requested %d", id, len(free), delta)
+	}
+
+	machines, err := p.inventory.IdleMachines(ctx)
+	if err != nil {
+		return fmt.Errorf("getting idle machines: %w", err)
+	}
+
+	if delta > len(machines) {
+		return fmt.Errorf("only %d idle machines available, requested %d", len(machines), delta)
+	}
+
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].ID < machines[j].ID
+	})
+
+	for i := 0; i < delta; i++ {
+		slot := &p.slots[free[i]]
+
+		if err := p.inventory.Provision(ctx, *slot, machines[i]); err != nil {
+			return fmt.Errorf("provisioning machine %q into slot %q: %w", machines[i].ID, slot.ProviderID, err)
+		}
+
+		slot.Machine = machines[i].ID
+	}
+
+	return nil
+}
+
+// DeleteNodes releases slots with given provider IDs.
+func (p *Provider) DeleteNodes(ctx context.Context, id string, providerIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.known(id) {
+		return fmt.Errorf("%w: %q", externalgrpc.ErrNodeGroupNotFound, id)
+	}
+
+	for _, providerID := range providerIDs {
+		slot := p.provisionedSlot(id, providerID)
+		if slot == nil {
+			return fmt.Errorf("node %q does not belong to node group %q", providerID, id)
+		}
+
+		if err := p.inventory.Release(ctx, *slot); err != nil {
+			return fmt.Errorf("releasing slot %q: %w", providerID, err)
+		}
+
+		slot.Machine = ""
+	}
+
+	return nil
+}
+
+// TemplateNode returns a copy of the template node of the node group.
+func (p *Provider) TemplateNode(id string) (*corev1.Node, error) {
+	for _, ng := range p.nodeGroups {
+		if ng.Name == id {
+			return ng.Template.DeepCopy(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", externalgrpc.ErrNodeGroupNotFound, id)
+}
+
+func (p *Provider) known(id string) bool {
+	for _, ng := range p.nodeGroups {
+		if ng.Name == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Provider) groupSlots(id string) []Slot {
+	slots := []Slot{}
+
+	for _, s := range p.slots {
+		if s.NodeGroup == id {
+			slots = append(slots, s)
+		}
+	}
+
+	return slots
+}
+
+func (p *Provider) provisionedSlot(id, providerID string) *Slot {
+	for i, s := range p.slots {
+		if s.NodeGroup == id && s.ProviderID == providerID && s.Machine != "" {
+			return &p.slots[i]
+		}
+	}
+
+	return nil
+}
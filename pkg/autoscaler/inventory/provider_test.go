@@ -0,0 +1,245 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/externalgrpc"
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/inventory"
+)
+
+type fakeInventory struct {
+	slots    []inventory.Slot
+	machines []inventory.Machine
+}
+
+func (f *fakeInventory) Slots(ctx context.Context) ([]inventory.Slot, error) {
+	return append([]inventory.Slot{}, f.slots...), nil
+}
+
+func (f *fakeInventory) IdleMachines(ctx context.Context) ([]inventory.Machine, error) {
+	idle := []inventory.Machine{}
+
+	for _, m := range f.machines {
+		used := false
+
+		for _, s := range f.slots {
+			if s.Machine == m.ID {
+				used = true
+			}
+		}
+
+		if !used {
+			idle = append(idle, m)
+		}
+	}
+
+	return idle, nil
+}
+
+func (f *fakeInventory) Provision(ctx context.Context, slot inventory.Slot, machine inventory.Machine) error {
+	for i, s := range f.slots {
+		if s.ID == slot.ID {
+			f.slots[i].Machine = machine.ID
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeInventory) Release(ctx context.Context, slot inventory.Slot) error {
+	for i, s := range f.slots {
+		if s.ID == slot.ID {
+			f.slots[i].Machine = ""
+		}
+	}
+
+	return nil
+}
+
+func newFakeInventory() *fakeInventory {
+	return &fakeInventory{
+		slots: []inventory.Slot{
+			{ID: "1", NodeGroup: "foo", ProviderID: "fake://foo-0", Machine: "10.0.0.1"},
+			{ID: "2", NodeGroup: "foo", ProviderID: "fake://foo-1"},
+			{ID: "3", NodeGroup: "foo", ProviderID: "fake://foo-2"},
+			{ID: "4", NodeGroup: "bar", ProviderID: "fake://bar-0"},
+			{ID: "5", NodeGroup: "baz", ProviderID: "fake://baz-0"},
+		},
+		machines: []inventory.Machine{
+			{ID: "10.0.0.1"},
+			{ID: "10.0.0.3"},
+			{ID: "10.0.0.2"},
+		},
+	}
+}
+
+func newProvider(t *testing.T, inv inventory.Inventory) *inventory.Provider {
+	t.Helper()
+
+	p := inventory.NewProvider(inv, []inventory.NodeGroup{
+		{Name: "foo", Template: &corev1.Node{}},
+		{Name: "bar", Template: &corev1.Node{}},
+	})
+
+	if err := p.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refreshing provider: %v", err)
+	}
+
+	return p
+}
+
+func TestNodeGroups(t *testing.T) {
+	p := newProvider(t, newFakeInventory())
+
+	expected := []externalgrpc.NodeGroup{
+		{ID: "foo", MaxSize: 3},
+		{ID: "bar", MaxSize: 1},
+	}
+
+	if got := p.NodeGroups(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Expected node groups %+v, got %+v", expected, got)
+	}
+}
+
+func TestNodeGroupForNode(t *testing.T) {
+	p := newProvider(t, newFakeInventory())
+
+	cases := map[string]string{
+		"fake://foo-0": "foo",
+		"fake://bar-0": "bar",
+		"fake://baz-0": "",
+		"":             "",
+	}
+
+	for providerID, expected := range cases {
+		if got := p.NodeGroupForNode(providerID); got != expected {
+			t.Errorf("Expected node group %q for node %q, got %q", expected, providerID, got)
+		}
+	}
+}
+
+func TestIncreaseSizeProvisionsIdleMachines(t *testing.T) {
+	inv := newFakeInventory()
+	p := newProvider(t, inv)
+
+	if err := p.IncreaseSize(context.Background(), "foo", 2); err != nil {
+		t.Fatalf("Increasing size should succeed, got: %v", err)
+	}
+
+	size, err := p.TargetSize("foo")
+	if err != nil {
+		t.Fatalf("Getting target size: %v", err)
+	}
+
+	if size != 3 {
+		t.Fatalf("Expected target size 3, got %d", size)
+	}
+
+	if inv.slots[1].Machine != "10.0.0.2" || inv.slots[2].Machine != "10.0.0.3" {
+		t.Fatalf("Expected idle machines to be provisioned in order, got slots %+v", inv.slots)
+	}
+}
+
+func TestIncreaseSizeFailsWhen(t *testing.T) {
+	cases := map[string]struct {
+		nodeGroup string
+		delta     int
+		machines  []inventory.Machine
+	}{
+		"there_are_no_free_slots": {
+			nodeGroup: "foo",
+			delta:     3,
+		},
+		"there_are_no_idle_machines": {
+			nodeGroup: "foo",
+			delta:     1,
+			machines:  []inventory.Machine{{ID: "10.0.0.1"}},
+		},
+		"node_group_is_not_configured": {
+			nodeGroup: "baz",
+			delta:     1,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+
+		t.Run(n, func(t *testing.T) {
+			inv := newFakeInventory()
+			if tc.machines != nil {
+				inv.machines = tc.machines
+			}
+
+			p := newProvider(t, inv)
+
+			if err := p.IncreaseSize(context.Background(), tc.nodeGroup, tc.delta); err == nil {
+				t.Fatalf("Increasing size should fail")
+			}
+
+			if inv.slots[1].Machine != "" {
+				t.Fatalf("No machine should be provisioned, got slots %+v", inv.slots)
+			}
+		})
+	}
+}
+
+func TestDeleteNodesReleasesSlots(t *testing.T) {
+	inv := newFakeInventory()
+	p := newProvider(t, inv)
+
+	if err := p.DeleteNodes(context.Background(), "foo", []string{"fake://foo-0"}); err != nil {
+		t.Fatalf("Deleting nodes should succeed, got: %v", err)
+	}
+
+	if inv.slots[0].Machine != "" {
+		t.Fatalf("Slot should be released, got %+v", inv.slots[0])
+	}
+
+	nodes, err := p.Nodes("foo")
+	if err != nil {
+		t.Fatalf("Getting nodes: %v", err)
+	}
+
+	if len(nodes) != 0 {
+		t.Fatalf("Expected no nodes, got %v", nodes)
+	}
+}
+
+func TestDeleteNodesRejectsNodesFromOtherNodeGroups(t *testing.T) {
+	p := newProvider(t, newFakeInventory())
+
+	if err := p.DeleteNodes(context.Background(), "bar", []string{"fake://foo-0"}); err == nil {
+		t.Fatalf("Deleting node from other node group should fail")
+	}
+}
+
+func TestUnknownNodeGroup(t *testing.T) {
+	p := newProvider(t, newFakeInventory())
+
+	if _, err := p.TargetSize("baz"); !errors.Is(err, externalgrpc.ErrNodeGroupNotFound) {
+		t.Fatalf("Expected node group not found error, got: %v", err)
+	}
+
+	if _, err := p.TemplateNode("baz"); !errors.Is(err, externalgrpc.ErrNodeGroupNotFound) {
+		t.Fatalf("Expected node group not found error, got: %v", err)
+	}
+}
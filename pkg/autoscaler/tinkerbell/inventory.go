@@ -0,0 +1,312 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tinkerbell implements autoscaler inventory backed by Tinkerbell server.
+package tinkerbell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/tinkerbell/tink/protos/hardware"
+	"github.com/tinkerbell/tink/protos/template"
+	"github.com/tinkerbell/tink/protos/workflow"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/inventory"
+)
+
+const (
+	// ProviderIDPrefix is a prefix of provider IDs of autoscaled Tinkerbell nodes.
+	ProviderIDPrefix = "tinkerbell://"
+
+	// workflowDevice is the name of the device used in Lokomotive workflow templates.
+	workflowDevice = "device_1"
+)
+
+// Inventory uses Tinkerbell hardware as machines and templates created for worker pools
+// as slots. A slot is provisioned, when a workflow for its template exists.
+type Inventory struct {
+	clusterName string
+	pools       []string
+
+	hardware  hardware.HardwareServiceClient
+	templates template.TemplateServiceClient
+	workflows workflow.WorkflowServiceClient
+	nodes     corev1client.NodeInterface
+}
+
+// NewInventory creates inventory of given cluster worker pools using given connection
+// to Tinkerbell server. Given nodes client is used to find hardware still used by the cluster.
+func NewInventory(conn grpc.ClientConnInterface, nodes corev1client.NodeInterface, clusterName string, pools []string) *Inventory { //nolint:lll
+	return &Inventory{
+		clusterName: clusterName,
+		pools:       pools,
+		hardware:    hardware.NewHardwareServiceClient(conn),
+		templates:   template.NewTemplateServiceClient(conn),
+		workflows:   workflow.NewWorkflowServiceClient(conn),
+		nodes:       nodes,
+	}
+}
+
+// SlotTemplateName returns name of the Tinkerbell template of the slot with given index
+// in given worker pool. It must be kept in sync with Terraform code.
+func SlotTemplateName(clusterName, pool string, index int) string {
+	return fmt.Sprintf("%s-worker-%s-spare-%d", clusterName, pool, index)
+}
+
+// slotPool returns name of the worker pool the template belongs to or empty string,
+// if the template is not a slot template of any of given pools.
+func slotPool(templateName, clusterName string, pools []string) string {
+	for _, p := range pools {
+		r := regexp.MustCompile(fmt.Sprintf(`^%s-worker-%s-spare-\d+$`, regexp.QuoteMeta(clusterName), regexp.QuoteMeta(p)))
+
+		if r.MatchString(templateName) {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// workflowMachine returns the machine workflow is running on.
+func workflowMachine(hardware string) (string, error) {
+	devices := map[string]string{}
+
+	if err := json.Unmarshal([]byte(hardware), &devices); err != nil {
+		return "", fmt.Errorf("parsing workflow hardware %q: %w", hardware, err)
+	}
+
+	return devices[workflowDevice], nil
+}
+
+// hardwareAddresses returns IP and MAC addresses of all hardware interfaces.
+func hardwareAddresses(h *hardware.Hardware) []string {
+	addresses := []string{}
+
+	for _, i := range h.GetNetwork().GetInterfaces() {
+		if ip := i.GetDhcp().GetIp().GetAddress(); ip != "" {
+			addresses = append(addresses, ip)
+		}
+
+		if mac := i.GetDhcp().GetMac(); mac != "" {
+			addresses = append(addresses, mac)
+		}
+	}
+
+	return addresses
+}
+
+// nodeAddresses returns all addresses of given nodes.
+func nodeAddresses(nodes []corev1.Node) []string {
+	addresses := []string{}
+
+	for _, n := range nodes {
+		for _, a := range n.Status.Addresses {
+			addresses = append(addresses, a.Address)
+		}
+	}
+
+	return addresses
+}
+
+// workflowsByTemplate returns all workflows indexed by template ID.
+func (i *Inventory) workflowsByTemplate(ctx context.Context) (map[string]*workflow.Workflow, error) {
+	stream, err := i.workflows.ListWorkflows(ctx, &workflow.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("listing workflows: %w", err)
+	}
+
+	workflows := map[string]*workflow.Workflow{}
+
+	for {
+		w, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return workflows, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("receiving workflow: %w", err)
+		}
+
+		workflows[w.GetTemplate()] = w
+	}
+}
+
+// Slots returns slots based on templates created for cluster worker pools.
+func (i *Inventory) Slots(ctx context.Context) ([]inventory.Slot, error) {
+	workflows, err := i.workflowsByTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := i.templates.ListTemplates(ctx, &template.ListRequest{
+		FilterBy: &template.ListRequest_Name{
+			Name: i.clusterName + "-worker-*",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing templates: %w", err)
+	}
+
+	slots := []inventory.Slot{}
+
+	for {
+		t, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return slots, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("receiving template: %w", err)
+		}
+
+		pool := slotPool(t.GetName(), i.clusterName, i.pools)
+		if pool == "" {
+			continue
+		}
+
+		slot := inventory.Slot{
+			ID:         t.GetId(),
+			NodeGroup:  pool,
+			ProviderID: ProviderIDPrefix + t.GetName(),
+		}
+
+		if w, ok := workflows[t.GetId()]; ok {
+			if slot.Machine, err = workflowMachine(w.GetHardware()); err != nil {
+				return nil, fmt.Errorf("workflow %q: %w", w.GetId(), err)
+			}
+		}
+
+		slots = append(slots, slot)
+	}
+}
+
+// IdleMachines returns IP addresses of hardware, which is not used by any workflow and does not
+// back any node registered in the cluster.
+func (i *Inventory) IdleMachines(ctx context.Context) ([]inventory.Machine, error) {
+	workflows, err := i.workflowsByTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	busy := map[string]bool{}
+
+	for _, w := range workflows {
+		m, err := workflowMachine(w.GetHardware())
+		if err != nil {
+			return nil, fmt.Errorf("workflow %q: %w", w.GetId(), err)
+		}
+
+		busy[m] = true
+	}
+
+	// Releasing a slot only removes its workflow, so the hardware is still running
+	// the node until it gets deprovisioned.
+	nodes, err := i.nodes.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	for _, a := range nodeAddresses(nodes.Items) {
+		busy[a] = true
+	}
+
+	stream, err := i.hardware.All(ctx, &hardware.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("listing hardware: %w", err)
+	}
+
+	machines := []inventory.Machine{}
+
+	for {
+		h, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return machines, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("receiving hardware: %w", err)
+		}
+
+		if m, ok := idleMachine(h, busy); ok {
+			machines = append(machines, m)
+		}
+	}
+}
+
+// idleMachine returns the machine for given hardware, if none of its addresses is used
+// by a workflow or a node.
+func idleMachine(h *hardware.Hardware, busy map[string]bool) (inventory.Machine, bool) {
+	addresses := hardwareAddresses(h)
+
+	for _, a := range addresses {
+		if busy[a] {
+			return inventory.Machine{}, false
+		}
+	}
+
+	for _, i := range h.GetNetwork().GetInterfaces() {
+		if ip := i.GetDhcp().GetIp().GetAddress(); ip != "" {
+			return inventory.Machine{ID: ip}, true
+		}
+	}
+
+	return inventory.Machine{}, false
+}
+
+// Provision creates a workflow installing the slot template on the machine.
+func (i *Inventory) Provision(ctx context.Context, slot inventory.Slot, machine inventory.Machine) error {
+	devices, err := json.Marshal(map[string]string{
+		workflowDevice: machine.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding workflow hardware: %w", err)
+	}
+
+	if _, err := i.workflows.CreateWorkflow(ctx, &workflow.CreateRequest{
+		Template: slot.ID,
+		Hardware: string(devices),
+	}); err != nil {
+		return fmt.Errorf("creating workflow: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes the workflow of the slot.
+func (i *Inventory) Release(ctx context.Context, slot inventory.Slot) error {
+	workflows, err := i.workflowsByTemplate(ctx)
+	if err != nil {
+		return err
+	}
+
+	w, ok := workflows[slot.ID]
+	if !ok {
+		return nil
+	}
+
+	if _, err := i.workflows.DeleteWorkflow(ctx, &workflow.GetRequest{Id: w.GetId()}); err != nil {
+		return fmt.Errorf("deleting workflow %q: %w", w.GetId(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tinkerbell
+
+import (
+	"testing"
+
+	"github.com/tinkerbell/tink/protos/hardware"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func testHardware(ip, mac string) *hardware.Hardware {
+	return &hardware.Hardware{
+		Network: &hardware.Hardware_Network{
+			Interfaces: []*hardware.Hardware_Network_Interface{
+				{
+					Dhcp: &hardware.Hardware_DHCP{
+						Mac: mac,
+						Ip: &hardware.Hardware_DHCP_IP{
+							Address: ip,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSlotPool(t *testing.T) {
+	t.Parallel()
+
+	pools := []string{"foo", "foo-bar"}
+
+	cases := map[string]string{
+		"mycluster-worker-foo-spare-0":      "foo",
+		"mycluster-worker-foo-bar-spare-12": "foo-bar",
+		"mycluster-worker-foo-0":            "",
+		"mycluster-worker-baz-spare-0":      "",
+		"othercluster-worker-foo-spare-0":   "",
+		"mycluster-worker-foo-spare-x":      "",
+	}
+
+	for name, expected := range cases {
+		if pool := slotPool(name, "mycluster", pools); pool != expected {
+			t.Errorf("Template %q: expected pool %q, got %q", name, expected, pool)
+		}
+	}
+}
+
+func TestSlotTemplateNameMatchesSlotPool(t *testing.T) {
+	t.Parallel()
+
+	name := SlotTemplateName("mycluster", "foo", 3)
+
+	if pool := slotPool(name, "mycluster", []string{"foo"}); pool != "foo" {
+		t.Fatalf("Template %q should be a slot of pool %q, got %q", name, "foo", pool)
+	}
+}
+
+func TestWorkflowMachine(t *testing.T) {
+	t.Parallel()
+
+	m, err := workflowMachine(`{"device_1": "10.0.0.5"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if m != "10.0.0.5" {
+		t.Fatalf("Expected machine %q, got %q", "10.0.0.5", m)
+	}
+
+	if _, err := workflowMachine("not JSON"); err == nil {
+		t.Fatal("Expected error when parsing invalid workflow hardware")
+	}
+}
+
+func TestIdleMachine(t *testing.T) {
+	t.Parallel()
+
+	busy := map[string]bool{
+		"10.0.0.5":          true,
+		"00:00:00:00:00:06": true,
+	}
+
+	cases := map[string]struct {
+		hardware *hardware.Hardware
+		idle     bool
+	}{
+		"used by IP":      {testHardware("10.0.0.5", "00:00:00:00:00:05"), false},
+		"used by MAC":     {testHardware("10.0.0.6", "00:00:00:00:00:06"), false},
+		"not used":        {testHardware("10.0.0.7", "00:00:00:00:00:07"), true},
+		"without address": {testHardware("", "00:00:00:00:00:08"), false},
+	}
+
+	for name, c := range cases {
+		m, ok := idleMachine(c.hardware, busy)
+		if ok != c.idle {
+			t.Errorf("%s: expected idle %v, got %v", name, c.idle, ok)
+		}
+
+		if ok && m.ID != "10.0.0.7" {
+			t.Errorf("%s: expected machine ID %q, got %q", name, "10.0.0.7", m.ID)
+		}
+	}
+}
+
+func TestIdleMachineSkipsHardwareOfRegisteredNodes(t *testing.T) {
+	t.Parallel()
+
+	nodes := []corev1.Node{
+		{
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{
+						Type:    corev1.NodeInternalIP,
+						Address: "10.0.0.5",
+					},
+				},
+			},
+		},
+	}
+
+	busy := map[string]bool{}
+
+	for _, a := range nodeAddresses(nodes) {
+		busy[a] = true
+	}
+
+	if _, ok := idleMachine(testHardware("10.0.0.5", "00:00:00:00:00:05"), busy); ok {
+		t.Fatal("Hardware backing registered node should not be idle")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tinkerbell
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/autoscaler/inventory"
+	"github.com/kinvolk/lokomotive/pkg/platform/tinkerbell"
+)
+
+const (
+	// defaultMaxPods is the kubelet default for maximum number of pods on a node.
+	defaultMaxPods = 110
+
+	// workerPoolLabel is a label holding the worker pool name set on spare worker nodes.
+	// It must be kept in sync with Terraform code.
+	workerPoolLabel = "lokomotive.alpha.kinvolk.io/worker-pool"
+)
+
+// NodeGroups returns node groups for all worker pools of the cluster with autoscaling
+// configured.
+func NodeGroups(c *tinkerbell.Config) ([]inventory.NodeGroup, error) {
+	nodeGroups := []inventory.NodeGroup{}
+
+	for _, p := range c.WorkerPools {
+		if p.Autoscaling == nil {
+			continue
+		}
+
+		node, err := templateNode(c.Name, p)
+		if err != nil {
+			return nil, fmt.Errorf("creating template node for worker pool %q: %w", p.PoolName, err)
+		}
+
+		nodeGroups = append(nodeGroups, inventory.NodeGroup{
+			Name:     p.PoolName,
+			Template: node,
+		})
+	}
+
+	return nodeGroups, nil
+}
+
+// templateNode returns a ready node with the capacity declared in the worker pool autoscaling
+// configuration and labels and taints set on the worker pool nodes.
+func templateNode(clusterName string, pool tinkerbell.WorkerPool) (*corev1.Node, error) {
+	a := pool.Autoscaling
+
+	cpu, err := resource.ParseQuantity(a.CPU)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CPU: %w", err)
+	}
+
+	memory, err := resource.ParseQuantity(a.Memory)
+	if err != nil {
+		return nil, fmt.Errorf("parsing memory: %w", err)
+	}
+
+	maxPods := a.MaxPods
+	if maxPods == 0 {
+		maxPods = defaultMaxPods
+	}
+
+	taints, err := parseTaints(pool.Taints)
+	if err != nil {
+		return nil, fmt.Errorf("parsing taints: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-worker-%s-template", clusterName, pool.PoolName)
+
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    cpu,
+		corev1.ResourceMemory: memory,
+		corev1.ResourcePods:   resource.MustParse(strconv.Itoa(maxPods)),
+	}
+
+	labels := map[string]string{}
+
+	for k, v := range pool.Labels {
+		labels[k] = v
+	}
+
+	for k, v := range map[string]string{
+		corev1.LabelOSStable:      "linux",
+		corev1.LabelArchStable:    "amd64",
+		corev1.LabelHostname:      name,
+		"node.kubernetes.io/node": "",
+		workerPoolLabel:           pool.PoolName,
+	} {
+		labels[k] = v
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: corev1.NodeSpec{
+			Taints: taints,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    capacity,
+			Allocatable: capacity,
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   corev1.NodeReady,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}, nil
+}
+
+// parseTaints converts worker pool taints in the kubelet format, where the map value is
+// "<value>:<effect>", to node taints sorted by key.
+func parseTaints(taints map[string]string) ([]corev1.Taint, error) {
+	keys := []string{}
+	for k := range taints {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	parsed := []corev1.Taint{}
+
+	for _, k := range keys {
+		i := strings.LastIndex(taints[k], ":")
+		if i < 0 {
+			return nil, fmt.Errorf("taint %q has no effect in value %q", k, taints[k])
+		}
+
+		parsed = append(parsed, corev1.Taint{
+			Key:    k,
+			Value:  taints[k][:i],
+			Effect: corev1.TaintEffect(taints[k][i+1:]),
+		})
+	}
+
+	return parsed, nil
+}
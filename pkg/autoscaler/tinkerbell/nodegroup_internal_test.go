@@ -0,0 +1,102 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tinkerbell
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/platform/tinkerbell"
+)
+
+func testWorkerPool() tinkerbell.WorkerPool {
+	return tinkerbell.WorkerPool{
+		PoolName: "foo",
+		Labels: map[string]string{
+			"testing.io": "yes",
+		},
+		Taints: map[string]string{
+			"nodeType":  "storage:NoSchedule",
+			"dedicated": "db:NoExecute",
+		},
+		Autoscaling: &tinkerbell.Autoscaling{
+			MaxCount: 2,
+			CPU:      "4",
+			Memory:   "8Gi",
+		},
+	}
+}
+
+func TestTemplateNodeHasWorkerPoolLabels(t *testing.T) {
+	t.Parallel()
+
+	n, err := templateNode("mycluster", testWorkerPool())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"testing.io":              "yes",
+		"node.kubernetes.io/node": "",
+		workerPoolLabel:           "foo",
+	}
+
+	for k, v := range expected {
+		if l, ok := n.Labels[k]; !ok || l != v {
+			t.Errorf("Expected label %q with value %q, got %q", k, v, l)
+		}
+	}
+}
+
+func TestTemplateNodeHasWorkerPoolTaints(t *testing.T) {
+	t.Parallel()
+
+	n, err := templateNode("mycluster", testWorkerPool())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []corev1.Taint{
+		{
+			Key:    "dedicated",
+			Value:  "db",
+			Effect: corev1.TaintEffectNoExecute,
+		},
+		{
+			Key:    "nodeType",
+			Value:  "storage",
+			Effect: corev1.TaintEffectNoSchedule,
+		},
+	}
+
+	if !reflect.DeepEqual(n.Spec.Taints, expected) {
+		t.Fatalf("Expected taints %v, got %v", expected, n.Spec.Taints)
+	}
+}
+
+func TestTemplateNodeRejectsTaintWithoutEffect(t *testing.T) {
+	t.Parallel()
+
+	p := testWorkerPool()
+	p.Taints = map[string]string{
+		"nodeType": "storage",
+	}
+
+	if _, err := templateNode("mycluster", p); err == nil {
+		t.Fatal("Expected error when taint has no effect")
+	}
+}
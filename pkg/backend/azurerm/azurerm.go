@@ -0,0 +1,83 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azurerm
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/kinvolk/lokomotive/internal/template"
+)
+
+type azurerm struct {
+	StorageAccountName string `hcl:"storage_account_name"`
+	ContainerName      string `hcl:"container_name"`
+	Key                string `hcl:"key"`
+	ResourceGroupName  string `hcl:"resource_group_name,optional"`
+	SubscriptionID     string `hcl:"subscription_id,optional"`
+	TenantID           string `hcl:"tenant_id,optional"`
+	Environment        string `hcl:"environment,optional"`
+	UseMSI             bool   `hcl:"use_msi,optional"`
+}
+
+const (
+	// Name represents Azure Storage backend name as it should be referenced in function calls
+	// and in configuration.
+	Name = "azurerm"
+)
+
+// LoadConfig loads the configuration for the azurerm backend.
+func (a *azurerm) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	return gohcl.DecodeBody(*configBody, evalContext, a)
+}
+
+// NewConfig returns new Azure Storage backend configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *azurerm {
+	return &azurerm{}
+}
+
+// Render renders the Go template with azurerm backend configuration.
+func (a *azurerm) Render() (string, error) {
+	return template.Render(backendConfigTmpl, a)
+}
+
+// Validate validates the azurerm backend configuration.
+func (a *azurerm) Validate() error {
+	if a.StorageAccountName == "" {
+		return fmt.Errorf("no storage account name specified")
+	}
+
+	if a.ContainerName == "" {
+		return fmt.Errorf("no container name specified")
+	}
+
+	if a.Key == "" {
+		return fmt.Errorf("no key specified")
+	}
+
+	if a.UseMSI && a.SubscriptionID == "" {
+		return fmt.Errorf("no subscription ID specified: subscription ID is required when using managed identity")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azurerm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/azurerm"
+)
+
+func loadConfig(t *testing.T, config string) hcl.Body {
+	t.Helper()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(config), "backend.lokocfg")
+	if diags.HasErrors() {
+		t.Fatalf("Parsing config: %v", diags)
+	}
+
+	return f.Body
+}
+
+func TestRenderUsesManagedIdentity(t *testing.T) {
+	a := azurerm.NewConfig()
+
+	body := loadConfig(t, `
+storage_account_name = "lokomotive"
+container_name       = "tfstate"
+key                  = "mycluster.tfstate"
+subscription_id      = "00000000-0000-0000-0000-000000000000"
+use_msi              = true
+`)
+
+	if diags := a.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+		t.Fatalf("Loading config: %v", diags)
+	}
+
+	if err := a.Validate(); err != nil {
+		t.Fatalf("Valid config should not return error, got: %v", err)
+	}
+
+	out, err := a.Render()
+	if err != nil {
+		t.Fatalf("Rendering: %v", err)
+	}
+
+	for _, expected := range []string{
+		`key                  = "mycluster.tfstate"`,
+		`subscription_id = "00000000-0000-0000-0000-000000000000"`,
+		"use_msi = true",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Rendered backend should contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestValidateFails(t *testing.T) {
+	cases := map[string]string{
+		"without storage account name": `
+container_name = "tfstate"
+key            = "mycluster.tfstate"
+`,
+		"with empty container name": `
+storage_account_name = "lokomotive"
+container_name       = ""
+key                  = "mycluster.tfstate"
+`,
+		"with empty key": `
+storage_account_name = "lokomotive"
+container_name       = "tfstate"
+key                  = ""
+`,
+		"with managed identity but no subscription ID": `
+storage_account_name = "lokomotive"
+container_name       = "tfstate"
+key                  = "mycluster.tfstate"
+use_msi              = true
+`,
+	}
+
+	for name, config := range cases {
+		config := config
+
+		t.Run(name, func(t *testing.T) {
+			a := azurerm.NewConfig()
+
+			body := loadConfig(t, config)
+
+			// Missing required attributes are already reported when loading the configuration.
+			if diags := a.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+				return
+			}
+
+			if err := a.Validate(); err == nil {
+				t.Fatal("Validation should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azurerm
+
+var backendConfigTmpl = `
+backend "azurerm" {
+  storage_account_name = "{{ .StorageAccountName }}"
+  container_name       = "{{ .ContainerName }}"
+  key                  = "{{ .Key }}"
+  {{- if .ResourceGroupName }}
+  resource_group_name = "{{ .ResourceGroupName }}"
+  {{- end }}
+  {{- if .SubscriptionID }}
+  subscription_id = "{{ .SubscriptionID }}"
+  {{- end }}
+  {{- if .TenantID }}
+  tenant_id = "{{ .TenantID }}"
+  {{- end }}
+  {{- if .Environment }}
+  environment = "{{ .Environment }}"
+  {{- end }}
+  {{- if .UseMSI }}
+  use_msi = true
+  {{- end }}
+}
+`
@@ -15,7 +15,12 @@
 package backend
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/hashicorp/hcl/v2"
+	"github.com/mitchellh/go-homedir"
 )
 
 // Backend describes the Terraform state storage location.
@@ -27,3 +32,39 @@ type Backend interface {
 	// Validate validates backend configuration.
 	Validate() error
 }
+
+// ValidateFile checks, that the file referenced by the backend configuration attribute
+// with given name exists and is a regular file. Leading ~ is expanded like Terraform does.
+func ValidateFile(attribute, path string) error {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return fmt.Errorf("expanding %s %q: %w", attribute, path, err)
+	}
+
+	info, err := os.Stat(expandedPath)
+	if err != nil {
+		return fmt.Errorf("checking %s %q: %w", attribute, path, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s %q is not a regular file", attribute, path)
+	}
+
+	return nil
+}
+
+// AbsPath returns the absolute path of the file referenced by the backend configuration.
+// Terraform resolves relative paths against the assets directory, so they must be made
+// absolute before rendering the backend configuration. Leading ~ is expanded.
+func AbsPath(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf("expanding %q: %w", path, err)
+	}
+
+	return filepath.Abs(expandedPath)
+}
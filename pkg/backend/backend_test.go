@@ -0,0 +1,70 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/backend"
+)
+
+func TestValidateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokomotive-backend")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	path := filepath.Join(dir, "credentials.json")
+
+	if err := ioutil.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("Writing file: %v", err)
+	}
+
+	if err := backend.ValidateFile("credentials path", path); err != nil {
+		t.Errorf("Validating existing file should succeed, got: %v", err)
+	}
+
+	if err := backend.ValidateFile("credentials path", filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("Validating non-existing file should fail")
+	}
+
+	if err := backend.ValidateFile("credentials path", dir); err == nil {
+		t.Errorf("Validating directory should fail")
+	}
+}
+
+func TestAbsPath(t *testing.T) {
+	path, err := backend.AbsPath("credentials.json")
+	if err != nil {
+		t.Fatalf("Getting absolute path: %v", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		t.Errorf("Expected absolute path, got %q", path)
+	}
+
+	if path, err := backend.AbsPath(""); err != nil || path != "" {
+		t.Errorf("Expected empty path to stay empty, got %q, %v", path, err)
+	}
+}
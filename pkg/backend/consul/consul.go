@@ -0,0 +1,121 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/kinvolk/lokomotive/internal/template"
+	"github.com/kinvolk/lokomotive/pkg/backend"
+)
+
+type consul struct {
+	Path       string `hcl:"path"`
+	Address    string `hcl:"address,optional"`
+	Scheme     string `hcl:"scheme,optional"`
+	Datacenter string `hcl:"datacenter,optional"`
+	Lock       bool   `hcl:"lock,optional"`
+	CAFile     string `hcl:"ca_file,optional"`
+	CertFile   string `hcl:"cert_file,optional"`
+	KeyFile    string `hcl:"key_file,optional"`
+}
+
+const (
+	// Name represents Consul backend name as it should be referenced in function calls
+	// and in configuration.
+	Name = "consul"
+)
+
+// LoadConfig loads the configuration for the consul backend.
+func (c *consul) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	if diags := gohcl.DecodeBody(*configBody, evalContext, c); diags.HasErrors() {
+		return diags
+	}
+
+	for attribute, path := range map[string]*string{
+		"CA file":   &c.CAFile,
+		"cert file": &c.CertFile,
+		"key file":  &c.KeyFile,
+	} {
+		absPath, err := backend.AbsPath(*path)
+		if err != nil {
+			return hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("Invalid %s", attribute),
+					Detail:   err.Error(),
+				},
+			}
+		}
+
+		*path = absPath
+	}
+
+	return hcl.Diagnostics{}
+}
+
+// NewConfig returns new Consul backend configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *consul {
+	return &consul{
+		Lock: true,
+	}
+}
+
+// Render renders the Go template with consul backend configuration.
+func (c *consul) Render() (string, error) {
+	return template.Render(backendConfigTmpl, c)
+}
+
+// Validate validates the consul backend configuration.
+func (c *consul) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("no path specified")
+	}
+
+	if c.Scheme != "" && c.Scheme != "http" && c.Scheme != "https" {
+		return fmt.Errorf("invalid scheme %q: must be either 'http' or 'https'", c.Scheme)
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert file and key file must be specified together")
+	}
+
+	files := map[string]string{
+		"CA file":   c.CAFile,
+		"cert file": c.CertFile,
+		"key file":  c.KeyFile,
+	}
+
+	for attribute, path := range files {
+		if path == "" {
+			continue
+		}
+
+		if err := backend.ValidateFile(attribute, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
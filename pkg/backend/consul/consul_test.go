@@ -0,0 +1,101 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/consul"
+)
+
+func loadConfig(t *testing.T, config string) hcl.Body {
+	t.Helper()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(config), "backend.lokocfg")
+	if diags.HasErrors() {
+		t.Fatalf("Parsing config: %v", diags)
+	}
+
+	return f.Body
+}
+
+func TestRenderDisablesLocking(t *testing.T) {
+	c := consul.NewConfig()
+
+	body := loadConfig(t, `
+path = "lokomotive/mycluster"
+lock = false
+`)
+
+	if diags := c.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+		t.Fatalf("Loading config: %v", diags)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Valid config should not return error, got: %v", err)
+	}
+
+	out, err := c.Render()
+	if err != nil {
+		t.Fatalf("Rendering: %v", err)
+	}
+
+	if !strings.Contains(out, "lock = false") {
+		t.Fatalf("Rendered backend should disable locking, got:\n%s", out)
+	}
+}
+
+func TestValidateFails(t *testing.T) {
+	cases := map[string]string{
+		"without path": `
+address = "consul.example.com:8500"
+`,
+		"with invalid scheme": `
+path   = "lokomotive/mycluster"
+scheme = "ftp"
+`,
+		"with cert file but no key file": `
+path      = "lokomotive/mycluster"
+cert_file = "/dev/null"
+`,
+		"with non-existing CA file": `
+path    = "lokomotive/mycluster"
+ca_file = "/nonexistent/ca.pem"
+`,
+	}
+
+	for name, config := range cases {
+		config := config
+
+		t.Run(name, func(t *testing.T) {
+			c := consul.NewConfig()
+
+			body := loadConfig(t, config)
+
+			// Missing required attributes are already reported when loading the configuration.
+			if diags := c.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+				return
+			}
+
+			if err := c.Validate(); err == nil {
+				t.Fatal("Validation should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consul
+
+var backendConfigTmpl = `
+backend "consul" {
+  path = "{{ .Path }}"
+  {{- if .Address }}
+  address = "{{ .Address }}"
+  {{- end }}
+  {{- if .Scheme }}
+  scheme = "{{ .Scheme }}"
+  {{- end }}
+  {{- if .Datacenter }}
+  datacenter = "{{ .Datacenter }}"
+  {{- end }}
+  {{- if not .Lock }}
+  lock = false
+  {{- end }}
+  {{- if .CAFile }}
+  ca_file = "{{ .CAFile }}"
+  {{- end }}
+  {{- if .CertFile }}
+  cert_file = "{{ .CertFile }}"
+  key_file  = "{{ .KeyFile }}"
+  {{- end }}
+}
+`
@@ -0,0 +1,88 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/kinvolk/lokomotive/internal/template"
+	"github.com/kinvolk/lokomotive/pkg/backend"
+)
+
+type gcs struct {
+	Bucket          string `hcl:"bucket"`
+	Prefix          string `hcl:"prefix,optional"`
+	CredentialsPath string `hcl:"credentials_path,optional"`
+}
+
+const (
+	// Name represents Google Cloud Storage backend name as it should be referenced in function calls
+	// and in configuration.
+	Name = "gcs"
+)
+
+// LoadConfig loads the configuration for the gcs backend.
+func (g *gcs) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	if diags := gohcl.DecodeBody(*configBody, evalContext, g); diags.HasErrors() {
+		return diags
+	}
+
+	path, err := backend.AbsPath(g.CredentialsPath)
+	if err != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid credentials path",
+				Detail:   err.Error(),
+			},
+		}
+	}
+
+	g.CredentialsPath = path
+
+	return hcl.Diagnostics{}
+}
+
+// NewConfig returns new Google Cloud Storage backend configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *gcs {
+	return &gcs{}
+}
+
+// Render renders the Go template with gcs backend configuration.
+func (g *gcs) Render() (string, error) {
+	return template.Render(backendConfigTmpl, g)
+}
+
+// Validate validates the gcs backend configuration.
+func (g *gcs) Validate() error {
+	if g.Bucket == "" {
+		return fmt.Errorf("no bucket specified")
+	}
+
+	if g.CredentialsPath != "" {
+		return backend.ValidateFile("credentials path", g.CredentialsPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/gcs"
+)
+
+func loadConfig(t *testing.T, config string) hcl.Body {
+	t.Helper()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(config), "backend.lokocfg")
+	if diags.HasErrors() {
+		t.Fatalf("Parsing config: %v", diags)
+	}
+
+	return f.Body
+}
+
+func TestRenderUsesAbsoluteCredentialsPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokomotive-gcs")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "credentials.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatalf("Writing credentials file: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getting working directory: %v", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Changing working directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Logf("Restoring working directory: %v", err)
+		}
+	})
+
+	g := gcs.NewConfig()
+
+	body := loadConfig(t, `
+bucket           = "lokomotive"
+prefix           = "mycluster"
+credentials_path = "credentials.json"
+`)
+
+	if diags := g.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+		t.Fatalf("Loading config: %v", diags)
+	}
+
+	if err := g.Validate(); err != nil {
+		t.Fatalf("Valid config should not return error, got: %v", err)
+	}
+
+	out, err := g.Render()
+	if err != nil {
+		t.Fatalf("Rendering: %v", err)
+	}
+
+	// Temporary directory may be behind a symlink, so compare with the working directory.
+	absDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getting working directory: %v", err)
+	}
+
+	expected := `credentials = "` + filepath.Join(absDir, "credentials.json") + `"`
+
+	if !strings.Contains(out, expected) {
+		t.Fatalf("Rendered backend should contain %q, got:\n%s", expected, out)
+	}
+
+	if !strings.Contains(out, `prefix = "mycluster"`) {
+		t.Fatalf("Rendered backend should contain prefix, got:\n%s", out)
+	}
+}
+
+func TestValidateFails(t *testing.T) {
+	cases := map[string]string{
+		"without bucket": `
+prefix = "mycluster"
+`,
+		"with non-existing credentials file": `
+bucket           = "lokomotive"
+credentials_path = "/nonexistent/credentials.json"
+`,
+	}
+
+	for name, config := range cases {
+		config := config
+
+		t.Run(name, func(t *testing.T) {
+			g := gcs.NewConfig()
+
+			body := loadConfig(t, config)
+
+			// Missing required attributes are already reported when loading the configuration.
+			if diags := g.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+				return
+			}
+
+			if err := g.Validate(); err == nil {
+				t.Fatal("Validation should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/kinvolk/lokomotive/internal/template"
+)
+
+type pg struct {
+	ConnStr            string `hcl:"conn_str,optional"`
+	SchemaName         string `hcl:"schema_name,optional"`
+	SkipSchemaCreation bool   `hcl:"skip_schema_creation,optional"`
+}
+
+const (
+	// Name represents PostgreSQL backend name as it should be referenced in function calls
+	// and in configuration.
+	Name = "pg"
+
+	// connStrEnvVariable is the environment variable Terraform reads the connection string from.
+	connStrEnvVariable = "PG_CONN_STR"
+)
+
+// LoadConfig loads the configuration for the pg backend.
+func (p *pg) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	return gohcl.DecodeBody(*configBody, evalContext, p)
+}
+
+// NewConfig returns new PostgreSQL backend configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *pg {
+	return &pg{}
+}
+
+// Render renders the Go template with pg backend configuration. The connection string
+// usually contains the password, so it is not rendered and passed using Env() instead.
+func (p *pg) Render() (string, error) {
+	return template.Render(backendConfigTmpl, p)
+}
+
+// Env returns environment variables with configured connection string for Terraform.
+func (p *pg) Env() map[string]string {
+	if p.ConnStr == "" {
+		return nil
+	}
+
+	return map[string]string{
+		connStrEnvVariable: p.ConnStr,
+	}
+}
+
+// Validate validates the pg backend configuration.
+func (p *pg) Validate() error {
+	if p.ConnStr == "" && os.Getenv(connStrEnvVariable) == "" {
+		return fmt.Errorf("no connection string specified, set conn_str or %s environment variable",
+			connStrEnvVariable)
+	}
+
+	return nil
+}
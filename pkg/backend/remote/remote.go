@@ -0,0 +1,76 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/kinvolk/lokomotive/internal/template"
+)
+
+type remote struct {
+	Hostname     string `hcl:"hostname,optional"`
+	Organization string `hcl:"organization"`
+	Workspace    string `hcl:"workspace"`
+}
+
+const (
+	// Name represents Terraform Cloud backend name as it should be referenced in function calls
+	// and in configuration.
+	Name = "remote"
+)
+
+// LoadConfig loads the configuration for the remote backend.
+func (r *remote) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	return gohcl.DecodeBody(*configBody, evalContext, r)
+}
+
+// NewConfig returns new Terraform Cloud backend configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *remote {
+	return &remote{
+		Hostname: "app.terraform.io",
+	}
+}
+
+// Render renders the Go template with remote backend configuration.
+func (r *remote) Render() (string, error) {
+	return template.Render(backendConfigTmpl, r)
+}
+
+// Validate validates the remote backend configuration.
+func (r *remote) Validate() error {
+	if r.Hostname == "" {
+		return fmt.Errorf("no hostname specified")
+	}
+
+	if r.Organization == "" {
+		return fmt.Errorf("no organization specified")
+	}
+
+	if r.Workspace == "" {
+		return fmt.Errorf("no workspace specified")
+	}
+
+	return nil
+}
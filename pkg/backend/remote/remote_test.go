@@ -0,0 +1,104 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/remote"
+)
+
+func loadConfig(t *testing.T, config string) hcl.Body {
+	t.Helper()
+
+	f, diags := hclparse.NewParser().ParseHCL([]byte(config), "backend.lokocfg")
+	if diags.HasErrors() {
+		t.Fatalf("Parsing config: %v", diags)
+	}
+
+	return f.Body
+}
+
+func TestRenderUsesDefaultHostname(t *testing.T) {
+	r := remote.NewConfig()
+
+	body := loadConfig(t, `
+organization = "kinvolk"
+workspace    = "mycluster"
+`)
+
+	if diags := r.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+		t.Fatalf("Loading config: %v", diags)
+	}
+
+	if err := r.Validate(); err != nil {
+		t.Fatalf("Valid config should not return error, got: %v", err)
+	}
+
+	out, err := r.Render()
+	if err != nil {
+		t.Fatalf("Rendering: %v", err)
+	}
+
+	for _, expected := range []string{
+		`hostname     = "app.terraform.io"`,
+		`organization = "kinvolk"`,
+		`name = "mycluster"`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Rendered backend should contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestValidateFails(t *testing.T) {
+	cases := map[string]string{
+		"without organization": `
+workspace = "mycluster"
+`,
+		"with empty hostname": `
+hostname     = ""
+organization = "kinvolk"
+workspace    = "mycluster"
+`,
+		"with empty workspace": `
+organization = "kinvolk"
+workspace    = ""
+`,
+	}
+
+	for name, config := range cases {
+		config := config
+
+		t.Run(name, func(t *testing.T) {
+			r := remote.NewConfig()
+
+			body := loadConfig(t, config)
+
+			// Missing required attributes are already reported when loading the configuration.
+			if diags := r.LoadConfig(&body, &hcl.EvalContext{}); diags.HasErrors() {
+				return
+			}
+
+			if err := r.Validate(); err == nil {
+				t.Fatal("Validation should fail")
+			}
+		})
+	}
+}
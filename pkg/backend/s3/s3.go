@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/hcl/v2/gohcl"
 
 	"github.com/kinvolk/lokomotive/internal/template"
+	"github.com/kinvolk/lokomotive/pkg/backend"
 )
 
 type s3 struct {
@@ -68,6 +69,12 @@ func (s *s3) Validate() error {
 		return fmt.Errorf("no key specified")
 	}
 
+	if s.AWSCredsPath != "" {
+		if err := backend.ValidateFile("AWS credentials path", s.AWSCredsPath); err != nil {
+			return err
+		}
+	}
+
 	if s.AWSCredsPath == "" && os.Getenv("AWS_SHARED_CREDENTIALS_FILE") == "" {
 		if s.Region == "" && os.Getenv("AWS_DEFAULT_REGION") == "" {
 			return fmt.Errorf("no region specified: use Region field in backend configuration or " +
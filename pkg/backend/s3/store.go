@@ -0,0 +1,119 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// URLScheme is the scheme of URLs pointing to objects stored in S3.
+const URLScheme = "s3"
+
+// Store reads and writes objects in the bucket used for storing Terraform state.
+type Store struct {
+	bucket string
+	prefix string
+	sess   *session.Session
+}
+
+// Store returns the object store using the bucket and credentials of the backend.
+// Objects are stored next to the Terraform state, under given directory.
+func (s *s3) Store(dir string) (*Store, error) {
+	config := aws.NewConfig()
+
+	if s.Region != "" {
+		config = config.WithRegion(s.Region)
+	}
+
+	options := session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+
+	if s.AWSCredsPath != "" {
+		options.SharedConfigFiles = []string{s.AWSCredsPath}
+	}
+
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", err)
+	}
+
+	return &Store{
+		bucket: s.Bucket,
+		prefix: path.Join(path.Dir(s.Key), dir),
+		sess:   sess,
+	}, nil
+}
+
+// Upload uploads the content of given reader as an object with a given name, encrypted
+// on the server side, and returns it's URL.
+func (st *Store) Upload(name string, r io.Reader) (string, error) {
+	key := path.Join(st.prefix, name)
+
+	if _, err := s3manager.NewUploader(st.sess).Upload(&s3manager.UploadInput{
+		Bucket: aws.String(st.bucket),
+		Key:    aws.String(key),
+		Body:   r,
+		// Objects like etcd snapshots contain cluster secrets, so never store them unencrypted.
+		ServerSideEncryption: aws.String(awss3.ServerSideEncryptionAes256),
+	}); err != nil {
+		return "", fmt.Errorf("uploading object %q to bucket %q: %w", key, st.bucket, err)
+	}
+
+	return (&url.URL{Scheme: URLScheme, Host: st.bucket, Path: "/" + key}).String(), nil
+}
+
+// Download writes the object pointed by given S3 URL to given writer.
+func (st *Store) Download(objectURL string, w io.WriterAt) error {
+	bucket, key, err := ParseURL(objectURL)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s3manager.NewDownloader(st.sess).Download(w, &awss3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("downloading object %q from bucket %q: %w", key, bucket, err)
+	}
+
+	return nil
+}
+
+// ParseURL returns the bucket and the key of the object pointed by given S3 URL.
+func ParseURL(objectURL string) (string, string, error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing URL %q: %w", objectURL, err)
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+
+	if u.Scheme != URLScheme || u.Host == "" || key == "" {
+		return "", "", fmt.Errorf("URL %q is not in format %s://<bucket>/<key>", objectURL, URLScheme)
+	}
+
+	return u.Host, key, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3_test
+
+import (
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/backend/s3"
+)
+
+func TestParseURL(t *testing.T) {
+	bucket, key, err := s3.ParseURL("s3://foo/bar/etcd-snapshots/etcd.db")
+	if err != nil {
+		t.Fatalf("Parsing valid URL should succeed, got: %v", err)
+	}
+
+	if bucket != "foo" || key != "bar/etcd-snapshots/etcd.db" {
+		t.Fatalf("Unexpected bucket %q and key %q", bucket, key)
+	}
+}
+
+func TestParseURLInvalid(t *testing.T) {
+	for _, u := range []string{"https://foo/bar", "s3://foo", "s3:///bar", "/tmp/etcd.db"} {
+		if _, _, err := s3.ParseURL(u); err == nil {
+			t.Errorf("Parsing invalid URL %q should fail", u)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterautoscaler
+
+import (
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	awsplatform "github.com/kinvolk/lokomotive/pkg/platform/aws"
+)
+
+type awsConfiguration struct {
+	// required parameters
+	Region string `hcl:"region,optional"`
+}
+
+// awsPlatform is implemented by the AWS platform configuration.
+type awsPlatform interface {
+	Autoscaling() awsplatform.AutoscalingConfig
+}
+
+// SetPlatform implements components.ComponentWithPlatform interface. On AWS, cluster name
+// and region default to the values from the platform configuration.
+func (c *component) SetPlatform(p platform.Platform) {
+	ap, ok := p.(awsPlatform)
+	if !ok {
+		return
+	}
+
+	ac := ap.Autoscaling()
+
+	c.Provider = "aws"
+	c.ClusterName = ac.ClusterName
+	c.Packet = nil
+	c.awsPlatform = &ac
+}
+
+// validateAWS validates AWS provider configuration. Autoscaling groups are discovered by
+// cluster-autoscaler using tags and it authenticates using the instance profile of controller
+// nodes, both set up by the AWS platform for worker pools with autoscaling enabled.
+func (c *component) validateAWS(diagnostics hcl.Diagnostics) hcl.Diagnostics {
+	if c.AWS == nil {
+		c.AWS = &awsConfiguration{}
+	}
+
+	if c.AWS.Region == "" && c.awsPlatform != nil {
+		c.AWS.Region = c.awsPlatform.Region
+	}
+
+	if c.AWS.Region == "" {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "'region' must be set",
+			Detail:   "When using AWS provider, 'region' must be set but it was not found",
+		})
+	}
+
+	if c.awsPlatform != nil && len(c.awsPlatform.WorkerPools) == 0 {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "no worker pools to autoscale",
+			Detail: "When using AWS provider, 'min_count' or 'max_count' must be set for at least one " +
+				"worker pool in the cluster configuration",
+		})
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterautoscaler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/kinvolk/lokomotive/pkg/components/internal/testutil"
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	awsplatform "github.com/kinvolk/lokomotive/pkg/platform/aws"
+)
+
+type fakeAWSPlatform struct {
+	platform.Platform
+
+	workerPools []string
+}
+
+func (f *fakeAWSPlatform) Autoscaling() awsplatform.AutoscalingConfig {
+	return awsplatform.AutoscalingConfig{
+		ClusterName: "foo",
+		Region:      "eu-west-1",
+		WorkerPools: f.workerPools,
+	}
+}
+
+func loadAWSConfig(t *testing.T, config string, p platform.Platform) (*component, hcl.Diagnostics) {
+	t.Helper()
+
+	c := NewConfig()
+
+	if p != nil {
+		c.SetPlatform(p)
+	}
+
+	body, diagnostics := util.GetComponentBody(config, Name)
+	if diagnostics != nil {
+		t.Fatalf("Error getting component body: %v", diagnostics)
+	}
+
+	return c, c.LoadConfig(body, &hcl.EvalContext{})
+}
+
+func TestAWSDefaultsFromPlatform(t *testing.T) {
+	config := `
+component "cluster-autoscaler" {}
+`
+
+	c, diagnostics := loadAWSConfig(t, config, &fakeAWSPlatform{workerPools: []string{"bar"}})
+	if diagnostics.HasErrors() {
+		t.Fatalf("Valid config should not return error, got: %v", diagnostics)
+	}
+
+	if c.Provider != "aws" || c.ClusterName != "foo" || c.AWS.Region != "eu-west-1" {
+		t.Fatalf("Provider, cluster name and region should default to platform values, got %q, %q, %q",
+			c.Provider, c.ClusterName, c.AWS.Region)
+	}
+}
+
+func TestAWSConfigIsInvalidWhen(t *testing.T) {
+	cases := map[string]struct {
+		config   string
+		platform platform.Platform
+	}{
+		"no_worker_pool_is_autoscaled": {
+			config: `
+component "cluster-autoscaler" {}
+`,
+			platform: &fakeAWSPlatform{},
+		},
+		"region_is_missing_without_platform": {
+			config: `
+component "cluster-autoscaler" {
+  provider     = "aws"
+  cluster_name = "foo"
+}
+`,
+		},
+		"cluster_name_is_missing_without_platform": {
+			config: `
+component "cluster-autoscaler" {
+  provider = "aws"
+
+  aws {
+    region = "eu-west-1"
+  }
+}
+`,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+
+		t.Run(n, func(t *testing.T) {
+			if _, diagnostics := loadAWSConfig(t, tc.config, tc.platform); !diagnostics.HasErrors() {
+				t.Fatalf("Invalid config should return error")
+			}
+		})
+	}
+}
+
+func TestRenderAWS(t *testing.T) {
+	config := `
+component "cluster-autoscaler" {
+  provider     = "aws"
+  cluster_name = "foo"
+
+  aws {
+    region = "eu-west-1"
+  }
+}
+`
+
+	m := testutil.RenderManifests(t, NewConfig(), Name, config)
+
+	deployment := testutil.ConfigFromMap(t, m, k8sutil.ObjectMetadata{
+		Version: "apps/v1", Kind: "Deployment", Name: "cluster-autoscaler-aws-cluster-autoscaler",
+	})
+
+	testutil.MatchJSONPathStringValue(t, deployment, "{.spec.template.spec.containers[0].command[3]}",
+		"--node-group-auto-discovery=asg:tag=k8s.io/cluster-autoscaler/enabled,k8s.io/cluster-autoscaler/foo")
+	testutil.MatchJSONPathStringValue(t, deployment,
+		"{.spec.template.spec.nodeSelector.node\\.kubernetes\\.io/controller}", "true")
+	testutil.MatchJSONPathJSONValue(t, deployment, "{.spec.template.spec.containers[0].env}",
+		`[{"name":"AWS_REGION","value":"eu-west-1"}]`)
+
+	for _, manifest := range m {
+		if strings.Contains(manifest, "kind: Secret") {
+			t.Fatalf("No credentials should be stored in the cluster, got:\n%s", manifest)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/kinvolk/lokomotive/pkg/components"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	awsplatform "github.com/kinvolk/lokomotive/pkg/platform/aws"
 )
 
 const (
@@ -49,8 +50,14 @@ rbac:
   create: true
 cloudConfigPath: /config
 image:
+{{- if .ExternalGRPC }}
+  repository: k8s.gcr.io/autoscaling/cluster-autoscaler
+  tag: v1.24.0
+{{- else }}
   repository: quay.io/kinvolk/cluster-autoscaler
   tag: cluster-autoscaler-1.22.0-1-g5cd43fe7a
+{{- end }}
+{{- if .Packet }}
 packetClusterName: {{ .ClusterName }}
 packetAuthToken: {{ .Packet.AuthToken }}
 packetCloudInit: {{ .Packet.UserData }}
@@ -64,6 +71,22 @@ autoscalingGroups:
 - name: {{ .WorkerPool }}
   maxSize: {{ .MaxWorkers }}
   minSize: {{ .MinWorkers }}
+{{- end }}
+{{- if .AWS }}
+awsRegion: {{ .AWS.Region }}
+autoDiscovery:
+  clusterName: {{ .ClusterName }}
+# Controllers have the instance profile allowing to scale worker pools.
+nodeSelector:
+  node.kubernetes.io/controller: "true"
+{{- end }}
+{{- if .ExternalGRPC }}
+externalgrpc:
+  address: {{ printf "%q" .ExternalGRPC.Address }}
+  caCert: {{ printf "%q" .ExternalGRPC.CACert }}
+  clientCert: {{ printf "%q" .ExternalGRPC.ClientCert }}
+  clientKey: {{ printf "%q" .ExternalGRPC.ClientKey }}
+{{- end }}
 
 extraArgs:
   scale-down-unneeded-time: {{ .ScaleDownUnneededTime }}
@@ -106,6 +129,15 @@ type component struct {
 
 	// Packet-specific parameters
 	Packet *packetConfiguration `hcl:"packet,block"`
+
+	// AWS-specific parameters
+	AWS *awsConfiguration `hcl:"aws,block"`
+
+	// External gRPC provider specific parameters
+	ExternalGRPC *externalGRPCConfiguration `hcl:"externalgrpc,block"`
+
+	// awsPlatform holds the AWS platform configuration, if the cluster runs on AWS.
+	awsPlatform *awsplatform.AutoscalingConfig
 }
 
 type packetConfiguration struct {
@@ -296,9 +328,15 @@ func (c *component) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContex
 	switch c.Provider {
 	case "packet":
 		diagnostics = c.validatePacket(diagnostics)
+	case "aws":
+		c.Packet = nil
+		diagnostics = c.validateAWS(diagnostics)
+	case "externalgrpc":
+		c.Packet = nil
+		diagnostics = c.validateExternalGRPC(diagnostics)
 	default:
 		// Slice can't be constant, so just use a variable
-		supportedProviders := []string{"packet"}
+		supportedProviders := []string{"packet", "aws", "externalgrpc"}
 		diagnostics = append(diagnostics, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Make sure to set provider to one of supported values",
@@ -306,7 +344,7 @@ func (c *component) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContex
 		})
 	}
 
-	if c.WorkerPool == "" {
+	if c.Provider == "packet" && c.WorkerPool == "" {
 		diagnostics = append(diagnostics, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "'worker_pool' must be set",
@@ -314,7 +352,8 @@ func (c *component) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContex
 		})
 	}
 
-	if c.ClusterName == "" {
+	// Node groups of the external gRPC provider are not tied to a cluster name.
+	if c.Provider != "externalgrpc" && c.ClusterName == "" {
 		diagnostics = append(diagnostics, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "'cluster_name' must be set",
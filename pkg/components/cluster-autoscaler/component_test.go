@@ -19,7 +19,9 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 
+	"github.com/kinvolk/lokomotive/pkg/components/internal/testutil"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 )
 
 func TestEmptyConfig(t *testing.T) {
@@ -75,3 +77,89 @@ func TestRender(t *testing.T) {
 		t.Fatalf("Valid config should not return error, got: %s", diagnostics)
 	}
 }
+
+func TestRenderExternalGRPC(t *testing.T) {
+	t.Parallel()
+
+	config := `
+	component "cluster-autoscaler" {
+		provider = "externalgrpc"
+
+		externalgrpc {
+			address     = "192.168.1.10:8086"
+			ca_cert     = "ca"
+			client_cert = "cert"
+			client_key  = "key"
+		}
+	}
+	`
+
+	m := testutil.RenderManifests(t, NewConfig(), Name, config)
+
+	deployment := testutil.ConfigFromMap(t, m, k8sutil.ObjectMetadata{
+		Version: "apps/v1", Kind: "Deployment", Name: "cluster-autoscaler-externalgrpc-cluster-autoscaler",
+	})
+
+	testutil.MatchJSONPathStringValue(t, deployment, "{.spec.template.spec.containers[0].command[1]}",
+		"--cloud-provider=externalgrpc")
+	testutil.MatchJSONPathStringValue(t, deployment, "{.spec.template.spec.volumes[0].secret.secretName}",
+		"cluster-autoscaler-externalgrpc-cluster-autoscaler")
+
+	secret := testutil.ConfigFromMap(t, m, k8sutil.ObjectMetadata{
+		Version: "v1", Kind: "Secret", Name: "cluster-autoscaler-externalgrpc-cluster-autoscaler",
+	})
+
+	expectedCloudConfig := `address: "192.168.1.10:8086"
+cacert: /config/ca.crt
+cert: /config/tls.crt
+key: /config/tls.key`
+
+	testutil.MatchJSONPathStringValue(t, secret, "{.stringData.cloud-config}", expectedCloudConfig)
+	testutil.MatchJSONPathStringValue(t, secret, "{.stringData.tls\\.key}", "key")
+}
+
+func TestExternalGRPCConfigIsInvalidWhen(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"externalgrpc block is missing": `
+		component "cluster-autoscaler" {
+			provider = "externalgrpc"
+		}
+		`,
+		"address is missing": `
+		component "cluster-autoscaler" {
+			provider = "externalgrpc"
+
+			externalgrpc {}
+		}
+		`,
+		"client key is missing": `
+		component "cluster-autoscaler" {
+			provider = "externalgrpc"
+
+			externalgrpc {
+				address     = "192.168.1.10:8086"
+				client_cert = "cert"
+			}
+		}
+		`,
+	}
+
+	for name, config := range cases {
+		config := config
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body, diagnostics := util.GetComponentBody(config, Name)
+			if diagnostics != nil {
+				t.Fatalf("Error getting component body: %v", diagnostics)
+			}
+
+			if diagnostics := NewConfig().LoadConfig(body, &hcl.EvalContext{}); !diagnostics.HasErrors() {
+				t.Fatal("Config should be invalid")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterautoscaler
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// externalGRPCConfiguration configures connection to the external gRPC cloud provider,
+// for example the one served by 'lokoctl autoscaler-provider'.
+type externalGRPCConfiguration struct {
+	// required parameters
+	Address string `hcl:"address,optional"`
+
+	// optional parameters
+	CACert     string `hcl:"ca_cert,optional"`
+	ClientCert string `hcl:"client_cert,optional"`
+	ClientKey  string `hcl:"client_key,optional"`
+}
+
+func (c *component) validateExternalGRPC(diagnostics hcl.Diagnostics) hcl.Diagnostics {
+	if c.ExternalGRPC == nil {
+		c.ExternalGRPC = &externalGRPCConfiguration{}
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "'externalgrpc' block must exist",
+			Detail:   "When using externalgrpc provider, 'externalgrpc' block must exist",
+		})
+	}
+
+	if c.ExternalGRPC.Address == "" {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "'address' must be set",
+			Detail:   "When using externalgrpc provider, 'address' must be set but it was not found",
+		})
+	}
+
+	if (c.ExternalGRPC.ClientCert == "") != (c.ExternalGRPC.ClientKey == "") {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "'client_cert' and 'client_key' must be set together",
+			Detail:   "When using externalgrpc provider, either both or none of 'client_cert' and 'client_key' must be set",
+		})
+	}
+
+	return diagnostics
+}
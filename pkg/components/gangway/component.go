@@ -22,6 +22,8 @@ import (
 
 	internaltemplate "github.com/kinvolk/lokomotive/internal/template"
 	"github.com/kinvolk/lokomotive/pkg/components"
+	certmanager "github.com/kinvolk/lokomotive/pkg/components/cert-manager"
+	"github.com/kinvolk/lokomotive/pkg/components/contour"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 )
@@ -85,10 +87,17 @@ func (c *component) RenderManifests() (map[string]string, error) {
 }
 
 func (c *component) Metadata() components.Metadata {
-	return components.Metadata{
+	m := components.Metadata{
 		Name: Name,
 		Namespace: k8sutil.Namespace{
 			Name: Name,
 		},
+		ConditionalDependencies: []string{certmanager.Name, contour.Name},
 	}
+
+	if c.IngressHost != "" {
+		m.Dependencies = []string{certmanager.Name, contour.Name}
+	}
+
+	return m
 }
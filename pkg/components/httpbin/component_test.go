@@ -76,3 +76,30 @@ component "httpbin" {
 		}
 	}
 }
+
+func TestMetadataDependencies(t *testing.T) {
+	if d := httpbin.NewConfig().Metadata().Dependencies; len(d) != 0 {
+		t.Fatalf("Component without ingress should have no dependencies, got: %v", d)
+	}
+
+	hcl := `
+component "httpbin" {
+	ingress_host = "foo"
+}
+`
+
+	b, d := util.GetComponentBody(hcl, name)
+	if d != nil {
+		t.Fatalf("Error getting component body: %v", d)
+	}
+
+	c := httpbin.NewConfig()
+
+	if d := c.LoadConfig(b, nil); d.HasErrors() {
+		t.Fatalf("Valid config should not return error, got: %s", d)
+	}
+
+	if d := c.Metadata().Dependencies; len(d) != 2 {
+		t.Fatalf("Component with ingress should depend on cert-manager and contour, got: %v", d)
+	}
+}
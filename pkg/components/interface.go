@@ -16,6 +16,8 @@ package components
 
 import (
 	"github.com/hashicorp/hcl/v2"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
 )
 
 // Component represents functionality each Lokomotive component should implement.
@@ -29,3 +31,10 @@ type Component interface {
 	// Metadata returns component metadata.
 	Metadata() Metadata
 }
+
+// ComponentWithPlatform is implemented by components, which derive their default configuration
+// from the cluster platform configuration. Implementing this interface is optional for components.
+type ComponentWithPlatform interface {
+	// SetPlatform is called with the loaded platform configuration before LoadConfig.
+	SetPlatform(platform.Platform)
+}
@@ -24,6 +24,15 @@ type Metadata struct {
 	Name      string
 	Namespace k8sutil.Namespace
 	Helm      HelmMetadata
+	// Dependencies is a list of names of components, which must be installed before
+	// this component, when they are applied together. A component can't be deleted
+	// while other installed components depend on it. Components exposed through an Ingress
+	// depend on cert-manager and contour, which issue the certificates and route the traffic.
+	Dependencies []string
+	// ConditionalDependencies is a list of names of components, which this component depends
+	// on only with some configuration. When the configuration is not known, e.g. for an installed
+	// component removed from the configuration, they are treated as dependencies.
+	ConditionalDependencies []string
 }
 
 // HelmMetadata stores Helm-related information about a component that is needed when managing component using Helm.
@@ -22,6 +22,7 @@ import (
 
 	"github.com/kinvolk/lokomotive/internal/template"
 	"github.com/kinvolk/lokomotive/pkg/components"
+	openebsoperator "github.com/kinvolk/lokomotive/pkg/components/openebs-operator"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 )
@@ -142,5 +143,6 @@ func (c *component) Metadata() components.Metadata {
 		Namespace: k8sutil.Namespace{
 			Name: "openebs",
 		},
+		Dependencies: []string{openebsoperator.Name},
 	}
 }
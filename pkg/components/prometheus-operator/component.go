@@ -23,6 +23,8 @@ import (
 
 	"github.com/kinvolk/lokomotive/internal/template"
 	"github.com/kinvolk/lokomotive/pkg/components"
+	certmanager "github.com/kinvolk/lokomotive/pkg/components/cert-manager"
+	"github.com/kinvolk/lokomotive/pkg/components/contour"
 	"github.com/kinvolk/lokomotive/pkg/components/types"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
@@ -256,7 +258,7 @@ func (c *component) RenderManifests() (map[string]string, error) {
 }
 
 func (c *component) Metadata() components.Metadata {
-	return components.Metadata{
+	m := components.Metadata{
 		Name: Name,
 		Namespace: k8sutil.Namespace{
 			Name: c.Namespace,
@@ -271,5 +273,12 @@ func (c *component) Metadata() components.Metadata {
 			// all deployments included in the component, including the webhook, become ready.
 			Wait: true,
 		},
+		ConditionalDependencies: []string{certmanager.Name, contour.Name},
 	}
+
+	if (c.Grafana != nil && c.Grafana.Ingress != nil) || (c.Prometheus != nil && c.Prometheus.Ingress != nil) {
+		m.Dependencies = []string{certmanager.Name, contour.Name}
+	}
+
+	return m
 }
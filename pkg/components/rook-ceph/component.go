@@ -22,6 +22,7 @@ import (
 
 	"github.com/kinvolk/lokomotive/internal/template"
 	"github.com/kinvolk/lokomotive/pkg/components"
+	"github.com/kinvolk/lokomotive/pkg/components/rook"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
 )
@@ -170,5 +171,7 @@ func (c *component) Metadata() components.Metadata {
 		Namespace: k8sutil.Namespace{
 			Name: c.Namespace,
 		},
+		// Ceph cluster is managed by the rook operator.
+		Dependencies: []string{rook.Name},
 	}
 }
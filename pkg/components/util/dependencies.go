@@ -0,0 +1,159 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kinvolk/lokomotive/pkg/components"
+)
+
+// InstallOrder groups given components into batches, which should be installed one after
+// another. Components within a single batch do not depend on each other, so they can be
+// installed concurrently. Dependencies on components not included in the list are ignored.
+//
+// Components with Helm.Wait set are treated as dependencies of all components listed after them,
+// as other components may require them to be ready, unless it would create a dependency cycle.
+//
+// If declared dependencies form a cycle, error is returned.
+func InstallOrder(comps []components.Component) ([][]components.Component, error) {
+	index := map[string]int{}
+
+	for i, c := range comps {
+		index[c.Metadata().Name] = i
+	}
+
+	// deps[i] holds the indices of components which component i depends on.
+	deps := make([]map[int]bool, len(comps))
+
+	for i, c := range comps {
+		deps[i] = map[int]bool{}
+
+		for _, d := range c.Metadata().Dependencies {
+			if j, ok := index[d]; ok && j != i {
+				deps[i][j] = true
+			}
+		}
+	}
+
+	for j, w := range comps {
+		if !w.Metadata().Helm.Wait {
+			continue
+		}
+
+		for i := j + 1; i < len(comps); i++ {
+			if !dependsOn(deps, j, i) {
+				deps[i][j] = true
+			}
+		}
+	}
+
+	batches := [][]components.Component{}
+	installed := map[int]bool{}
+
+	for len(installed) < len(comps) {
+		batch := []int{}
+
+		for i := range comps {
+			if !installed[i] && allInstalled(deps[i], installed) {
+				batch = append(batch, i)
+			}
+		}
+
+		if len(batch) == 0 {
+			return nil, fmt.Errorf("components have cyclic dependencies: %s", remaining(comps, installed))
+		}
+
+		b := []components.Component{}
+
+		for _, i := range batch {
+			installed[i] = true
+
+			b = append(b, comps[i])
+		}
+
+		batches = append(batches, b)
+	}
+
+	return batches, nil
+}
+
+// dependsOn returns true if component from, directly or indirectly, depends on component to.
+func dependsOn(deps []map[int]bool, from, to int) bool {
+	visited := map[int]bool{}
+	queue := []int{from}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		for d := range deps[c] {
+			if d == to {
+				return true
+			}
+
+			if !visited[d] {
+				visited[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	return false
+}
+
+func allInstalled(deps map[int]bool, installed map[int]bool) bool {
+	for d := range deps {
+		if !installed[d] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func remaining(comps []components.Component, installed map[int]bool) string {
+	names := []string{}
+
+	for i, c := range comps {
+		if !installed[i] {
+			names = append(names, c.Metadata().Name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Dependents returns sorted names of given components, which declare a dependency
+// on component with the given name.
+func Dependents(name string, comps []components.Component) []string {
+	dependents := []string{}
+
+	for _, c := range comps {
+		for _, d := range c.Metadata().Dependencies {
+			if d == name {
+				dependents = append(dependents, c.Metadata().Name)
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(dependents)
+
+	return dependents
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/kinvolk/lokomotive/pkg/components"
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+)
+
+type fakeComponent struct {
+	name         string
+	wait         bool
+	dependencies []string
+}
+
+func (f fakeComponent) LoadConfig(*hcl.Body, *hcl.EvalContext) hcl.Diagnostics {
+	return nil
+}
+
+func (f fakeComponent) RenderManifests() (map[string]string, error) {
+	return nil, nil
+}
+
+func (f fakeComponent) Metadata() components.Metadata {
+	return components.Metadata{
+		Name:         f.name,
+		Helm:         components.HelmMetadata{Wait: f.wait},
+		Dependencies: f.dependencies,
+	}
+}
+
+func batchNames(batches [][]components.Component) [][]string {
+	names := [][]string{}
+
+	for _, b := range batches {
+		n := []string{}
+
+		for _, c := range b {
+			n = append(n, c.Metadata().Name)
+		}
+
+		names = append(names, n)
+	}
+
+	return names
+}
+
+func TestInstallOrder(t *testing.T) {
+	cases := map[string]struct {
+		components []components.Component
+		expected   [][]string
+	}{
+		"independent_components": {
+			components: []components.Component{
+				fakeComponent{name: "a"},
+				fakeComponent{name: "b"},
+			},
+			expected: [][]string{{"a", "b"}},
+		},
+		"dependencies_listed_after_dependent": {
+			components: []components.Component{
+				fakeComponent{name: "dex", dependencies: []string{"cert-manager", "contour"}},
+				fakeComponent{name: "contour"},
+				fakeComponent{name: "cert-manager"},
+			},
+			expected: [][]string{{"contour", "cert-manager"}, {"dex"}},
+		},
+		"transitive_dependencies": {
+			components: []components.Component{
+				fakeComponent{name: "c", dependencies: []string{"b"}},
+				fakeComponent{name: "b", dependencies: []string{"a"}},
+				fakeComponent{name: "a"},
+			},
+			expected: [][]string{{"a"}, {"b"}, {"c"}},
+		},
+		"missing_dependency_is_ignored": {
+			components: []components.Component{
+				fakeComponent{name: "dex", dependencies: []string{"cert-manager"}},
+			},
+			expected: [][]string{{"dex"}},
+		},
+		"wait_component_is_installed_before_following_components": {
+			components: []components.Component{
+				fakeComponent{name: "a"},
+				fakeComponent{name: "prometheus-operator", wait: true},
+				fakeComponent{name: "b"},
+			},
+			expected: [][]string{{"a", "prometheus-operator"}, {"b"}},
+		},
+		"wait_component_depending_on_following_component": {
+			components: []components.Component{
+				fakeComponent{name: "prometheus-operator", wait: true, dependencies: []string{"contour"}},
+				fakeComponent{name: "contour"},
+				fakeComponent{name: "b"},
+			},
+			expected: [][]string{{"contour"}, {"prometheus-operator"}, {"b"}},
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			batches, err := util.InstallOrder(c.components)
+			if err != nil {
+				t.Fatalf("Ordering components should succeed, got: %v", err)
+			}
+
+			if diff := cmp.Diff(c.expected, batchNames(batches)); diff != "" {
+				t.Fatalf("Unexpected install order (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInstallOrderCycle(t *testing.T) {
+	comps := []components.Component{
+		fakeComponent{name: "a", dependencies: []string{"b"}},
+		fakeComponent{name: "b", dependencies: []string{"a"}},
+		fakeComponent{name: "c"},
+	}
+
+	if _, err := util.InstallOrder(comps); err == nil {
+		t.Fatalf("Ordering components with cyclic dependencies should fail")
+	}
+}
+
+func TestDependents(t *testing.T) {
+	comps := []components.Component{
+		fakeComponent{name: "web-ui", dependencies: []string{"contour"}},
+		fakeComponent{name: "dex", dependencies: []string{"cert-manager", "contour"}},
+		fakeComponent{name: "contour"},
+	}
+
+	if diff := cmp.Diff([]string{"dex", "web-ui"}, util.Dependents("contour", comps)); diff != "" {
+		t.Fatalf("Unexpected dependents (-want +got)\n%s", diff)
+	}
+
+	if d := util.Dependents("dex", comps); len(d) != 0 {
+		t.Fatalf("Expected no dependents, got: %v", d)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+)
+
+// ChangeAction describes what happens with a Kubernetes object when new manifest gets applied.
+type ChangeAction string
+
+const (
+	// ChangeActionCreate means that the object does not exist yet and it will be created.
+	ChangeActionCreate ChangeAction = "create"
+	// ChangeActionUpdate means that the object exists, but it's content will change.
+	ChangeActionUpdate ChangeAction = "update"
+	// ChangeActionDelete means that the object exists, but it is no longer part of the manifest
+	// and it will be removed.
+	ChangeActionDelete ChangeAction = "delete"
+)
+
+// ObjectChange describes a change of a single Kubernetes object between two manifests.
+type ObjectChange struct {
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace,omitempty"`
+	Name      string       `json:"name"`
+	Action    ChangeAction `json:"action"`
+	// Diff is a unified diff between current and desired content of the object.
+	Diff string `json:"diff"`
+}
+
+const (
+	// diffContextLines is a number of unchanged lines included around each change in the diff.
+	diffContextLines = 3
+
+	// secretKind is a kind of objects, which values are masked in the diff.
+	secretKind = "Secret"
+
+	// secretMask replaces values of Secret data fields in the diff.
+	secretMask = "***"
+)
+
+// secretDataFields are fields of the Secret object holding sensitive values.
+var secretDataFields = []string{"data", "stringData"}
+
+type objectKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// DiffManifests compares current and desired manifests object by object and returns
+// the list of objects, which differ, sorted by kind, namespace and name.
+//
+// Objects are identified by their kind, namespace and name and their content is compared
+// after normalizing the formatting, so only semantic changes are reported. Removed objects
+// are included as well, with all their content marked as removed in the diff.
+func DiffManifests(current, desired string) ([]ObjectChange, error) {
+	currentObjects, err := manifestObjects(current)
+	if err != nil {
+		return nil, fmt.Errorf("parsing current manifest: %w", err)
+	}
+
+	desiredObjects, err := manifestObjects(desired)
+	if err != nil {
+		return nil, fmt.Errorf("parsing desired manifest: %w", err)
+	}
+
+	changes := []ObjectChange{}
+
+	for key, desiredContent := range desiredObjects {
+		currentContent, exists := currentObjects[key]
+		if exists && currentContent == desiredContent {
+			continue
+		}
+
+		action := ChangeActionUpdate
+		if !exists {
+			action = ChangeActionCreate
+		}
+
+		change, err := newObjectChange(key, action, currentContent, desiredContent)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	for key, currentContent := range currentObjects {
+		if _, ok := desiredObjects[key]; ok {
+			continue
+		}
+
+		change, err := newObjectChange(key, ChangeActionDelete, currentContent, "")
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+
+		if changes[i].Namespace != changes[j].Namespace {
+			return changes[i].Namespace < changes[j].Namespace
+		}
+
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes, nil
+}
+
+func newObjectChange(key objectKey, action ChangeAction, current, desired string) (ObjectChange, error) {
+	name := key.name
+	if key.namespace != "" {
+		name = fmt.Sprintf("%s/%s", key.namespace, key.name)
+	}
+
+	if key.kind == secretKind {
+		var err error
+
+		if current, desired, err = maskSecrets(current, desired); err != nil {
+			return ObjectChange{}, fmt.Errorf("masking values of %s %s: %w", key.kind, name, err)
+		}
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(current),
+		B:        splitLines(desired),
+		FromFile: fmt.Sprintf("current %s %s", key.kind, name),
+		ToFile:   fmt.Sprintf("desired %s %s", key.kind, name),
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return ObjectChange{}, fmt.Errorf("generating diff for %s %s: %w", key.kind, name, err)
+	}
+
+	return ObjectChange{
+		Kind:      key.kind,
+		Namespace: key.namespace,
+		Name:      key.name,
+		Action:    action,
+		Diff:      diff,
+	}, nil
+}
+
+// maskSecrets replaces values of Secret data fields in given current and desired content
+// of the object with a placeholder, so they are not printed. Like with 'kubectl diff',
+// changed values get a different placeholder on each side, so the change is still visible.
+func maskSecrets(current, desired string) (string, string, error) {
+	currentObject, err := secretObject(current)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing current content: %w", err)
+	}
+
+	desiredObject, err := secretObject(desired)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing desired content: %w", err)
+	}
+
+	for _, field := range secretDataFields {
+		currentData, _ := currentObject[field].(map[string]interface{})
+		desiredData, _ := desiredObject[field].(map[string]interface{})
+
+		for k, v := range currentData {
+			if dv, ok := desiredData[k]; ok && dv == v {
+				currentData[k] = secretMask
+				desiredData[k] = secretMask
+
+				continue
+			}
+
+			currentData[k] = secretMask + " (before)"
+		}
+
+		for k, v := range desiredData {
+			if v != secretMask {
+				desiredData[k] = secretMask + " (after)"
+			}
+		}
+	}
+
+	maskedCurrent, err := secretContent(currentObject)
+	if err != nil {
+		return "", "", fmt.Errorf("encoding current content: %w", err)
+	}
+
+	maskedDesired, err := secretContent(desiredObject)
+	if err != nil {
+		return "", "", fmt.Errorf("encoding desired content: %w", err)
+	}
+
+	return maskedCurrent, maskedDesired, nil
+}
+
+// secretObject parses given object content. Empty content returns nil object.
+func secretObject(content string) (map[string]interface{}, error) {
+	if content == "" {
+		return nil, nil
+	}
+
+	o := map[string]interface{}{}
+
+	return o, yaml.Unmarshal([]byte(content), &o)
+}
+
+// secretContent encodes given object back to YAML. Nil object returns empty content.
+func secretContent(o map[string]interface{}) (string, error) {
+	if o == nil {
+		return "", nil
+	}
+
+	content, err := yaml.Marshal(o)
+
+	return string(content), err
+}
+
+// splitLines splits given text into lines, keeping the line endings, as expected by difflib.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	// Drop the empty element after the last line ending.
+	return lines[:len(lines)-1]
+}
+
+// manifestObjects parses given YAML stream and returns normalized content of each object in it.
+func manifestObjects(manifest string) (map[objectKey]string, error) {
+	manifests, err := k8sutil.LoadManifests(map[string]string{"manifest": manifest})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := map[objectKey]string{}
+
+	for _, m := range manifests {
+		key := objectKey{
+			kind:      m.Kind(),
+			namespace: m.Namespace(),
+			name:      m.Name(),
+		}
+
+		// Converting JSON back to YAML sorts all the keys, so formatting differences
+		// between the manifests are not reported as changes.
+		content, err := yaml.JSONToYAML(m.Raw())
+		if err != nil {
+			return nil, fmt.Errorf("normalizing object %s %s/%s: %w", key.kind, key.namespace, key.name, err)
+		}
+
+		objects[key] = string(content)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/kinvolk/lokomotive/pkg/components/util"
+)
+
+const currentManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+  namespace: foo
+data:
+  a: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+  namespace: foo
+data:
+  a: b
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: removed
+  namespace: foo
+`
+
+const desiredManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  namespace: foo
+  name: unchanged
+data:
+  a: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+  namespace: foo
+data:
+  a: c
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: added
+  namespace: foo
+`
+
+func TestDiffManifests(t *testing.T) {
+	changes, err := util.DiffManifests(currentManifest, desiredManifest)
+	if err != nil {
+		t.Fatalf("Diffing valid manifests should succeed, got: %v", err)
+	}
+
+	expected := []util.ObjectChange{
+		{
+			Kind:      "ConfigMap",
+			Namespace: "foo",
+			Name:      "changed",
+			Action:    util.ChangeActionUpdate,
+			Diff: `--- current ConfigMap foo/changed
++++ desired ConfigMap foo/changed
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  a: b
++  a: c
+ kind: ConfigMap
+ metadata:
+   name: changed
+`,
+		},
+		{
+			Kind:      "Secret",
+			Namespace: "foo",
+			Name:      "removed",
+			Action:    util.ChangeActionDelete,
+			Diff: `--- current Secret foo/removed
++++ desired Secret foo/removed
+@@ -1,5 +0,0 @@
+-apiVersion: v1
+-kind: Secret
+-metadata:
+-  name: removed
+-  namespace: foo
+`,
+		},
+		{
+			Kind:      "ServiceAccount",
+			Namespace: "foo",
+			Name:      "added",
+			Action:    util.ChangeActionCreate,
+			Diff: `--- current ServiceAccount foo/added
++++ desired ServiceAccount foo/added
+@@ -0,0 +1,5 @@
++apiVersion: v1
++kind: ServiceAccount
++metadata:
++  name: added
++  namespace: foo
+`,
+		},
+	}
+
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Fatalf("Unexpected changes (-want +got)\n%s", diff)
+	}
+}
+
+func TestDiffManifestsNoChanges(t *testing.T) {
+	changes, err := util.DiffManifests(currentManifest, currentManifest)
+	if err != nil {
+		t.Fatalf("Diffing valid manifests should succeed, got: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes, got: %v", changes)
+	}
+}
+
+func TestDiffManifestsBadManifest(t *testing.T) {
+	if _, err := util.DiffManifests("", "foo: bar: baz"); err == nil {
+		t.Fatalf("Diffing malformed manifest should fail")
+	}
+}
+
+func TestDiffManifestsMasksSecretValues(t *testing.T) {
+	current := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: foo
+data:
+  password: b2xk
+  username: YWRtaW4=
+`
+
+	desired := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: foo
+data:
+  password: bmV3
+  username: YWRtaW4=
+stringData:
+  token: secret-token
+`
+
+	changes, err := util.DiffManifests(current, desired)
+	if err != nil {
+		t.Fatalf("Diffing valid manifests should succeed, got: %v", err)
+	}
+
+	expected := []util.ObjectChange{
+		{
+			Kind:      "Secret",
+			Namespace: "foo",
+			Name:      "credentials",
+			Action:    util.ChangeActionUpdate,
+			Diff: `--- current Secret foo/credentials
++++ desired Secret foo/credentials
+@@ -1,8 +1,10 @@
+ apiVersion: v1
+ data:
+-  password: '*** (before)'
++  password: '*** (after)'
+   username: '***'
+ kind: Secret
+ metadata:
+   name: credentials
+   namespace: foo
++stringData:
++  token: '*** (after)'
+`,
+		},
+	}
+
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Fatalf("Unexpected changes (-want +got)\n%s", diff)
+	}
+}
@@ -149,6 +149,25 @@ func chartFromComponent(c components.Component) (*chart.Chart, error) {
 	return ch, nil
 }
 
+// ComponentManifest renders given component and returns it's manifest in the same form as
+// it is stored in the Helm release by InstallComponent, so both can be compared.
+//
+// CRDs are not part of the returned manifest, as they are installed separately from the release.
+func ComponentManifest(c components.Component) (string, error) {
+	ch, err := chartFromComponent(c)
+	if err != nil {
+		return "", err
+	}
+
+	manifests := []string{}
+
+	for _, f := range ch.Manifests {
+		manifests = append(manifests, string(f.Data))
+	}
+
+	return strings.Join(manifests, "\n---\n"), nil
+}
+
 // chartFromManifests creates Helm chart object in memory from given manifests.
 func chartFromManifests(metadata components.Metadata, manifests map[string]string) (*chart.Chart, error) {
 	ch := &chart.Chart{
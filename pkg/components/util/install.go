@@ -21,6 +21,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -166,6 +167,36 @@ func ReleaseExists(actionConfig action.Configuration, name string) (bool, error)
 	return err != driver.ErrReleaseNotFound, nil
 }
 
+// ReleaseManifest returns the manifest of the deployed Helm release with a given name and
+// whether the release exists at all.
+func ReleaseManifest(actionConfig *action.Configuration, name string) (string, bool, error) {
+	rel, err := action.NewGet(actionConfig).Run(name)
+	if err == driver.ErrReleaseNotFound {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("getting release: %w", err)
+	}
+
+	return rel.Manifest, true, nil
+}
+
+// ReleaseStatus returns the status of the latest revision of the Helm release with a given name.
+// If the release does not exist, an empty status is returned.
+func ReleaseStatus(actionConfig *action.Configuration, name string) (release.Status, error) {
+	rel, err := action.NewGet(actionConfig).Run(name)
+	if err == driver.ErrReleaseNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("getting release: %w", err)
+	}
+
+	return rel.Info.Status, nil
+}
+
 // UninstallComponent uninstalls a component and optionally removes it's namespace.
 func UninstallComponent(c components.Component, kubeconfig []byte, deleteNSBool bool) error {
 	name := c.Metadata().Name
@@ -22,6 +22,8 @@ import (
 
 	"github.com/kinvolk/lokomotive/internal/template"
 	"github.com/kinvolk/lokomotive/pkg/components"
+	certmanager "github.com/kinvolk/lokomotive/pkg/components/cert-manager"
+	"github.com/kinvolk/lokomotive/pkg/components/contour"
 	"github.com/kinvolk/lokomotive/pkg/components/types"
 	"github.com/kinvolk/lokomotive/pkg/components/util"
 	"github.com/kinvolk/lokomotive/pkg/k8sutil"
@@ -94,10 +96,17 @@ func (c *component) RenderManifests() (map[string]string, error) {
 }
 
 func (c *component) Metadata() components.Metadata {
-	return components.Metadata{
+	m := components.Metadata{
 		Name: Name,
 		Namespace: k8sutil.Namespace{
 			Name: c.Namespace,
 		},
+		ConditionalDependencies: []string{certmanager.Name, contour.Name},
 	}
+
+	if c.Ingress != nil {
+		m.Dependencies = []string{certmanager.Name, contour.Name}
+	}
+
+	return m
 }
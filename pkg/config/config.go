@@ -15,6 +15,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -26,12 +28,10 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
-)
 
-type variable struct {
-	Name    string         `hcl:"name,label"`
-	Default hcl.Attributes `hcl:",remain"`
-}
+	"github.com/kinvolk/lokomotive/internal/redact"
+	"github.com/kinvolk/lokomotive/pkg/secrets"
+)
 
 type cluster struct {
 	Name   string   `hcl:"name,label"`
@@ -53,11 +53,16 @@ type RootConfig struct {
 	Backend    *backend    `hcl:"backend,block"`
 	Components []component `hcl:"component,block"`
 	Variables  []variable  `hcl:"variable,block"`
+	Locals     []locals    `hcl:"locals,block"`
 }
 
 type Config struct {
 	RootConfig  *RootConfig
 	EvalContext *hcl.EvalContext
+
+	// paths and files are used to show the merged configuration.
+	paths []string
+	files map[string]*hcl.File
 }
 
 func loadLokocfgPaths(configPath string) ([]string, error) {
@@ -79,29 +84,39 @@ func loadLokocfgPaths(configPath string) ([]string, error) {
 	return lokocfgPaths, nil
 }
 
-func LoadConfig(lokocfgPath, lokocfgVarsPath string) (*Config, hcl.Diagnostics) {
-	lokocfgPaths, err := loadLokocfgPaths(lokocfgPath)
-	if err != nil {
+// LoadConfig loads the configuration from given paths to lokocfg directories or files and
+// given values file. Configuration from each path is merged into configuration loaded from
+// previous paths, so later paths take precedence. See RootConfig.merge for details.
+func LoadConfig(lokocfgPaths []string, lokocfgVarsPath string) (*Config, hcl.Diagnostics) {
+	if len(lokocfgPaths) == 0 {
 		return nil, hcl.Diagnostics{
 			&hcl.Diagnostic{
 				Severity: hcl.DiagError,
-				Summary:  err.Error(),
+				Summary:  "no configuration paths given",
 			},
 		}
 	}
 
 	hclParser := hclparse.NewParser()
 
-	var hclFiles []*hcl.File
-	for _, f := range lokocfgPaths {
-		hclFile, diags := hclParser.ParseHCLFile(f)
+	var rootConfig *RootConfig
+
+	for _, lokocfgPath := range lokocfgPaths {
+		layer, diags := loadLayer(hclParser, lokocfgPath)
 		if len(diags) > 0 {
 			return nil, diags
 		}
-		hclFiles = append(hclFiles, hclFile)
-	}
 
-	configBody := hcl.MergeFiles(hclFiles)
+		if rootConfig == nil {
+			rootConfig = layer
+
+			continue
+		}
+
+		if diags := rootConfig.merge(layer); len(diags) > 0 {
+			return nil, diags
+		}
+	}
 
 	exists, err := pathExists(lokocfgVarsPath)
 	if err != nil {
@@ -121,46 +136,116 @@ func LoadConfig(lokocfgPath, lokocfgVarsPath string) (*Config, hcl.Diagnostics)
 		}
 	}
 
-	var rootConfig RootConfig
-	diags = gohcl.DecodeBody(configBody, nil, &rootConfig)
+	functions := evalFunctions()
+
+	variables, diags := evalVariables(rootConfig.Variables, userVals, functions)
+	if len(diags) > 0 {
+		return nil, diags
+	}
+
+	localValues, diags := evalLocals(rootConfig.Locals, variables, functions)
 	if len(diags) > 0 {
 		return nil, diags
 	}
 
+	evalContext := hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(variables),
+			"local": cty.ObjectVal(localValues),
+		},
+		Functions: functions,
+	}
+
+	return &Config{
+		RootConfig:  rootConfig,
+		EvalContext: &evalContext,
+		paths:       lokocfgPaths,
+		files:       hclParser.Files(),
+	}, nil
+}
+
+// loadLayer loads the configuration from given lokocfg directory or file.
+func loadLayer(hclParser *hclparse.Parser, lokocfgPath string) (*RootConfig, hcl.Diagnostics) {
+	paths, err := loadLokocfgPaths(lokocfgPath)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  err.Error(),
+			},
+		}
+	}
+
+	var hclFiles []*hcl.File
+
+	for _, f := range paths {
+		hclFile, diags := hclParser.ParseHCLFile(f)
+		if len(diags) > 0 {
+			return nil, diags
+		}
+
+		hclFiles = append(hclFiles, hclFile)
+	}
+
+	var rootConfig RootConfig
+	if diags := gohcl.DecodeBody(hcl.MergeFiles(hclFiles), nil, &rootConfig); len(diags) > 0 {
+		return nil, diags
+	}
+
+	return &rootConfig, nil
+}
+
+// evalVariables returns values of all declared variables. Values of sensitive variables are
+// registered for redaction in the output.
+func evalVariables(
+	declared []variable, userVals map[string]cty.Value, functions map[string]function.Function,
+) (map[string]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
 	variables := map[string]cty.Value{}
-	for _, v := range rootConfig.Variables {
-		if userVal, ok := userVals[v.Name]; ok {
-			variables[v.Name] = userVal
+	sensitive := []string{}
+
+	for _, v := range declared {
+		d, declDiags := decodeVariable(v)
+		diags = append(diags, declDiags...)
+
+		if declDiags.HasErrors() {
 			continue
 		}
-		if len(v.Default) == 0 {
+
+		if _, ok := variables[d.name]; ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable declaration",
+				Detail:   fmt.Sprintf("Variable %q is declared more than once.", d.name),
+				Subject:  d.declRange.Ptr(),
+			})
+
 			continue
 		}
-		defaultValue, hasDefaultValue := v.Default["default"]
-		if !hasDefaultValue {
+
+		var userValue *cty.Value
+		if uv, ok := userVals[d.name]; ok {
+			userValue = &uv
+		}
+
+		value, valueDiags := d.value(userValue, functions)
+		diags = append(diags, valueDiags...)
+
+		if valueDiags.HasErrors() {
 			continue
 		}
-		defaultVal, diags := defaultValue.Expr.Value(nil)
-		if len(diags) > 0 {
-			return nil, diags
+
+		variables[d.name] = value
+
+		if d.sensitive {
+			sensitive = append(sensitive, sensitiveStrings(value)...)
 		}
-		variables[v.Name] = defaultVal
 	}
 
-	evalContext := hcl.EvalContext{
-		Variables: map[string]cty.Value{
-			"var": cty.ObjectVal(variables),
-		},
-		Functions: map[string]function.Function{
-			"pathexpand": evalFuncPathExpand(),
-			"file":       evalFuncFile(),
-		},
-	}
+	redact.Add(sensitive...)
 
-	return &Config{
-		RootConfig:  &rootConfig,
-		EvalContext: &evalContext,
-	}, nil
+	return variables, diags
 }
 
 func evalFuncPathExpand() function.Function {
@@ -194,6 +279,88 @@ func evalFuncFile() function.Function {
 	})
 }
 
+// evalFuncEncryptedFile returns function reading files encrypted using age or SOPS.
+// Decrypted content is only kept in memory.
+func evalFuncEncryptedFile() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			content, err := readEncryptedFile(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(string(content)), nil
+		},
+	})
+}
+
+// evalFuncEnv returns function reading environment variables. If the variable is not set,
+// the optional default value is returned or an error, if no default value is given.
+// Values of set variables are registered for redaction in the output.
+func evalFuncEnv() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "name",
+				Type: cty.String,
+			}},
+		VarParam: &function.Parameter{
+			Name: "default",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if len(args) > 2 {
+				return cty.StringVal(""), fmt.Errorf("at most one default value can be given")
+			}
+
+			name := args[0].AsString()
+
+			if value, ok := os.LookupEnv(name); ok {
+				// Environment variables are often used to pass credentials.
+				redact.Add(value)
+
+				return cty.StringVal(value), nil
+			}
+
+			if len(args) == 2 {
+				return args[1], nil
+			}
+
+			return cty.StringVal(""), fmt.Errorf("environment variable %q is not set", name)
+		},
+	})
+}
+
+// evalFuncSecret returns function reading secrets referenced by URL from given providers.
+// Returned secrets are registered for redaction in the output.
+func evalFuncSecret(providers secrets.Providers) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "ref",
+				Type: cty.String,
+			}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			secret, err := providers.Resolve(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			redact.Add(secret)
+
+			return cty.StringVal(secret), nil
+		},
+	})
+}
+
 // LoadComponentConfigBody returns nil if no component with the given
 // name is found in the configuration
 func (c *Config) LoadComponentConfigBody(componentName string) *hcl.Body {
@@ -205,6 +372,45 @@ func (c *Config) LoadComponentConfigBody(componentName string) *hcl.Body {
 	return nil
 }
 
+// Checksum returns a checksum of the configuration files and the values file,
+// which can be used to detect configuration changes between lokoctl runs.
+// Files referenced from the configuration are not taken into account.
+func Checksum(lokocfgPaths []string, lokocfgVarsPath string) (string, error) {
+	var paths []string
+
+	for _, lokocfgPath := range lokocfgPaths {
+		layerPaths, err := loadLokocfgPaths(lokocfgPath)
+		if err != nil {
+			return "", err
+		}
+
+		paths = append(paths, layerPaths...)
+	}
+
+	exists, err := pathExists(lokocfgVarsPath)
+	if err != nil {
+		return "", fmt.Errorf("could not stat %q: %w", lokocfgVarsPath, err)
+	}
+
+	if exists {
+		paths = append(paths, lokocfgVarsPath)
+	}
+
+	h := sha256.New()
+
+	for _, p := range paths {
+		content, err := ioutil.ReadFile(p) //nolint:gosec
+		if err != nil {
+			return "", fmt.Errorf("reading file %q: %w", p, err)
+		}
+
+		fmt.Fprintf(h, "%s\n%d\n", filepath.Base(p), len(content))
+		h.Write(content) //nolint:errcheck
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func pathExists(path string) (bool, error) {
 	_, err := os.Stat(path)
 	if err == nil {
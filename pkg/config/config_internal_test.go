@@ -0,0 +1,95 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/kinvolk/lokomotive/internal/redact"
+	"github.com/kinvolk/lokomotive/pkg/secrets"
+)
+
+const testEnvVariable = "LOKOMOTIVE_CONFIG_TEST_VARIABLE"
+
+func TestEnvFunction(t *testing.T) {
+	if err := os.Setenv(testEnvVariable, "foo"); err != nil {
+		t.Fatalf("Setting environment variable: %v", err)
+	}
+
+	t.Cleanup(func() {
+		os.Unsetenv(testEnvVariable) //nolint:errcheck
+	})
+
+	v, err := evalFuncEnv().Call([]cty.Value{cty.StringVal(testEnvVariable), cty.StringVal("bar")})
+	if err != nil {
+		t.Fatalf("Reading set environment variable should succeed, got: %v", err)
+	}
+
+	if v.AsString() != "foo" {
+		t.Fatalf("Expected value %q, got %q", "foo", v.AsString())
+	}
+
+	if got := redact.String("foo"); got != redact.Placeholder {
+		t.Fatalf("Environment variable value should be redacted, got %q", got)
+	}
+}
+
+func TestEnvFunctionDefault(t *testing.T) {
+	v, err := evalFuncEnv().Call([]cty.Value{cty.StringVal(testEnvVariable + "_UNSET"), cty.StringVal("bar")})
+	if err != nil {
+		t.Fatalf("Reading unset environment variable with default should succeed, got: %v", err)
+	}
+
+	if v.AsString() != "bar" {
+		t.Fatalf("Expected default value %q, got %q", "bar", v.AsString())
+	}
+
+	if _, err := evalFuncEnv().Call([]cty.Value{cty.StringVal(testEnvVariable + "_UNSET")}); err == nil {
+		t.Fatal("Reading unset environment variable without default should fail")
+	}
+}
+
+type fakeProvider map[string]string
+
+func (f fakeProvider) Secret(ref *url.URL) (string, error) {
+	return f[ref.Opaque], nil
+}
+
+func TestSecretFunction(t *testing.T) {
+	providers := secrets.Providers{
+		"fake": fakeProvider{"foo": "bar"},
+	}
+
+	v, err := evalFuncSecret(providers).Call([]cty.Value{cty.StringVal("fake:foo")})
+	if err != nil {
+		t.Fatalf("Reading secret should succeed, got: %v", err)
+	}
+
+	if v.AsString() != "bar" {
+		t.Fatalf("Expected secret %q, got %q", "bar", v.AsString())
+	}
+
+	if got := redact.String("bar"); got != redact.Placeholder {
+		t.Fatalf("Secret should be redacted, got %q", got)
+	}
+
+	if _, err := evalFuncSecret(providers).Call([]cty.Value{cty.StringVal("unknown:foo")}); err == nil {
+		t.Fatal("Reading secret with unsupported scheme should fail")
+	}
+}
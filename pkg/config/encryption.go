@@ -0,0 +1,168 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// AgeKeyFileEnv is the environment variable pointing to the file with age identities used
+	// for decrypting files. It is shared with SOPS, so the same keys work for both formats.
+	AgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+
+	// defaultAgeKeyFile is the age identities file used when AgeKeyFileEnv is not set.
+	defaultAgeKeyFile = "~/.config/sops/age/keys.txt"
+
+	// ageHeader is the first line of binary age encrypted files.
+	ageHeader = "age-encryption.org/v1\n"
+
+	// sopsBinary is the name of the SOPS executable used for decrypting SOPS encrypted files.
+	sopsBinary = "sops"
+)
+
+// readFile reads the file with given path and, if it is encrypted using age or SOPS,
+// decrypts its content in memory.
+func readFile(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading file %q: %w", path, err)
+	}
+
+	switch {
+	case isAgeEncrypted(content):
+		return decryptAge(path, content)
+	case isSOPSEncrypted(content):
+		return decryptSOPS(path)
+	default:
+		return content, nil
+	}
+}
+
+// readEncryptedFile is like readFile, but fails if the file is not encrypted, to avoid
+// accidentally keeping secrets in plain text.
+func readEncryptedFile(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading file %q: %w", path, err)
+	}
+
+	if !isAgeEncrypted(content) && !isSOPSEncrypted(content) {
+		return nil, fmt.Errorf("file %q is not encrypted using age or SOPS", path)
+	}
+
+	return readFile(path)
+}
+
+// isAgeEncrypted checks, if given content is encrypted using age, either in binary
+// or in armored format.
+func isAgeEncrypted(content []byte) bool {
+	trimmed := bytes.TrimLeft(content, " \t\r\n")
+
+	return bytes.HasPrefix(content, []byte(ageHeader)) || bytes.HasPrefix(trimmed, []byte(armor.Header))
+}
+
+// isSOPSEncrypted checks, if given content is a file encrypted using SOPS in binary
+// format, which is a JSON document with "data" and "sops" keys.
+func isSOPSEncrypted(content []byte) bool {
+	document := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(content, &document); err != nil {
+		return false
+	}
+
+	_, hasData := document["data"]
+	_, hasMetadata := document["sops"]
+
+	return hasData && hasMetadata
+}
+
+// ageIdentities returns age identities from the file pointed by AgeKeyFileEnv
+// or from the default location.
+func ageIdentities() ([]age.Identity, error) {
+	path := os.Getenv(AgeKeyFileEnv)
+	if path == "" {
+		path = defaultAgeKeyFile
+	}
+
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("expanding path %q: %w", path, err)
+	}
+
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("opening age identities file (set %s to change it): %w", AgeKeyFileEnv, err)
+	}
+
+	defer f.Close() //nolint:errcheck
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing age identities file %q: %w", path, err)
+	}
+
+	return identities, nil
+}
+
+func decryptAge(path string, content []byte) ([]byte, error) {
+	identities, err := ageIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(content)
+
+	if !bytes.HasPrefix(content, []byte(ageHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimLeft(content, " \t\r\n")))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting file %q using age: %w", path, err)
+	}
+
+	decrypted, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting file %q using age: %w", path, err)
+	}
+
+	return decrypted, nil
+}
+
+func decryptSOPS(path string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	// #nosec G204 // The path comes from the user configuration.
+	cmd := exec.Command(sopsBinary, "--decrypt", "--input-type", "binary", "--output-type", "binary", path)
+	cmd.Stderr = &stderr
+
+	decrypted, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("decrypting file %q using SOPS: %w: %s", path, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return decrypted, nil
+}
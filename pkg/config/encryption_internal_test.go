@@ -0,0 +1,152 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// encryptedFixture generates an age identity pointed by AgeKeyFileEnv and writes given
+// content encrypted for it to a file, which path is returned.
+func encryptedFixture(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Generating age identity: %v", err)
+	}
+
+	keyFile := filepath.Join(dir, "keys.txt")
+
+	if err := ioutil.WriteFile(keyFile, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatalf("Writing age identity: %v", err)
+	}
+
+	oldKeyFile, keyFileSet := os.LookupEnv(AgeKeyFileEnv)
+
+	if err := os.Setenv(AgeKeyFileEnv, keyFile); err != nil {
+		t.Fatalf("Setting %s: %v", AgeKeyFileEnv, err)
+	}
+
+	t.Cleanup(func() {
+		if keyFileSet {
+			os.Setenv(AgeKeyFileEnv, oldKeyFile) //nolint:errcheck
+
+			return
+		}
+
+		os.Unsetenv(AgeKeyFileEnv) //nolint:errcheck
+	})
+
+	var buf bytes.Buffer
+
+	a := armor.NewWriter(&buf)
+
+	w, err := age.Encrypt(a, identity.Recipient())
+	if err != nil {
+		t.Fatalf("Creating age encryptor: %v", err)
+	}
+
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Encrypting: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Closing encryptor: %v", err)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("Closing armor writer: %v", err)
+	}
+
+	path := filepath.Join(dir, "lokocfg.vars")
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("Writing encrypted file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadValuesFileDecryptsAgeEncryptedFile(t *testing.T) {
+	path := encryptedFixture(t, `client_secret = "foo"`)
+
+	values, diags := LoadValuesFile(path)
+	if diags.HasErrors() {
+		t.Fatalf("Loading encrypted values file should succeed, got: %v", diags)
+	}
+
+	if v, ok := values["client_secret"]; !ok || v.AsString() != "foo" {
+		t.Fatalf("Expected decrypted value %q, got: %v", "foo", values)
+	}
+}
+
+func TestEncryptedFileFunction(t *testing.T) {
+	path := encryptedFixture(t, "secret")
+
+	content, err := evalFuncEncryptedFile().Call([]cty.Value{cty.StringVal(path)})
+	if err != nil {
+		t.Fatalf("Reading encrypted file should succeed, got: %v", err)
+	}
+
+	if content.AsString() != "secret" {
+		t.Fatalf("Expected decrypted content %q, got %q", "secret", content.AsString())
+	}
+}
+
+func TestEncryptedFileFunctionRejectsPlainTextFile(t *testing.T) {
+	path := filepath.Join(filepath.Dir(encryptedFixture(t, "")), "plain.txt")
+
+	if err := ioutil.WriteFile(path, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("Writing file: %v", err)
+	}
+
+	if _, err := evalFuncEncryptedFile().Call([]cty.Value{cty.StringVal(path)}); err == nil {
+		t.Fatal("Reading plain text file should fail")
+	}
+}
+
+func TestIsSOPSEncrypted(t *testing.T) {
+	cases := map[string]bool{
+		`{"data": "ENC[AES256_GCM,data:...]", "sops": {"version": "3.7.1"}}`: true,
+		`{"data": "foo"}`:       false,
+		`client_secret = "foo"`: false,
+	}
+
+	for content, expected := range cases {
+		if isSOPSEncrypted([]byte(content)) != expected {
+			t.Errorf("Content %q: expected %v", content, expected)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"unicode/utf8"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"github.com/zclconf/go-cty/cty/gocty"
+
+	"github.com/kinvolk/lokomotive/pkg/secrets"
+)
+
+// evalFunctions returns functions available in the configuration. Apart from Lokomotive
+// specific functions, they follow the Terraform function library.
+func evalFunctions() map[string]function.Function {
+	functions := map[string]function.Function{
+		"pathexpand":     evalFuncPathExpand(),
+		"file":           evalFuncFile(),
+		"encrypted_file": evalFuncEncryptedFile(),
+		"env":            evalFuncEnv(),
+		"secret":         evalFuncSecret(secrets.DefaultProviders()),
+
+		"abs":             stdlib.AbsoluteFunc,
+		"base64decode":    evalFuncBase64Decode(),
+		"base64encode":    evalFuncBase64Encode(),
+		"ceil":            stdlib.CeilFunc,
+		"chomp":           stdlib.ChompFunc,
+		"cidrhost":        evalFuncCIDRHost(),
+		"cidrsubnet":      evalFuncCIDRSubnet(),
+		"coalesce":        stdlib.CoalesceFunc,
+		"compact":         stdlib.CompactFunc,
+		"concat":          stdlib.ConcatFunc,
+		"contains":        stdlib.ContainsFunc,
+		"distinct":        stdlib.DistinctFunc,
+		"element":         stdlib.ElementFunc,
+		"flatten":         stdlib.FlattenFunc,
+		"floor":           stdlib.FloorFunc,
+		"format":          stdlib.FormatFunc,
+		"formatlist":      stdlib.FormatListFunc,
+		"indent":          stdlib.IndentFunc,
+		"join":            stdlib.JoinFunc,
+		"jsondecode":      stdlib.JSONDecodeFunc,
+		"jsonencode":      stdlib.JSONEncodeFunc,
+		"keys":            stdlib.KeysFunc,
+		"length":          stdlib.LengthFunc,
+		"lookup":          stdlib.LookupFunc,
+		"lower":           stdlib.LowerFunc,
+		"max":             stdlib.MaxFunc,
+		"merge":           stdlib.MergeFunc,
+		"min":             stdlib.MinFunc,
+		"range":           stdlib.RangeFunc,
+		"regex":           stdlib.RegexFunc,
+		"regexall":        stdlib.RegexAllFunc,
+		"replace":         stdlib.ReplaceFunc,
+		"reverse":         stdlib.ReverseListFunc,
+		"setintersection": stdlib.SetIntersectionFunc,
+		"setunion":        stdlib.SetUnionFunc,
+		"slice":           stdlib.SliceFunc,
+		"sort":            stdlib.SortFunc,
+		"split":           stdlib.SplitFunc,
+		"substr":          stdlib.SubstrFunc,
+		"title":           stdlib.TitleFunc,
+		"trimprefix":      stdlib.TrimPrefixFunc,
+		"trimspace":       stdlib.TrimSpaceFunc,
+		"trimsuffix":      stdlib.TrimSuffixFunc,
+		"upper":           stdlib.UpperFunc,
+		"values":          stdlib.ValuesFunc,
+		"yamldecode":      ctyyaml.YAMLDecodeFunc,
+		"yamlencode":      ctyyaml.YAMLEncodeFunc,
+		"zipmap":          stdlib.ZipmapFunc,
+	}
+
+	// Like in Terraform, templates can use all functions except templatefile itself.
+	templateFunctions := map[string]function.Function{}
+	for name, f := range functions {
+		templateFunctions[name] = f
+	}
+
+	functions["templatefile"] = evalFuncTemplateFile(templateFunctions)
+
+	return functions
+}
+
+func evalFuncBase64Encode() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	})
+}
+
+func evalFuncBase64Decode() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			decoded, err := base64.StdEncoding.DecodeString(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("decoding base64: %w", err)
+			}
+
+			if !utf8.Valid(decoded) {
+				return cty.UnknownVal(cty.String), fmt.Errorf("decoded base64 is not valid UTF-8")
+			}
+
+			return cty.StringVal(string(decoded)), nil
+		},
+	})
+}
+
+// evalFuncCIDRSubnet returns function calculating subnet address within given IP network
+// address prefix, like cidrsubnet("10.0.0.0/16", 8, 2) = "10.0.2.0/24".
+func evalFuncCIDRSubnet() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "prefix",
+				Type: cty.String,
+			},
+			{
+				Name: "newbits",
+				Type: cty.Number,
+			},
+			{
+				Name: "netnum",
+				Type: cty.Number,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			var newBits int
+			if err := gocty.FromCtyValue(args[1], &newBits); err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("invalid newbits: %w", err)
+			}
+
+			netNum := args[2].AsBigFloat()
+			if !netNum.IsInt() {
+				return cty.UnknownVal(cty.String), fmt.Errorf("netnum must be an integer")
+			}
+
+			n, _ := netNum.Int(nil)
+
+			_, network, err := net.ParseCIDR(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("invalid CIDR expression: %w", err)
+			}
+
+			subnet, err := cidr.SubnetBig(network, newBits, n)
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+
+			return cty.StringVal(subnet.String()), nil
+		},
+	})
+}
+
+// evalFuncCIDRHost returns function calculating host address within given IP network
+// address prefix, like cidrhost("10.0.0.0/16", 5) = "10.0.0.5".
+func evalFuncCIDRHost() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "prefix",
+				Type: cty.String,
+			},
+			{
+				Name: "hostnum",
+				Type: cty.Number,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			hostNum := args[1].AsBigFloat()
+			if !hostNum.IsInt() {
+				return cty.UnknownVal(cty.String), fmt.Errorf("hostnum must be an integer")
+			}
+
+			n, _ := hostNum.Int(nil)
+
+			_, network, err := net.ParseCIDR(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("invalid CIDR expression: %w", err)
+			}
+
+			ip, err := cidr.HostBig(network, n)
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+
+			return cty.StringVal(ip.String()), nil
+		},
+	})
+}
+
+// evalFuncTemplateFile returns function rendering given template file with given variables.
+// Templates use the configuration template syntax and can use given functions.
+func evalFuncTemplateFile(functions map[string]function.Function) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "vars",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+
+			vars := args[1]
+			if !vars.Type().IsObjectType() && !vars.Type().IsMapType() {
+				return cty.UnknownVal(cty.String), fmt.Errorf("template variables must be a map or an object")
+			}
+
+			content, err := ioutil.ReadFile(path) //nolint:gosec
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("reading template file %q: %w", path, err)
+			}
+
+			expr, diags := hclsyntax.ParseTemplate(content, path, hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				return cty.UnknownVal(cty.String), diags
+			}
+
+			variables := map[string]cty.Value{}
+			if !vars.IsNull() {
+				variables = vars.AsValueMap()
+			}
+
+			result, diags := expr.Value(&hcl.EvalContext{
+				Variables: variables,
+				Functions: functions,
+			})
+			if diags.HasErrors() {
+				return cty.UnknownVal(cty.String), diags
+			}
+
+			// Template consisting of a single interpolation returns the interpolated value as is.
+			str, err := convert.Convert(result, cty.String)
+			if err != nil {
+				return cty.UnknownVal(cty.String), fmt.Errorf("invalid template result: %w", err)
+			}
+
+			return str, nil
+		},
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+type locals struct {
+	Config hcl.Body `hcl:",remain"`
+}
+
+// localsEvaluator evaluates local values in order of their dependencies.
+type localsEvaluator struct {
+	attributes map[string]*hcl.Attribute
+	variables  map[string]cty.Value
+	functions  map[string]function.Function
+
+	values map[string]cty.Value
+	// visiting holds names of local values being currently evaluated, in order, to
+	// detect and report dependency cycles.
+	visiting []string
+	failed   map[string]bool
+}
+
+// evalLocals returns values of all local values defined in given locals blocks. Local values
+// may reference variables and other local values.
+func evalLocals(
+	blocks []locals, variables map[string]cty.Value, functions map[string]function.Function,
+) (map[string]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	e := &localsEvaluator{
+		attributes: map[string]*hcl.Attribute{},
+		variables:  variables,
+		functions:  functions,
+		values:     map[string]cty.Value{},
+		failed:     map[string]bool{},
+	}
+
+	for _, block := range blocks {
+		attrs, attrsDiags := block.Config.JustAttributes()
+		diags = append(diags, attrsDiags...)
+
+		for name, attr := range attrs {
+			if existing, ok := e.attributes[name]; ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate local value definition",
+					Detail: fmt.Sprintf("Local value %q was already defined at %s.",
+						name, existing.NameRange),
+					Subject: attr.NameRange.Ptr(),
+				})
+
+				continue
+			}
+
+			e.attributes[name] = attr
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	names := make([]string, 0, len(e.attributes))
+	for name := range e.attributes {
+		names = append(names, name)
+	}
+
+	// Sort names to get stable order of reported errors.
+	sort.Strings(names)
+
+	for _, name := range names {
+		diags = append(diags, e.eval(name)...)
+	}
+
+	return e.values, diags
+}
+
+// eval evaluates local value with given name, evaluating its dependencies first.
+func (e *localsEvaluator) eval(name string) hcl.Diagnostics {
+	if _, ok := e.values[name]; ok || e.failed[name] {
+		return nil
+	}
+
+	attr := e.attributes[name]
+
+	for i, visiting := range e.visiting {
+		if visiting != name {
+			continue
+		}
+
+		cycle := append(append([]string{}, e.visiting[i:]...), name)
+		for j := range cycle {
+			cycle[j] = "local." + cycle[j]
+		}
+
+		e.failed[name] = true
+
+		return hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Cycle in local values",
+				Detail:   fmt.Sprintf("Local values depend on each other: %s.", strings.Join(cycle, " -> ")),
+				Subject:  attr.NameRange.Ptr(),
+			},
+		}
+	}
+
+	e.visiting = append(e.visiting, name)
+
+	defer func() {
+		e.visiting = e.visiting[:len(e.visiting)-1]
+	}()
+
+	var diags hcl.Diagnostics
+
+	for _, traversal := range attr.Expr.Variables() {
+		if traversal.RootName() != "local" {
+			continue
+		}
+
+		dependency, ok := localName(traversal)
+		if !ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid reference",
+				Detail:   `A reference to a local value must be followed by its name, like "local.foo".`,
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+
+			continue
+		}
+
+		if _, ok := e.attributes[dependency]; !ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared local value",
+				Detail:   fmt.Sprintf("A local value named %q has not been declared.", dependency),
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+
+			continue
+		}
+
+		diags = append(diags, e.eval(dependency)...)
+	}
+
+	if diags.HasErrors() {
+		e.failed[name] = true
+
+		return diags
+	}
+
+	value, valueDiags := attr.Expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(e.variables),
+			"local": cty.ObjectVal(e.values),
+		},
+		Functions: e.functions,
+	})
+	diags = append(diags, valueDiags...)
+
+	if valueDiags.HasErrors() {
+		e.failed[name] = true
+
+		return diags
+	}
+
+	e.values[name] = value
+
+	return diags
+}
+
+// localName returns the name of the local value referenced by given traversal.
+func localName(traversal hcl.Traversal) (string, bool) {
+	if len(traversal) < 2 {
+		return "", false
+	}
+
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+
+	return attr.Name, true
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLocalValues(t *testing.T) {
+	lokocfg := `
+variable "cluster_name" {}
+
+locals {
+  fqdn   = format("%s.%s", var.cluster_name, local.domain)
+  labels = merge(local.common_labels, { "cluster" = var.cluster_name })
+}
+
+locals {
+  domain        = "example.com"
+  common_labels = { "team" = "platform" }
+}
+`
+
+	c, err := loadTestConfig(t, lokocfg, `cluster_name = "foo"`)
+	if err != nil {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", err)
+	}
+
+	locals := c.EvalContext.Variables["local"]
+
+	if got := locals.GetAttr("fqdn"); !got.RawEquals(cty.StringVal("foo.example.com")) {
+		t.Fatalf("Expected local value %q, got %#v", "foo.example.com", got)
+	}
+
+	expectedLabels := cty.ObjectVal(map[string]cty.Value{
+		"team":    cty.StringVal("platform"),
+		"cluster": cty.StringVal("foo"),
+	})
+
+	if got := locals.GetAttr("labels"); !got.RawEquals(expectedLabels) {
+		t.Fatalf("Expected local value %#v, got %#v", expectedLabels, got)
+	}
+}
+
+func TestLocalValuesAreInvalid(t *testing.T) {
+	cases := map[string]struct {
+		lokocfg string
+		err     string
+	}{
+		"when local values depend on each other": {
+			lokocfg: `
+locals {
+  a = local.b
+  b = "${local.c}-b"
+  c = local.a
+}
+`,
+			err: "local.a -> local.b -> local.c -> local.a",
+		},
+		"when local value references itself": {
+			lokocfg: `
+locals {
+  a = local.a
+}
+`,
+			err: "Cycle in local values",
+		},
+		"when local value is defined more than once": {
+			lokocfg: `
+locals {
+  a = 1
+}
+
+locals {
+  a = 2
+}
+`,
+			err: "Duplicate local value definition",
+		},
+		"when referenced local value is not declared": {
+			lokocfg: `
+locals {
+  a = local.b
+}
+`,
+			err: "Reference to undeclared local value",
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			_, err := loadTestConfig(t, c.lokocfg, "")
+			if err == nil {
+				t.Fatal("Loading configuration should fail")
+			}
+
+			if !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("Expected error containing %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	templatePath := filepath.Join(dir, "template.tmpl")
+
+	if err := ioutil.WriteFile(templatePath, []byte(`name: ${upper(name)}`), 0o600); err != nil {
+		t.Fatalf("Writing template file: %v", err)
+	}
+
+	lokocfg := `
+locals {
+  join         = join(",", ["a", "b"])
+  lookup       = lookup({ "a" = "b" }, "c", "d")
+  cidrsubnet   = cidrsubnet("10.0.0.0/16", 8, 2)
+  cidrhost     = cidrhost("10.0.2.0/24", 5)
+  base64encode = base64encode("foo")
+  base64decode = base64decode("Zm9v")
+  yamlencode   = yamlencode({ "a" = "b" })
+  templatefile = templatefile("` + templatePath + `", { "name" = "foo" })
+}
+`
+
+	c, err := loadTestConfig(t, lokocfg, "")
+	if err != nil {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"join":         "a,b",
+		"lookup":       "d",
+		"cidrsubnet":   "10.0.2.0/24",
+		"cidrhost":     "10.0.2.5",
+		"base64encode": "Zm9v",
+		"base64decode": "foo",
+		"yamlencode":   "\"a\": \"b\"\n",
+		"templatefile": "name: FOO",
+	}
+
+	locals := c.EvalContext.Variables["local"]
+
+	for name, value := range expected {
+		if got := locals.GetAttr(name); !got.RawEquals(cty.StringVal(value)) {
+			t.Errorf("Expected %q to return %q, got %#v", name, value, got)
+		}
+	}
+}
+
+func TestTemplateFileNonStringResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	templatePath := filepath.Join(dir, "template.tmpl")
+
+	if err := ioutil.WriteFile(templatePath, []byte(`${n}`), 0o600); err != nil {
+		t.Fatalf("Writing template file: %v", err)
+	}
+
+	templateFile := evalFuncTemplateFile(nil)
+
+	v, err := templateFile.Call([]cty.Value{
+		cty.StringVal(templatePath),
+		cty.ObjectVal(map[string]cty.Value{"n": cty.NumberIntVal(5)}),
+	})
+	if err != nil {
+		t.Fatalf("Rendering template with number result should succeed, got: %v", err)
+	}
+
+	if !v.RawEquals(cty.StringVal("5")) {
+		t.Fatalf("Expected number to be converted to string, got %#v", v)
+	}
+
+	if _, err := templateFile.Call([]cty.Value{
+		cty.StringVal(templatePath),
+		cty.ObjectVal(map[string]cty.Value{"n": cty.ListVal([]cty.Value{cty.StringVal("a")})}),
+	}); err == nil {
+		t.Fatal("Rendering template with list result should fail")
+	}
+}
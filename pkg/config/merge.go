@@ -0,0 +1,296 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// merge merges given configuration layer into the configuration. Values defined in
+// the layer take precedence:
+//
+//   - cluster and backend blocks with the same label and component and variable blocks
+//     with the same name are merged using mergeBodies. Blocks with different labels replace
+//     previously defined ones.
+//   - local values replace previously defined local values with the same name.
+func (r *RootConfig) merge(layer *RootConfig) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	switch {
+	case layer.Cluster == nil:
+	case r.Cluster == nil || r.Cluster.Name != layer.Cluster.Name:
+		r.Cluster = layer.Cluster
+	default:
+		body, bodyDiags := mergeBodies(r.Cluster.Config, layer.Cluster.Config)
+		diags = append(diags, bodyDiags...)
+		r.Cluster.Config = body
+	}
+
+	switch {
+	case layer.Backend == nil:
+	case r.Backend == nil || r.Backend.Name != layer.Backend.Name:
+		r.Backend = layer.Backend
+	default:
+		body, bodyDiags := mergeBodies(r.Backend.Config, layer.Backend.Config)
+		diags = append(diags, bodyDiags...)
+		r.Backend.Config = body
+	}
+
+	components := map[string]int{}
+	for i, c := range r.Components {
+		components[c.Name] = i
+	}
+
+	for _, c := range layer.Components {
+		i, ok := components[c.Name]
+		if !ok {
+			r.Components = append(r.Components, c)
+
+			continue
+		}
+
+		body, bodyDiags := mergeBodies(r.Components[i].Config, c.Config)
+		diags = append(diags, bodyDiags...)
+		r.Components[i].Config = body
+
+		// Duplicated components in a single layer should not be merged together.
+		delete(components, c.Name)
+	}
+
+	variables := map[string]int{}
+	for i, v := range r.Variables {
+		variables[v.Name] = i
+	}
+
+	for _, v := range layer.Variables {
+		i, ok := variables[v.Name]
+		if !ok {
+			// Duplicated declarations will be reported when evaluating variables.
+			r.Variables = append(r.Variables, v)
+
+			continue
+		}
+
+		body, bodyDiags := mergeBodies(r.Variables[i].Config, v.Config)
+		diags = append(diags, bodyDiags...)
+		r.Variables[i].Config = body
+
+		delete(variables, v.Name)
+	}
+
+	localsDiags := r.mergeLocals(layer.Locals)
+	diags = append(diags, localsDiags...)
+
+	return diags
+}
+
+// mergeLocals removes local values defined in given locals blocks from the configuration
+// and adds the blocks. This way duplicated local values are still reported within a single
+// configuration layer.
+func (r *RootConfig) mergeLocals(blocks []locals) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	overridden := map[string]struct{}{}
+
+	for _, block := range blocks {
+		attrs, attrsDiags := block.Config.JustAttributes()
+		diags = append(diags, attrsDiags...)
+
+		for name := range attrs {
+			overridden[name] = struct{}{}
+		}
+	}
+
+	merged := []locals{}
+
+	for _, block := range r.Locals {
+		body, ok := block.Config.(*hclsyntax.Body)
+		if !ok {
+			diags = append(diags, unsupportedBodyDiagnostic(block.Config))
+
+			continue
+		}
+
+		filtered := *body
+		filtered.Attributes = hclsyntax.Attributes{}
+
+		for name, attr := range body.Attributes {
+			if _, ok := overridden[name]; !ok {
+				filtered.Attributes[name] = attr
+			}
+		}
+
+		merged = append(merged, locals{Config: &filtered})
+	}
+
+	r.Locals = append(merged, blocks...)
+
+	return diags
+}
+
+// mergeBodies returns body with attributes and blocks of given override body merged into
+// given base body:
+//
+//   - Attributes from the override body replace attributes with the same name.
+//   - If both bodies have at most one block of given type with given labels, e.g. a single
+//     "worker_pool" block with label "pool-1", blocks are merged recursively. Blocks which
+//     exist only in the override body are added.
+//   - Otherwise, e.g. for lists of blocks without labels, all blocks of given type from the
+//     override body replace blocks of given type from the base body.
+func mergeBodies(base, override hcl.Body) (hcl.Body, hcl.Diagnostics) {
+	baseBody, ok := base.(*hclsyntax.Body)
+	if !ok {
+		return base, hcl.Diagnostics{unsupportedBodyDiagnostic(base)}
+	}
+
+	overrideBody, ok := override.(*hclsyntax.Body)
+	if !ok {
+		return base, hcl.Diagnostics{unsupportedBodyDiagnostic(override)}
+	}
+
+	return mergeSyntaxBodies(baseBody, overrideBody), nil
+}
+
+func mergeSyntaxBodies(base, override *hclsyntax.Body) *hclsyntax.Body {
+	// Copy the body to keep attributes and blocks hidden by partial decoding hidden.
+	merged := *base
+
+	merged.Attributes = hclsyntax.Attributes{}
+
+	for name, attr := range base.Attributes {
+		merged.Attributes[name] = attr
+	}
+
+	for name, attr := range override.Attributes {
+		merged.Attributes[name] = attr
+	}
+
+	merged.Blocks = mergeBlocks(base.Blocks, override.Blocks)
+
+	return &merged
+}
+
+func mergeBlocks(base, override hclsyntax.Blocks) hclsyntax.Blocks {
+	merged := append(hclsyntax.Blocks{}, base...)
+
+	types := []string{}
+	overrideBlocks := map[string]hclsyntax.Blocks{}
+
+	for _, block := range override {
+		if _, ok := overrideBlocks[block.Type]; !ok {
+			types = append(types, block.Type)
+		}
+
+		overrideBlocks[block.Type] = append(overrideBlocks[block.Type], block)
+	}
+
+	for _, blockType := range types {
+		blocks := overrideBlocks[blockType]
+
+		if !uniqueLabels(blocks) || !uniqueLabels(blocksOfType(merged, blockType)) {
+			merged = append(blocksWithoutType(merged, blockType), blocks...)
+
+			continue
+		}
+
+		for _, block := range blocks {
+			i := matchingBlock(merged, block)
+			if i < 0 {
+				merged = append(merged, block)
+
+				continue
+			}
+
+			mergedBlock := *merged[i]
+			mergedBlock.Body = mergeSyntaxBodies(merged[i].Body, block.Body)
+			merged[i] = &mergedBlock
+		}
+	}
+
+	return merged
+}
+
+// uniqueLabels returns true if all given blocks have different labels.
+func uniqueLabels(blocks hclsyntax.Blocks) bool {
+	for i, block := range blocks {
+		if matchingBlock(blocks[i+1:], block) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchingBlock returns index of the block with the same type and labels as given block
+// or -1, if there is no such block.
+func matchingBlock(blocks hclsyntax.Blocks, block *hclsyntax.Block) int {
+	for i, b := range blocks {
+		if b.Type == block.Type && equalLabels(b.Labels, block.Labels) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func blocksOfType(blocks hclsyntax.Blocks, blockType string) hclsyntax.Blocks {
+	filtered := hclsyntax.Blocks{}
+
+	for _, block := range blocks {
+		if block.Type == blockType {
+			filtered = append(filtered, block)
+		}
+	}
+
+	return filtered
+}
+
+func blocksWithoutType(blocks hclsyntax.Blocks, blockType string) hclsyntax.Blocks {
+	filtered := hclsyntax.Blocks{}
+
+	for _, block := range blocks {
+		if block.Type != blockType {
+			filtered = append(filtered, block)
+		}
+	}
+
+	return filtered
+}
+
+func unsupportedBodyDiagnostic(body hcl.Body) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unsupported configuration syntax",
+		Detail:   fmt.Sprintf("Configuration body of type %T can't be merged.", body),
+		Subject:  body.MissingItemRange().Ptr(),
+	}
+}
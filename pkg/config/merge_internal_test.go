@@ -0,0 +1,244 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// loadTestLayers writes given configuration layers to separate temporary directories
+// and loads them in order.
+func loadTestLayers(t *testing.T, layers ...string) *Config {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	paths := []string{}
+
+	for i, layer := range layers {
+		path := filepath.Join(dir, string(rune('a'+i)))
+
+		if err := os.Mkdir(path, 0o700); err != nil {
+			t.Fatalf("Creating directory %q: %v", path, err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(path, "test.lokocfg"), []byte(layer), 0o600); err != nil {
+			t.Fatalf("Writing configuration file: %v", err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	c, diags := LoadConfig(paths, filepath.Join(dir, "lokocfg.vars"))
+	if diags.HasErrors() {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", diags)
+	}
+
+	return c
+}
+
+const baseLayer = `
+variable "count" {
+  default = 1
+}
+
+locals {
+  name = "staging"
+  zone = "example.com"
+}
+
+cluster "test" {
+  name  = local.name
+  zone  = local.zone
+  count = var.count
+
+  pool "pool-1" {
+    count  = 1
+    labels = { "a" = "b" }
+  }
+
+  toleration {
+    key = "foo"
+  }
+
+  toleration {
+    key = "bar"
+  }
+}
+
+component "foo" {
+  enabled = false
+  version = "1.0"
+}
+`
+
+const overlayLayer = `
+variable "count" {
+  default = 3
+}
+
+locals {
+  name = "prod"
+}
+
+cluster "test" {
+  pool "pool-1" {
+    count = 3
+  }
+
+  pool "pool-2" {
+    count = 2
+  }
+
+  toleration {
+    key = "baz"
+  }
+}
+
+component "foo" {
+  enabled = true
+}
+
+component "bar" {}
+`
+
+type testPool struct {
+	Name   string            `hcl:"name,label"`
+	Count  int               `hcl:"count"`
+	Labels map[string]string `hcl:"labels,optional"`
+}
+
+type testToleration struct {
+	Key string `hcl:"key"`
+}
+
+type testCluster struct {
+	Name        string           `hcl:"name"`
+	Zone        string           `hcl:"zone"`
+	Count       int              `hcl:"count"`
+	Pools       []testPool       `hcl:"pool,block"`
+	Tolerations []testToleration `hcl:"toleration,block"`
+}
+
+func TestMergeLayers(t *testing.T) {
+	c := loadTestLayers(t, baseLayer, overlayLayer)
+
+	cluster := testCluster{}
+	if diags := gohcl.DecodeBody(c.RootConfig.Cluster.Config, c.EvalContext, &cluster); diags.HasErrors() {
+		t.Fatalf("Decoding merged cluster configuration should succeed, got: %v", diags)
+	}
+
+	expected := testCluster{
+		Name:  "prod",
+		Zone:  "example.com",
+		Count: 3,
+		Pools: []testPool{
+			{Name: "pool-1", Count: 3, Labels: map[string]string{"a": "b"}},
+			{Name: "pool-2", Count: 2},
+		},
+		Tolerations: []testToleration{{Key: "baz"}},
+	}
+
+	if !reflect.DeepEqual(cluster, expected) {
+		t.Fatalf("Expected merged cluster configuration %+v, got %+v", expected, cluster)
+	}
+
+	if len(c.RootConfig.Components) != 2 {
+		t.Fatalf("Expected 2 components, got %d", len(c.RootConfig.Components))
+	}
+
+	attrs, diags := (*c.LoadComponentConfigBody("foo")).JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("Getting component attributes should succeed, got: %v", diags)
+	}
+
+	for name, value := range map[string]cty.Value{"enabled": cty.True, "version": cty.StringVal("1.0")} {
+		if got, _ := attrs[name].Expr.Value(nil); !got.RawEquals(value) {
+			t.Errorf("Expected component attribute %q to be %#v, got %#v", name, value, got)
+		}
+	}
+}
+
+func TestMergeLayersReportsDuplicatedLocalsInSingleLayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	files := map[string]string{
+		"base.lokocfg":    `locals { a = 1 }`,
+		"overlay.lokocfg": "locals { a = 2 }\nlocals { a = 3 }",
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("Writing file %q: %v", name, err)
+		}
+	}
+
+	_, diags := LoadConfig([]string{filepath.Join(dir, "base.lokocfg"), filepath.Join(dir, "overlay.lokocfg")}, "")
+	if !strings.Contains(diags.Error(), "Duplicate local value definition") {
+		t.Fatalf("Expected duplicated local value to be reported, got: %v", diags)
+	}
+}
+
+func TestShow(t *testing.T) {
+	c := loadTestLayers(t, baseLayer, overlayLayer)
+
+	output, err := c.Show()
+	if err != nil {
+		t.Fatalf("Showing configuration should succeed, got: %v", err)
+	}
+
+	// Ignore alignment of attributes and comments.
+	shown := strings.Join(strings.Fields(string(output)), " ")
+
+	expected := []string{
+		"# 1. " + c.paths[0],
+		"# 2. " + c.paths[1],
+		`name = "prod" # ` + filepath.Join(c.paths[1], "test.lokocfg") + ":7",
+		`zone = "example.com" # ` + filepath.Join(c.paths[0], "test.lokocfg") + ":8",
+		`pool "pool-2" {`,
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(shown, e) {
+			t.Errorf("Expected output to contain %q, got:\n%s", e, output)
+		}
+	}
+}
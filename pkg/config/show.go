@@ -0,0 +1,159 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Show returns the merged configuration in HCL format. Every attribute is annotated with
+// the location, where it has been defined, to show which configuration path it has been
+// taken from. Attribute values are not evaluated, so values of variables are not included.
+func (c *Config) Show() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintln(buf, "# Configuration merged from the following paths, with later paths taking precedence:")
+
+	for i, path := range c.paths {
+		fmt.Fprintf(buf, "#   %d. %s\n", i+1, path)
+	}
+
+	r := c.RootConfig
+
+	for _, v := range r.Variables {
+		if err := c.showBlock(buf, "variable", []string{v.Name}, v.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.showLocals(buf); err != nil {
+		return nil, err
+	}
+
+	if r.Backend != nil {
+		if err := c.showBlock(buf, "backend", []string{r.Backend.Name}, r.Backend.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Cluster != nil {
+		if err := c.showBlock(buf, "cluster", []string{r.Cluster.Name}, r.Cluster.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, component := range r.Components {
+		if err := c.showBlock(buf, "component", []string{component.Name}, component.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	return hclwrite.Format(buf.Bytes()), nil
+}
+
+// showLocals writes all local values as a single locals block.
+func (c *Config) showLocals(buf *bytes.Buffer) error {
+	locals := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{},
+	}
+
+	for _, block := range c.RootConfig.Locals {
+		body, ok := block.Config.(*hclsyntax.Body)
+		if !ok {
+			return unsupportedBodyDiagnostic(block.Config)
+		}
+
+		for name, attr := range body.Attributes {
+			locals.Attributes[name] = attr
+		}
+	}
+
+	if len(locals.Attributes) == 0 {
+		return nil
+	}
+
+	return c.showBlock(buf, "locals", nil, locals)
+}
+
+func (c *Config) showBlock(buf *bytes.Buffer, blockType string, labels []string, body hcl.Body) error {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return unsupportedBodyDiagnostic(body)
+	}
+
+	fmt.Fprintln(buf)
+	c.showSyntaxBlock(buf, blockType, labels, syntaxBody)
+
+	return nil
+}
+
+func (c *Config) showSyntaxBlock(buf *bytes.Buffer, blockType string, labels []string, body *hclsyntax.Body) {
+	fmt.Fprint(buf, blockType)
+
+	for _, label := range labels {
+		fmt.Fprintf(buf, " %q", label)
+	}
+
+	fmt.Fprintln(buf, " {")
+
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		c.showAttribute(buf, body.Attributes[name])
+	}
+
+	for i, block := range body.Blocks {
+		if i > 0 || len(names) > 0 {
+			fmt.Fprintln(buf)
+		}
+
+		c.showSyntaxBlock(buf, block.Type, block.Labels, block.Body)
+	}
+
+	fmt.Fprintln(buf, "}")
+}
+
+// showAttribute writes given attribute with its source location. For single line values,
+// the location is written as a trailing comment. Multi-line values, like heredocs, can't
+// be followed by a comment, so the location is written above them.
+func (c *Config) showAttribute(buf *bytes.Buffer, attr *hclsyntax.Attribute) {
+	exprRange := attr.Expr.Range()
+	location := fmt.Sprintf("# %s:%d", exprRange.Filename, attr.SrcRange.Start.Line)
+
+	var expr string
+	if file, ok := c.files[exprRange.Filename]; ok {
+		expr = string(exprRange.SliceBytes(file.Bytes))
+	}
+
+	if strings.Contains(expr, "\n") {
+		fmt.Fprintf(buf, "%s\n%s = %s\n", location, attr.Name, expr)
+
+		return
+	}
+
+	fmt.Fprintf(buf, "%s = %s %s\n", attr.Name, expr, location)
+}
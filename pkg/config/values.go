@@ -12,13 +12,23 @@ import (
 
 // LoadValuesFile reads the file at the given path and parses it as a
 // "values file" (flat key.value HCL config) for later use in the
-// `EvalContext`.
+// `EvalContext`. Files encrypted using age or SOPS are decrypted in memory.
 //
 // Adapted from
 // https://github.com/hashicorp/terraform/blob/d4ac68423c4998279f33404db46809d27a5c2362/configs/parser_values.go#L8-L23
 func LoadValuesFile(path string) (map[string]cty.Value, hcl.Diagnostics) {
+	content, err := readFile(path)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  err.Error(),
+			},
+		}
+	}
+
 	hclParser := hclparse.NewParser()
-	varsFile, diags := hclParser.ParseHCLFile(path)
+	varsFile, diags := hclParser.ParseHCL(content, path)
 	if diags != nil {
 		return nil, diags
 	}
@@ -0,0 +1,305 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+type variable struct {
+	Name   string   `hcl:"name,label"`
+	Config hcl.Body `hcl:",remain"`
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "default"},
+		{Name: "description"},
+		{Name: "sensitive"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "validation"},
+	},
+}
+
+var validationSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "condition", Required: true},
+		{Name: "error_message", Required: true},
+	},
+}
+
+// legacyTypes maps quoted type names, which were accepted before type expressions
+// were supported, to types.
+var legacyTypes = map[string]cty.Type{
+	"string": cty.String,
+	"list":   cty.List(cty.DynamicPseudoType),
+	"map":    cty.Map(cty.DynamicPseudoType),
+}
+
+// variableDefinition is a decoded variable block.
+type variableDefinition struct {
+	name         string
+	description  string
+	typ          cty.Type
+	defaultValue *cty.Value
+	sensitive    bool
+	validations  []variableValidation
+	declRange    hcl.Range
+}
+
+type variableValidation struct {
+	condition    hcl.Expression
+	errorMessage string
+}
+
+// decodeVariable decodes the variable block. Attributes are evaluated without any
+// variables or functions.
+func decodeVariable(v variable) (*variableDefinition, hcl.Diagnostics) {
+	d := &variableDefinition{
+		name:      v.Name,
+		typ:       cty.DynamicPseudoType,
+		declRange: v.Config.MissingItemRange(),
+	}
+
+	content, diags := v.Config.Content(variableSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if attr, ok := content.Attributes["type"]; ok {
+		typ, typeDiags := variableType(attr.Expr)
+		diags = append(diags, typeDiags...)
+		d.typ = typ
+	}
+
+	if attr, ok := content.Attributes["description"]; ok {
+		diags = append(diags, decodeAttribute(attr, cty.String, func(v cty.Value) {
+			d.description = v.AsString()
+		})...)
+	}
+
+	if attr, ok := content.Attributes["sensitive"]; ok {
+		diags = append(diags, decodeAttribute(attr, cty.Bool, func(v cty.Value) {
+			d.sensitive = v.True()
+		})...)
+	}
+
+	if attr, ok := content.Attributes["default"]; ok {
+		defaultValue, valueDiags := attr.Expr.Value(nil)
+		diags = append(diags, valueDiags...)
+		d.defaultValue = &defaultValue
+	}
+
+	for _, block := range content.Blocks {
+		validation, validationDiags := decodeValidation(block)
+		diags = append(diags, validationDiags...)
+
+		if validation != nil {
+			d.validations = append(d.validations, *validation)
+		}
+	}
+
+	return d, diags
+}
+
+// variableType returns the type given by a type expression, like list(string), or by
+// a legacy quoted type name.
+func variableType(expr hcl.Expression) (cty.Type, hcl.Diagnostics) {
+	if v, diags := expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String && v.IsKnown() && !v.IsNull() {
+		if typ, ok := legacyTypes[v.AsString()]; ok {
+			return typ, nil
+		}
+
+		return cty.DynamicPseudoType, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable type",
+				Detail:   fmt.Sprintf("Quoted type %q is not supported, use a type expression like string or list(string).", v.AsString()), //nolint:lll
+				Subject:  expr.Range().Ptr(),
+			},
+		}
+	}
+
+	return typeexpr.TypeConstraint(expr)
+}
+
+// decodeAttribute evaluates given attribute without context, converts it to given type
+// and passes the value to given function, if it is not null.
+func decodeAttribute(attr *hcl.Attribute, typ cty.Type, set func(cty.Value)) hcl.Diagnostics {
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	v, err := convert.Convert(v, typ)
+	if err != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid %q attribute", attr.Name),
+				Detail:   fmt.Sprintf("Attribute %q must be %s: %v.", attr.Name, typ.FriendlyName(), err),
+				Subject:  attr.Expr.Range().Ptr(),
+			},
+		}
+	}
+
+	if !v.IsNull() {
+		set(v)
+	}
+
+	return nil
+}
+
+func decodeValidation(block *hcl.Block) (*variableValidation, hcl.Diagnostics) {
+	content, diags := block.Body.Content(validationSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	validation := &variableValidation{
+		condition: content.Attributes["condition"].Expr,
+	}
+
+	diags = append(diags, decodeAttribute(content.Attributes["error_message"], cty.String, func(v cty.Value) {
+		validation.errorMessage = v.AsString()
+	})...)
+
+	if !diags.HasErrors() && validation.errorMessage == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid validation error message",
+			Detail:   "Validation error message must not be empty.",
+			Subject:  content.Attributes["error_message"].Expr.Range().Ptr(),
+		})
+	}
+
+	return validation, diags
+}
+
+// value returns the value of the variable, which is either given by the user or the default
+// value, converted to the variable type and validated using variable validation rules.
+func (d *variableDefinition) value(userValue *cty.Value, functions map[string]function.Function) (cty.Value, hcl.Diagnostics) { //nolint:lll
+	v := userValue
+	if v == nil {
+		v = d.defaultValue
+	}
+
+	if v == nil {
+		detail := fmt.Sprintf("Variable %q has no default value, so a value must be set in the values file.", d.name)
+		if d.description != "" {
+			detail += fmt.Sprintf(" Variable description: %s", d.description)
+		}
+
+		return cty.NilVal, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "No value for required variable",
+				Detail:   detail,
+				Subject:  d.declRange.Ptr(),
+			},
+		}
+	}
+
+	value, err := convert.Convert(*v, d.typ)
+	if err != nil {
+		return cty.NilVal, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value for variable",
+				Detail:   fmt.Sprintf("Value of variable %q must be %s: %v.", d.name, d.typ.FriendlyName(), err),
+				Subject:  d.declRange.Ptr(),
+			},
+		}
+	}
+
+	return value, d.validate(value, functions)
+}
+
+// validate checks given value using the variable validation rules. Conditions can reference
+// only the variable itself.
+func (d *variableDefinition) validate(value cty.Value, functions map[string]function.Function) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				d.name: value,
+			}),
+		},
+		Functions: functions,
+	}
+
+	for _, validation := range d.validations {
+		result, resultDiags := validation.condition.Value(evalContext)
+		if resultDiags.HasErrors() {
+			diags = append(diags, resultDiags...)
+
+			continue
+		}
+
+		result, err := convert.Convert(result, cty.Bool)
+		if err != nil || result.IsNull() || !result.IsKnown() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid validation condition",
+				Detail:   "Validation condition must evaluate to true or false.",
+				Subject:  validation.condition.Range().Ptr(),
+			})
+
+			continue
+		}
+
+		if result.False() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value for variable",
+				Detail:   fmt.Sprintf("Variable %q: %s", d.name, validation.errorMessage),
+				Subject:  validation.condition.Range().Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
+
+// sensitiveStrings returns string representations of all primitive values, i.e. strings,
+// numbers and booleans, contained in given value.
+func sensitiveStrings(v cty.Value) []string {
+	strs := []string{}
+
+	//nolint:errcheck // The callback never returns an error.
+	cty.Walk(v, func(_ cty.Path, v cty.Value) (bool, error) {
+		if !v.IsKnown() || v.IsNull() || !v.Type().IsPrimitiveType() {
+			return true, nil
+		}
+
+		// Primitive values are always convertible to strings.
+		if s, err := convert.Convert(v, cty.String); err == nil {
+			strs = append(strs, s.AsString())
+		}
+
+		return true, nil
+	})
+
+	return strs
+}
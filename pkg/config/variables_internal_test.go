@@ -0,0 +1,214 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/kinvolk/lokomotive/internal/redact"
+)
+
+// loadTestConfig writes given configuration and values files to a temporary directory
+// and loads them.
+func loadTestConfig(t *testing.T, lokocfg, values string) (*Config, error) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lokomotive-config")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	files := map[string]string{
+		"test.lokocfg": lokocfg,
+		"lokocfg.vars": values,
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("Writing file %q: %v", name, err)
+		}
+	}
+
+	c, diags := LoadConfig([]string{dir}, filepath.Join(dir, "lokocfg.vars"))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return c, nil
+}
+
+func TestVariableValues(t *testing.T) {
+	lokocfg := `
+variable "legacy_type" {
+  type = "string"
+}
+
+variable "count" {
+  type        = number
+  description = "Number of workers."
+
+  validation {
+    condition     = var.count > 0
+    error_message = "Count must be positive."
+  }
+}
+
+variable "labels" {
+  type    = map(string)
+  default = {}
+}
+
+variable "optional" {
+  default = null
+}
+`
+
+	values := `
+legacy_type = 5
+count       = "3"
+`
+
+	c, err := loadTestConfig(t, lokocfg, values)
+	if err != nil {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", err)
+	}
+
+	expected := cty.ObjectVal(map[string]cty.Value{
+		"legacy_type": cty.StringVal("5"),
+		"count":       cty.NumberIntVal(3),
+		"labels":      cty.MapValEmpty(cty.String),
+		"optional":    cty.NullVal(cty.DynamicPseudoType),
+	})
+
+	if got := c.EvalContext.Variables["var"]; !got.RawEquals(expected) {
+		t.Fatalf("Expected variables %#v, got %#v", expected, got)
+	}
+}
+
+func TestVariablesAreInvalid(t *testing.T) {
+	cases := map[string]struct {
+		lokocfg string
+		values  string
+		err     string
+	}{
+		"when required variable has no value": {
+			lokocfg: `
+variable "token" {
+  description = "API token."
+}
+`,
+			err: "No value for required variable",
+		},
+		"when value can't be converted to variable type": {
+			lokocfg: `
+variable "count" {
+  type = number
+}
+`,
+			values: `count = "foo"`,
+			err:    "Invalid value for variable",
+		},
+		"when validation fails": {
+			lokocfg: `
+variable "count" {
+  validation {
+    condition     = var.count > 0
+    error_message = "Count must be positive."
+  }
+}
+`,
+			values: `count = 0`,
+			err:    "Count must be positive.",
+		},
+		"when variable block has unknown attribute": {
+			lokocfg: `
+variable "count" {
+  foo = "bar"
+}
+`,
+			values: `count = 0`,
+			err:    "Unsupported argument",
+		},
+		"when quoted type is not supported": {
+			lokocfg: `
+variable "count" {
+  type = "number"
+}
+`,
+			values: `count = 0`,
+			err:    "Invalid variable type",
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			_, err := loadTestConfig(t, c.lokocfg, c.values)
+			if err == nil {
+				t.Fatal("Loading configuration should fail")
+			}
+
+			if !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("Expected error containing %q, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestSensitiveVariableIsRedacted(t *testing.T) {
+	lokocfg := `
+variable "client_secret" {
+  sensitive = true
+}
+`
+
+	if _, err := loadTestConfig(t, lokocfg, `client_secret = "lokomotive-test-secret"`); err != nil {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", err)
+	}
+
+	if got := redact.String("secret: lokomotive-test-secret"); got != "secret: "+redact.Placeholder {
+		t.Fatalf("Sensitive value should be redacted, got %q", got)
+	}
+}
+
+func TestSensitiveNumberIsRedacted(t *testing.T) {
+	lokocfg := `
+variable "pin" {
+  type      = number
+  sensitive = true
+}
+`
+
+	if _, err := loadTestConfig(t, lokocfg, `pin = 97531`); err != nil {
+		t.Fatalf("Loading valid configuration should succeed, got: %v", err)
+	}
+
+	if got := redact.String("pin: 97531"); got != "pin: "+redact.Placeholder {
+		t.Fatalf("Sensitive value should be redacted, got %q", got)
+	}
+}
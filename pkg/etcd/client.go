@@ -0,0 +1,318 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etcd implements a minimal client for the etcd v3 JSON gateway, which is
+// used to inspect etcd members of self-hosted Lokomotive clusters.
+package etcd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// requestTimeout is the maximum time a single request to an etcd member may take.
+	requestTimeout = 10 * time.Second
+
+	healthPath     = "/health"
+	statusPath     = "/v3/maintenance/status"
+	memberListPath = "/v3/cluster/member/list"
+)
+
+// Client talks to etcd members using the etcd v3 JSON gateway.
+type Client struct {
+	endpoints  []string
+	httpClient *http.Client
+}
+
+// TLSFiles holds paths to PEM encoded certificates used to authenticate to etcd.
+type TLSFiles struct {
+	CACert     string
+	ClientCert string
+	ClientKey  string
+}
+
+// AssetDirTLSFiles returns etcd client certificates generated by Terraform in
+// the given cluster assets directory.
+func AssetDirTLSFiles(clusterAssetsDir string) TLSFiles {
+	return TLSFiles{
+		CACert:     filepath.Join(clusterAssetsDir, "tls", "etcd-client-ca.crt"),
+		ClientCert: filepath.Join(clusterAssetsDir, "tls", "etcd-client.crt"),
+		ClientKey:  filepath.Join(clusterAssetsDir, "tls", "etcd-client.key"),
+	}
+}
+
+// NewClient creates etcd client for given endpoints, authenticating using given certificates.
+func NewClient(endpoints []string, files TLSFiles) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no etcd endpoints given")
+	}
+
+	caCert, err := ioutil.ReadFile(files.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no valid certificates found in %q", files.CACert)
+	}
+
+	cert, err := tls.LoadX509KeyPair(files.ClientCert, files.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+
+	return &Client{
+		endpoints: endpoints,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      pool,
+					Certificates: []tls.Certificate{cert},
+					MinVersion:   tls.VersionTLS12,
+				},
+			},
+		},
+	}, nil
+}
+
+// Endpoints returns the list of configured etcd endpoints.
+func (c *Client) Endpoints() []string {
+	return c.endpoints
+}
+
+// MemberHealth describes the health of a single etcd member.
+type MemberHealth struct {
+	Endpoint string `json:"endpoint"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Healthy  bool   `json:"healthy"`
+	Leader   bool   `json:"leader"`
+	// RaftIndex is the current raft index of the member.
+	RaftIndex uint64 `json:"raftIndex"`
+	// RaftIndexLag is the difference between the highest raft index among members
+	// and the raft index of this member.
+	RaftIndexLag uint64 `json:"raftIndexLag"`
+	// DBSize is the size of the backend database in bytes.
+	DBSize int64  `json:"dbSize"`
+	Error  string `json:"error,omitempty"`
+}
+
+type responseHeader struct {
+	MemberID uint64 `json:"member_id,string"`
+}
+
+type healthResponse struct {
+	Health string `json:"health"`
+	Reason string `json:"reason"`
+}
+
+type statusResponse struct {
+	Header    responseHeader `json:"header"`
+	Version   string         `json:"version"`
+	DBSize    int64          `json:"dbSize,string"`
+	Leader    uint64         `json:"leader,string"`
+	RaftIndex uint64         `json:"raftIndex,string"`
+	Errors    []string       `json:"errors"`
+}
+
+type member struct {
+	ID         uint64   `json:"ID,string"`
+	Name       string   `json:"name"`
+	ClientURLs []string `json:"clientURLs"`
+}
+
+type memberListResponse struct {
+	Members []member `json:"members"`
+}
+
+// Health probes all endpoints and returns the health of each of them. Errors
+// reaching individual members are reported in the returned list. An error is only
+// returned if no member could be reached at all.
+func (c *Client) Health() ([]MemberHealth, error) {
+	members := map[uint64]string{}
+
+	var memberListErr error
+
+	for _, endpoint := range c.endpoints {
+		ml := &memberListResponse{}
+
+		if memberListErr = c.post(endpoint, memberListPath, ml); memberListErr == nil {
+			for _, m := range ml.Members {
+				members[m.ID] = m.Name
+			}
+
+			break
+		}
+	}
+
+	if memberListErr != nil {
+		return nil, fmt.Errorf("listing etcd members: %w", memberListErr)
+	}
+
+	result := []MemberHealth{}
+
+	var maxRaftIndex uint64
+
+	for _, endpoint := range c.endpoints {
+		mh, id := c.memberHealth(endpoint)
+		mh.Name = members[id]
+
+		if mh.RaftIndex > maxRaftIndex {
+			maxRaftIndex = mh.RaftIndex
+		}
+
+		result = append(result, mh)
+	}
+
+	for i := range result {
+		if result[i].Error == "" {
+			result[i].RaftIndexLag = maxRaftIndex - result[i].RaftIndex
+		}
+	}
+
+	return result, nil
+}
+
+// memberHealth returns the health of the member serving given endpoint and it's ID.
+func (c *Client) memberHealth(endpoint string) (MemberHealth, uint64) {
+	mh := MemberHealth{
+		Endpoint: endpoint,
+	}
+
+	hr := &healthResponse{}
+	if err := c.get(endpoint, healthPath, hr); err != nil {
+		mh.Error = err.Error()
+
+		return mh, 0
+	}
+
+	sr := &statusResponse{}
+	if err := c.post(endpoint, statusPath, sr); err != nil {
+		mh.Error = err.Error()
+
+		return mh, 0
+	}
+
+	mh.ID = formatID(sr.Header.MemberID)
+	mh.Leader = sr.Leader != 0 && sr.Leader == sr.Header.MemberID
+	mh.RaftIndex = sr.RaftIndex
+	mh.DBSize = sr.DBSize
+	mh.Healthy = hr.Health == "true"
+
+	switch {
+	case hr.Reason != "":
+		mh.Error = hr.Reason
+	case len(sr.Errors) > 0:
+		mh.Error = strings.Join(sr.Errors, ", ")
+	}
+
+	return mh, sr.Header.MemberID
+}
+
+func (c *Client) get(endpoint, path string, v interface{}) error {
+	resp, err := c.httpClient.Get(strings.TrimSuffix(endpoint, "/") + path)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	return decodeResponse(resp, v)
+}
+
+func (c *Client) post(endpoint, path string, v interface{}) error {
+	url := strings.TrimSuffix(endpoint, "/") + path
+
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBufferString("{}"))
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	return decodeResponse(resp, v)
+}
+
+func decodeResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	// etcd returns 503 with a valid body from /health when the member is unhealthy.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return fmt.Errorf("unexpected response status %q: %s", resp.Status, string(body))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
+
+func formatID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%x", id)
+}
+
+// AssetDirEndpoints returns client endpoints of etcd members, as configured for
+// kube-apiserver in the given cluster assets directory.
+func AssetDirEndpoints(clusterAssetsDir string) ([]string, error) {
+	path := filepath.Join(clusterAssetsDir, "charts", "kube-system", "kube-apiserver.yaml")
+
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading kube-apiserver values file: %w", err)
+	}
+
+	values := struct {
+		APIServer struct {
+			EtcdServers string `json:"etcdServers"`
+		} `json:"apiserver"`
+	}{}
+
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("parsing kube-apiserver values file %q: %w", path, err)
+	}
+
+	endpoints := []string{}
+
+	for _, e := range strings.Split(values.APIServer.EtcdServers, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no etcd servers found in %q", path)
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const memberList = `{"members":[{"ID":"10","name":"etcd0"},{"ID":"11","name":"etcd1"},{"ID":"12","name":"etcd2"}]}`
+
+// fakeMember returns a handler emulating etcd JSON gateway of a member with a given ID.
+func fakeMember(t *testing.T, id, leader, raftIndex int) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"health":"true"}`)
+	})
+
+	mux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST request, got %q", r.Method)
+		}
+
+		fmt.Fprintf(w, `{"header":{"member_id":"%d"},"dbSize":"1024","leader":"%d","raftIndex":"%d"}`,
+			id, leader, raftIndex)
+	})
+
+	mux.HandleFunc(memberListPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, memberList)
+	})
+
+	return mux
+}
+
+func TestHealth(t *testing.T) {
+	leader := httptest.NewTLSServer(fakeMember(t, 10, 10, 100))
+	defer leader.Close()
+
+	follower := httptest.NewTLSServer(fakeMember(t, 11, 10, 97))
+	defer follower.Close()
+
+	down := httptest.NewTLSServer(http.NotFoundHandler())
+	down.Close()
+
+	c := &Client{
+		endpoints:  []string{down.URL, leader.URL, follower.URL},
+		httpClient: leader.Client(),
+	}
+
+	members, err := c.Health()
+	if err != nil {
+		t.Fatalf("Getting health should succeed, got: %v", err)
+	}
+
+	if len(members) != 3 {
+		t.Fatalf("Expected 3 members, got: %v", members)
+	}
+
+	if members[0].Healthy || members[0].Error == "" {
+		t.Fatalf("Unreachable member should be reported as unhealthy with error, got: %+v", members[0])
+	}
+
+	// Ignore error from unreachable member, as it is not deterministic.
+	members[0].Error = ""
+
+	expected := []MemberHealth{
+		{
+			Endpoint: down.URL,
+		},
+		{
+			Endpoint:  leader.URL,
+			ID:        "a",
+			Name:      "etcd0",
+			Healthy:   true,
+			Leader:    true,
+			RaftIndex: 100,
+			DBSize:    1024,
+		},
+		{
+			Endpoint:     follower.URL,
+			ID:           "b",
+			Name:         "etcd1",
+			Healthy:      true,
+			RaftIndex:    97,
+			RaftIndexLag: 3,
+			DBSize:       1024,
+		},
+	}
+
+	if diff := cmp.Diff(expected, members); diff != "" {
+		t.Fatalf("Unexpected members health (-want +got)\n%s", diff)
+	}
+}
+
+func TestHealthNoReachableMembers(t *testing.T) {
+	down := httptest.NewTLSServer(http.NotFoundHandler())
+	down.Close()
+
+	c := &Client{
+		endpoints:  []string{down.URL},
+		httpClient: down.Client(),
+	}
+
+	if _, err := c.Health(); err == nil {
+		t.Fatalf("Getting health with no reachable members should fail")
+	}
+}
+
+func TestAssetDirEndpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lokoctl-tests-")
+	if err != nil {
+		t.Fatalf("Creating temporary directory should succeed, got: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory %q failed: %v", dir, err)
+		}
+	})
+
+	chartsDir := filepath.Join(dir, "charts", "kube-system")
+	if err := os.MkdirAll(chartsDir, 0o700); err != nil {
+		t.Fatalf("Creating charts directory should succeed, got: %v", err)
+	}
+
+	values := `apiserver:
+  etcdServers: https://foo-etcd0.example.com:2379,https://foo-etcd1.example.com:2379
+`
+
+	if err := ioutil.WriteFile(filepath.Join(chartsDir, "kube-apiserver.yaml"), []byte(values), 0o600); err != nil {
+		t.Fatalf("Writing values file should succeed, got: %v", err)
+	}
+
+	endpoints, err := AssetDirEndpoints(dir)
+	if err != nil {
+		t.Fatalf("Getting endpoints should succeed, got: %v", err)
+	}
+
+	expected := []string{"https://foo-etcd0.example.com:2379", "https://foo-etcd1.example.com:2379"}
+
+	if diff := cmp.Diff(expected, endpoints); diff != "" {
+		t.Fatalf("Unexpected endpoints (-want +got)\n%s", diff)
+	}
+}
@@ -77,6 +77,14 @@ func (m manifest) Name() string {
 	return m.name
 }
 
+func (m manifest) Namespace() string {
+	return m.namespace
+}
+
+func (m manifest) APIVersion() string {
+	return m.apiVersion
+}
+
 // LoadManifests parses a map of Kubernetes manifests.
 // Deprecated: YAMLToObjectMetadata should be used instead.
 func LoadManifests(files map[string]string) ([]manifest, error) {
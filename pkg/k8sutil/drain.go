@@ -0,0 +1,92 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/drain"
+)
+
+// DrainTimeout is the default timeout for evicting all pods from a node.
+const DrainTimeout = 10 * time.Minute
+
+// DrainOptions controls DrainNode() behavior.
+type DrainOptions struct {
+	// Force allows removing pods, which are not managed by any controller.
+	Force bool
+	// Timeout after which draining is aborted. If zero, DrainTimeout is used.
+	Timeout time.Duration
+	// Out receives progress messages. If nil, messages are discarded.
+	Out io.Writer
+}
+
+func drainHelper(ctx context.Context, client kubernetes.Interface, options DrainOptions) *drain.Helper {
+	out := options.Out
+	if out == nil {
+		out = ioutil.Discard
+	}
+
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = DrainTimeout
+	}
+
+	return &drain.Helper{
+		Ctx:                 ctx,
+		Client:              client,
+		Force:               options.Force,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  true,
+		Timeout:             timeout,
+		Out:                 out,
+		ErrOut:              out,
+	}
+}
+
+// CordonNode marks given node as schedulable or unschedulable.
+func CordonNode(ctx context.Context, client kubernetes.Interface, name string, cordon bool) error {
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting node %q: %w", name, err)
+	}
+
+	if err := drain.RunCordonOrUncordon(drainHelper(ctx, client, DrainOptions{}), node, cordon); err != nil {
+		return fmt.Errorf("updating node %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// DrainNode cordons given node and evicts all pods from it, except the ones managed by DaemonSets,
+// the same way as 'kubectl drain' does.
+func DrainNode(ctx context.Context, client kubernetes.Interface, name string, options DrainOptions) error {
+	if err := CordonNode(ctx, client, name, true); err != nil {
+		return fmt.Errorf("cordoning: %w", err)
+	}
+
+	if err := drain.RunNodeDrain(drainHelper(ctx, client, options), name); err != nil {
+		return fmt.Errorf("draining node %q: %w", name, err)
+	}
+
+	return nil
+}
@@ -18,7 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
+	"sort"
 	"text/tabwriter"
 	"time"
 
@@ -49,24 +49,6 @@ func NewCluster(client *kubernetes.Clientset, expectedNodes int) *Cluster {
 	return &Cluster{KubeClient: client, ExpectedNodes: expectedNodes}
 }
 
-func (cl *Cluster) Health() ([]v1.ComponentStatus, error) {
-	cs, err := cl.KubeClient.CoreV1().ComponentStatuses().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	// For now we only show the status of etcd.
-	var etcdComponents []v1.ComponentStatus
-
-	for _, item := range cs.Items {
-		if strings.HasPrefix(item.Name, "etcd") {
-			etcdComponents = append(etcdComponents, item)
-		}
-	}
-
-	return etcdComponents, nil
-}
-
 // NodeStatus represents the status of all nodes of a cluster.
 type NodeStatus struct {
 	nodeConditions map[string][]v1.NodeCondition
@@ -108,6 +90,47 @@ func (ns *NodeStatus) Ready() bool {
 	return true
 }
 
+// NodeReadiness describes the Ready condition of a single node.
+type NodeReadiness struct {
+	Name    string             `json:"name"`
+	Ready   v1.ConditionStatus `json:"ready"`
+	Reason  string             `json:"reason"`
+	Message string             `json:"message"`
+}
+
+// Nodes returns readiness of all cluster nodes sorted by name.
+func (ns *NodeStatus) Nodes() []NodeReadiness {
+	nodes := []NodeReadiness{}
+
+	for node, conditions := range ns.nodeConditions {
+		for _, condition := range conditions {
+			if condition.Type == "Ready" {
+				nodes = append(nodes, NodeReadiness{
+					Name:    node,
+					Ready:   condition.Status,
+					Reason:  condition.Reason,
+					Message: condition.Message,
+				})
+			}
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes
+}
+
+// MissingNodes returns number of nodes, which are expected, but not registered in the cluster.
+func (ns *NodeStatus) MissingNodes() int {
+	if len(ns.nodeConditions) >= ns.expectedNodes {
+		return 0
+	}
+
+	return ns.expectedNodes - len(ns.nodeConditions)
+}
+
 // PrettyPrint prints Node statuses in a pretty way.
 func (ns *NodeStatus) PrettyPrint() {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
@@ -118,19 +141,16 @@ func (ns *NodeStatus) PrettyPrint() {
 	// An empty line between header and the body.
 	fmt.Fprintln(w, "\t\t\t\t")
 
-	for node, conditions := range ns.nodeConditions {
-		for _, condition := range conditions {
-			if condition.Type == "Ready" {
-				line := fmt.Sprintf(
-					"%s\t%s\t%s\t%s\t",
-					node, condition.Status, condition.Reason, condition.Message,
-				)
-				fmt.Fprintln(w, line)
-			}
-		}
+	for _, node := range ns.Nodes() {
+		line := fmt.Sprintf(
+			"%s\t%s\t%s\t%s\t",
+			node.Name, node.Ready, node.Reason, node.Message,
+		)
+		fmt.Fprintln(w, line)
 	}
-	if len(ns.nodeConditions) < ns.expectedNodes {
-		line := fmt.Sprintf("%d nodes are missing", ns.expectedNodes-len(ns.nodeConditions))
+
+	if missing := ns.MissingNodes(); missing > 0 {
+		line := fmt.Sprintf("%d nodes are missing", missing)
 		fmt.Fprintln(w, line)
 	}
 
@@ -0,0 +1,131 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lokomotive
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KindDeployment is the kind of Deployment objects.
+	KindDeployment = "Deployment"
+	// KindDaemonSet is the kind of DaemonSet objects.
+	KindDaemonSet = "DaemonSet"
+	// KindStatefulSet is the kind of StatefulSet objects.
+	KindStatefulSet = "StatefulSet"
+)
+
+// WorkloadHealth describes readiness of a single workload.
+type WorkloadHealth struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Ready     bool   `json:"ready"`
+	Message   string `json:"message"`
+}
+
+// WorkloadHealth checks if the workload of a given kind is fully rolled out and all it's
+// replicas are ready. Missing workload is reported as not ready.
+func (cl *Cluster) WorkloadHealth(kind, namespace, name string) (WorkloadHealth, error) {
+	ctx := context.TODO()
+	apps := cl.KubeClient.AppsV1()
+
+	var (
+		wh  WorkloadHealth
+		err error
+	)
+
+	switch kind {
+	case KindDeployment:
+		var d *appsv1.Deployment
+
+		if d, err = apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			wh = deploymentHealth(d)
+		}
+	case KindDaemonSet:
+		var ds *appsv1.DaemonSet
+
+		if ds, err = apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			wh = daemonSetHealth(ds)
+		}
+	case KindStatefulSet:
+		var sts *appsv1.StatefulSet
+
+		if sts, err = apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			wh = statefulSetHealth(sts)
+		}
+	default:
+		return wh, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+
+	if apierrors.IsNotFound(err) {
+		wh.Message = "not found"
+		err = nil
+	}
+
+	if err != nil {
+		return wh, fmt.Errorf("getting %s %s/%s: %w", kind, namespace, name, err)
+	}
+
+	wh.Kind = kind
+	wh.Namespace = namespace
+	wh.Name = name
+
+	return wh, nil
+}
+
+func deploymentHealth(d *appsv1.Deployment) WorkloadHealth {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+
+	return WorkloadHealth{
+		Ready: d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedReplicas == replicas &&
+			d.Status.AvailableReplicas == replicas,
+		Message: fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, replicas),
+	}
+}
+
+func daemonSetHealth(ds *appsv1.DaemonSet) WorkloadHealth {
+	desired := ds.Status.DesiredNumberScheduled
+
+	return WorkloadHealth{
+		Ready: ds.Status.ObservedGeneration >= ds.Generation &&
+			ds.Status.UpdatedNumberScheduled == desired &&
+			ds.Status.NumberReady == desired,
+		Message: fmt.Sprintf("%d/%d pods ready", ds.Status.NumberReady, desired),
+	}
+}
+
+func statefulSetHealth(sts *appsv1.StatefulSet) WorkloadHealth {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	return WorkloadHealth{
+		Ready: sts.Status.ObservedGeneration >= sts.Generation &&
+			sts.Status.UpdatedReplicas == replicas &&
+			sts.Status.ReadyReplicas == replicas,
+		Message: fmt.Sprintf("%d/%d replicas ready", sts.Status.ReadyReplicas, replicas),
+	}
+}
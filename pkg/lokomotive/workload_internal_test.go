@@ -0,0 +1,116 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lokomotive
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestDeploymentHealth(t *testing.T) {
+	tests := map[string]struct {
+		deployment *appsv1.Deployment
+		ready      bool
+	}{
+		"all_replicas_available": {
+			deployment: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					UpdatedReplicas:   2,
+					AvailableReplicas: 2,
+				},
+			},
+			ready: true,
+		},
+		"rollout_in_progress": {
+			deployment: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					UpdatedReplicas:   1,
+					AvailableReplicas: 2,
+				},
+			},
+		},
+		"generation_not_observed": {
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1,
+					UpdatedReplicas:    1,
+					AvailableReplicas:  1,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := deploymentHealth(tc.deployment); got.Ready != tc.ready {
+				t.Fatalf("Expected ready to be %v, got %v (%s)", tc.ready, got.Ready, got.Message)
+			}
+		})
+	}
+}
+
+func TestDaemonSetHealth(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberReady:            2,
+		},
+	}
+
+	if daemonSetHealth(ds).Ready {
+		t.Fatalf("DaemonSet with not all pods ready should not be ready")
+	}
+
+	ds.Status.NumberReady = 3
+
+	if !daemonSetHealth(ds).Ready {
+		t.Fatalf("DaemonSet with all pods ready should be ready")
+	}
+}
+
+func TestStatefulSetHealth(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		Status: appsv1.StatefulSetStatus{
+			UpdatedReplicas: 3,
+			ReadyReplicas:   1,
+		},
+	}
+
+	if statefulSetHealth(sts).Ready {
+		t.Fatalf("StatefulSet with not all replicas ready should not be ready")
+	}
+
+	sts.Status.ReadyReplicas = 3
+
+	if !statefulSetHealth(sts).Ready {
+		t.Fatalf("StatefulSet with all replicas ready should be ready")
+	}
+}
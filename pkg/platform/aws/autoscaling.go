@@ -0,0 +1,64 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+// AutoscalingConfig describes the parts of the platform configuration, which are needed
+// to autoscale worker pools.
+type AutoscalingConfig struct {
+	ClusterName string
+	Region      string
+	// WorkerPools holds names of the worker pools with autoscaling enabled.
+	WorkerPools []string
+}
+
+// AutoscalingEnabled returns true, if the size of the worker pool autoscaling group is
+// managed by cluster-autoscaler. This is the case when 'min_count' or 'max_count' is set
+// and the group is allowed to change its size.
+func (w *workerPool) AutoscalingEnabled() bool {
+	min, max := w.bounds()
+
+	return (w.MinCount != nil || w.MaxCount != nil) && min != max
+}
+
+// autoscalingEnabled returns true, if any of the worker pools is autoscaled.
+func (c *config) autoscalingEnabled() bool {
+	for i := range c.WorkerPools {
+		if c.WorkerPools[i].AutoscalingEnabled() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Autoscaling returns the configuration needed to autoscale worker pools of the cluster.
+// Autoscaling groups of autoscaled worker pools are tagged with "k8s.io/cluster-autoscaler/enabled"
+// and "k8s.io/cluster-autoscaler/<cluster name>" tags, so cluster-autoscaler can discover them,
+// and controllers get permissions to scale them.
+func (c *config) Autoscaling() AutoscalingConfig {
+	ac := AutoscalingConfig{
+		ClusterName: c.ClusterName,
+		Region:      c.Region,
+		WorkerPools: []string{},
+	}
+
+	for i := range c.WorkerPools {
+		if c.WorkerPools[i].AutoscalingEnabled() {
+			ac.WorkerPools = append(ac.WorkerPools, c.WorkerPools[i].Name)
+		}
+	}
+
+	return ac
+}
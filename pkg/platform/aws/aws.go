@@ -33,6 +33,8 @@ import (
 type workerPool struct {
 	Name             string            `hcl:"pool_name,label"`
 	Count            int               `hcl:"count"`
+	MinCount         *int              `hcl:"min_count,optional"`
+	MaxCount         *int              `hcl:"max_count,optional"`
 	CPUManagerPolicy string            `hcl:"cpu_manager_policy,optional"`
 	SSHPubKeys       []string          `hcl:"ssh_pubkeys"`
 	InstanceType     string            `hcl:"instance_type,optional"`
@@ -51,6 +53,21 @@ type workerPool struct {
 	LBHTTPSPort      int               `hcl:"lb_https_port,optional"`
 }
 
+// bounds returns minimum and maximum size of the worker pool autoscaling group.
+func (w *workerPool) bounds() (int, int) {
+	min, max := w.Count, w.Count+2
+
+	if w.MinCount != nil {
+		min = *w.MinCount
+	}
+
+	if w.MaxCount != nil {
+		max = *w.MaxCount
+	}
+
+	return min, max
+}
+
 type config struct {
 	AssetDir                 string            `hcl:"asset_dir"`
 	ClusterName              string            `hcl:"cluster_name"`
@@ -149,6 +166,14 @@ func (c *config) Apply(ex *terraform.Executor) error {
 		return err
 	}
 
+	if err := c.migrateWorkerPoolModules(ex); err != nil {
+		return fmt.Errorf("migrating worker pool modules: %w", err)
+	}
+
+	if err := platform.PrepareScaleDown(ex, c.drainRemovedWorkers); err != nil {
+		return fmt.Errorf("draining removed workers: %w", err)
+	}
+
 	return ex.Apply([]string{terraform.WithParallelism})
 }
 
@@ -158,6 +183,14 @@ func (c *config) ApplyWithoutParallel(ex *terraform.Executor) error {
 		return fmt.Errorf("initializing Terraform configuration: %w", err)
 	}
 
+	if err := c.migrateWorkerPoolModules(ex); err != nil {
+		return fmt.Errorf("migrating worker pool modules: %w", err)
+	}
+
+	if err := platform.PrepareScaleDown(ex, c.drainRemovedWorkers); err != nil {
+		return fmt.Errorf("draining removed workers: %w", err)
+	}
+
 	return ex.Apply([]string{terraform.WithoutParallelism})
 }
 
@@ -166,6 +199,10 @@ func (c *config) Destroy(ex *terraform.Executor) error {
 		return err
 	}
 
+	if err := c.migrateWorkerPoolModules(ex); err != nil {
+		return fmt.Errorf("migrating worker pool modules: %w", err)
+	}
+
 	return ex.Destroy()
 }
 
@@ -259,12 +296,14 @@ func createTerraformConfigFile(cfg *config, terraformRootDir string) error {
 		WorkerCLCSnippets     string
 		WorkerTargetGroups    string
 		WorkerpoolCfg         []map[string]string
+		AutoscalingEnabled    bool
 	}{
 		Config:                *cfg,
 		Tags:                  string(tags),
 		SSHPublicKeys:         string(keyListBytes),
 		ControllerCLCSnippets: string(controllerCLCSnippetsBytes),
 		WorkerpoolCfg:         workerpoolCfgList,
+		AutoscalingEnabled:    cfg.autoscalingEnabled(),
 	}
 
 	if err := t.Execute(f, terraformCfg); err != nil {
@@ -283,6 +322,7 @@ func (c *config) checkValidConfig() hcl.Diagnostics {
 	diagnostics = append(diagnostics, c.checkNameSizes()...)
 	diagnostics = append(diagnostics, c.checkLBPortsUnique()...)
 	diagnostics = append(diagnostics, c.checkCPUManagerPolicy()...)
+	diagnostics = append(diagnostics, c.checkWorkerPoolCounts()...)
 
 	if c.ConntrackMaxPerCore < 0 {
 		diagnostics = append(diagnostics, &hcl.Diagnostic{
@@ -300,6 +340,26 @@ func (c *config) checkValidConfig() hcl.Diagnostics {
 	return diagnostics
 }
 
+// checkWorkerPoolCounts checks that the worker pool count is within the autoscaling group bounds.
+func (c *config) checkWorkerPoolCounts() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	for _, w := range c.WorkerPools {
+		min, max := w.bounds()
+
+		if min < 0 || min > w.Count || w.Count > max {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Worker pool count must be between min_count and max_count",
+				Detail: fmt.Sprintf("Worker pool %q has count %d, min_count %d and max_count %d",
+					w.Name, w.Count, min, max),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
 // checkLBPortsUnique checks that the lb_http_port and lb_https_port
 // flags have different values if the user is using multiple worker
 // pools.
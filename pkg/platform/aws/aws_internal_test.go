@@ -15,6 +15,7 @@
 package aws
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -183,6 +184,16 @@ func TestConfigurationIsInvalidWhen(t *testing.T) {
 		"conntrack_max_per_core_is_negative": func(c *config) {
 			c.ConntrackMaxPerCore = -1
 		},
+		"worker_pool_count_is_below_min_count": func(c *config) {
+			min := 2
+			c.WorkerPools[0].Count = 1
+			c.WorkerPools[0].MinCount = &min
+		},
+		"worker_pool_count_is_above_max_count": func(c *config) {
+			max := 2
+			c.WorkerPools[0].Count = 3
+			c.WorkerPools[0].MaxCount = &max
+		},
 	}
 
 	for n, c := range cases {
@@ -206,6 +217,12 @@ func TestConfigurationIsValidWhen(t *testing.T) {
 		"conntrack_max_per_core_is_a_positive_value": func(c *config) {
 			c.ConntrackMaxPerCore = 10
 		},
+		"worker_pool_can_scale_to_zero": func(c *config) {
+			min, max := 0, 10
+			c.WorkerPools[0].Count = 1
+			c.WorkerPools[0].MinCount = &min
+			c.WorkerPools[0].MaxCount = &max
+		},
 	}
 
 	for n, c := range cases {
@@ -222,3 +239,25 @@ func TestConfigurationIsValidWhen(t *testing.T) {
 		})
 	}
 }
+
+func TestAutoscalingIncludesOnlyAutoscaledWorkerPools(t *testing.T) {
+	c := validConfig()
+
+	fixed, max := 2, 5
+
+	c.WorkerPools = []workerPool{
+		{Name: "default", Count: 2},
+		{Name: "fixed", Count: 2, MinCount: &fixed, MaxCount: &fixed},
+		{Name: "autoscaled", Count: 2, MaxCount: &max},
+	}
+
+	ac := c.Autoscaling()
+
+	if !reflect.DeepEqual(ac.WorkerPools, []string{"autoscaled"}) {
+		t.Fatalf("Expected only autoscaled worker pool, got: %v", ac.WorkerPools)
+	}
+
+	if !c.autoscalingEnabled() {
+		t.Fatalf("Autoscaling should be enabled when any worker pool is autoscaled")
+	}
+}
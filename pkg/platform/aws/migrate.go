@@ -0,0 +1,108 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// indexedWorkerGroupRegexp matches autoscaling groups of worker pool modules addressed by the
+// pool index, as created by older versions of lokoctl.
+var indexedWorkerGroupRegexp = regexp.MustCompile(`^(module\.worker-pool-\d+)\.aws_autoscaling_group\.workers$`)
+
+// workerPoolModuleMoves returns addresses of worker pool modules addressed by the pool index,
+// mapped to the addresses using the pool name. Pools are identified by the name of their
+// autoscaling group. Modules of pools, which are no longer configured, are not moved.
+func workerPoolModuleMoves(resources []terraform.StateResource, pools []string) map[string]string {
+	configured := map[string]bool{}
+
+	for _, p := range pools {
+		configured[p] = true
+	}
+
+	// Modules existing in the state, e.g. "module.foo".
+	modules := map[string]bool{}
+
+	for _, r := range resources {
+		if parts := strings.SplitN(r.Address, ".", 3); parts[0] == "module" && len(parts) > 1 {
+			modules["module."+parts[1]] = true
+		}
+	}
+
+	moves := map[string]string{}
+
+	for _, r := range resources {
+		m := indexedWorkerGroupRegexp.FindStringSubmatch(r.Address)
+		if m == nil {
+			continue
+		}
+
+		name, _ := r.Values["name"].(string)
+		pool := strings.TrimSuffix(name, "-worker")
+
+		to := workerPoolModule(pool)
+
+		if !configured[pool] || modules[to] {
+			continue
+		}
+
+		moves[m[1]] = to
+	}
+
+	return moves
+}
+
+// workerPoolModule returns the address of the module of the worker pool with given name.
+func workerPoolModule(pool string) string {
+	return "module.worker-pool-" + pool
+}
+
+// migrateWorkerPoolModules moves worker pool modules addressed by the pool index to the
+// addresses using the pool name, so removing a pool does not recreate the pools after it.
+func (c *config) migrateWorkerPoolModules(ex *terraform.Executor) error {
+	resources, err := ex.StateResources()
+	if err != nil {
+		return fmt.Errorf("reading state resources: %w", err)
+	}
+
+	pools := []string{}
+
+	for _, p := range c.WorkerPools {
+		pools = append(pools, p.Name)
+	}
+
+	moves := workerPoolModuleMoves(resources, pools)
+
+	from := []string{}
+
+	for f := range moves {
+		from = append(from, f)
+	}
+
+	sort.Strings(from)
+
+	for _, f := range from {
+		if err := ex.MoveState(f, moves[f]); err != nil {
+			return fmt.Errorf("moving worker pool module %q: %w", f, err)
+		}
+	}
+
+	return nil
+}
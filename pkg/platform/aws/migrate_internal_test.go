@@ -0,0 +1,54 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+func TestWorkerPoolModuleMoves(t *testing.T) {
+	group := func(module, pool string) terraform.StateResource {
+		return terraform.StateResource{
+			Address: module + ".aws_autoscaling_group.workers",
+			Values: map[string]interface{}{
+				"name": pool + "-worker",
+			},
+		}
+	}
+
+	resources := []terraform.StateResource{
+		group("module.worker-pool-0", "foo"),
+		{Address: "module.worker-pool-0.aws_launch_configuration.worker"},
+		group("module.worker-pool-1", "bar"),
+		group("module.worker-pool-2", "removed"),
+		group("module.worker-pool-3", "migrated"),
+		group("module.worker-pool-migrated", "migrated"),
+		group("module.worker-pool-baz", "baz"),
+	}
+
+	expected := map[string]string{
+		"module.worker-pool-0": "module.worker-pool-foo",
+		"module.worker-pool-1": "module.worker-pool-bar",
+	}
+
+	moves := workerPoolModuleMoves(resources, []string{"foo", "bar", "migrated", "baz"})
+
+	if !reflect.DeepEqual(expected, moves) {
+		t.Fatalf("Expected moves %v, got %v", expected, moves)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// workerGroupRegexp matches Terraform addresses of worker pool autoscaling groups.
+var workerGroupRegexp = regexp.MustCompile(`^module\.worker-pool-[^.]+\.aws_autoscaling_group\.workers$`)
+
+// groupScaleDown describes planned removal of instances from the worker pool autoscaling group.
+type groupScaleDown struct {
+	name string
+	// remove is the number of instances which will be removed.
+	remove int
+	// minSize is the minimum size of the group after the change.
+	minSize int
+	// deleted is true, when the whole group is going to be destroyed.
+	deleted bool
+}
+
+type instance struct {
+	id         string
+	privateIP  string
+	launchTime time.Time
+}
+
+// shrinkingGroups returns worker pool autoscaling groups, which are going to be scaled down
+// or destroyed. Groups managed by cluster-autoscaler are only included when they are destroyed,
+// as their instances are added and removed by cluster-autoscaler.
+func shrinkingGroups(changes []terraform.ResourceChange, autoscaled map[string]bool) []groupScaleDown {
+	groups := []groupScaleDown{}
+
+	for _, rc := range changes {
+		if !workerGroupRegexp.MatchString(rc.Address) || len(rc.Actions) != 1 {
+			continue
+		}
+
+		name, _ := rc.Before["name"].(string)
+		before, _ := rc.Before["desired_capacity"].(float64)
+
+		switch rc.Actions[0] {
+		case "delete":
+			groups = append(groups, groupScaleDown{
+				name:    name,
+				remove:  int(before),
+				deleted: true,
+			})
+		case "update":
+			if autoscaled[name] {
+				continue
+			}
+
+			after, ok := rc.After["desired_capacity"].(float64)
+			if !ok || after >= before {
+				continue
+			}
+
+			minSize, _ := rc.After["min_size"].(float64)
+
+			groups = append(groups, groupScaleDown{
+				name:    name,
+				remove:  int(before - after),
+				minSize: int(minSize),
+			})
+		}
+	}
+
+	return groups
+}
+
+// newestInstances returns up to n most recently launched instances.
+func newestInstances(instances []instance, n int) []instance {
+	sorted := append([]instance{}, instances...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].launchTime.After(sorted[j].launchTime)
+	})
+
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+
+	return sorted[:n]
+}
+
+// instanceNodes returns names of the nodes running on given instances, matched by the
+// private IP address.
+func instanceNodes(nodes []corev1.Node, instances []instance) []string {
+	ips := map[string]bool{}
+	for _, i := range instances {
+		ips[i.privateIP] = true
+	}
+
+	names := []string{}
+
+	for _, n := range nodes {
+		for _, a := range n.Status.Addresses {
+			if a.Type == corev1.NodeInternalIP && ips[a.Address] {
+				names = append(names, n.Name)
+
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+// drainRemovedWorkers drains the nodes of instances, which are going to be removed from the worker
+// pool autoscaling groups. As autoscaling group selects instances to terminate on its own when
+// scaling down, drained instances are terminated here, so Terraform does not remove other ones.
+func (c *config) drainRemovedWorkers(ctx context.Context, cs kubernetes.Interface, changes []terraform.ResourceChange) error { //nolint:lll
+	groups := shrinkingGroups(changes, c.autoscaledGroups())
+	if len(groups) == 0 {
+		return nil
+	}
+
+	sess, err := c.awsSession()
+	if err != nil {
+		return err
+	}
+
+	asg := autoscaling.New(sess)
+
+	nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+
+	for _, g := range groups {
+		instances, err := groupInstances(asg, ec2.New(sess), g.name)
+		if err != nil {
+			return err
+		}
+
+		removed := newestInstances(instances, g.remove)
+
+		if err := platform.DrainNodes(ctx, cs, instanceNodes(nodes.Items, removed)); err != nil {
+			return err
+		}
+
+		// Terraform will destroy whole group.
+		if g.deleted {
+			continue
+		}
+
+		if err := terminateInstances(asg, g, removed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// autoscaledGroups returns names of autoscaling groups of worker pools with autoscaling enabled.
+func (c *config) autoscaledGroups() map[string]bool {
+	groups := map[string]bool{}
+
+	for i := range c.WorkerPools {
+		if c.WorkerPools[i].AutoscalingEnabled() {
+			groups[c.WorkerPools[i].Name+"-worker"] = true
+		}
+	}
+
+	return groups
+}
+
+func (c *config) awsSession() (*session.Session, error) {
+	options := session.Options{
+		Config:            *aws.NewConfig().WithRegion(c.Region),
+		SharedConfigState: session.SharedConfigEnable,
+	}
+
+	if c.CredsPath != "" {
+		options.SharedConfigFiles = []string{c.CredsPath}
+	}
+
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", err)
+	}
+
+	return sess, nil
+}
+
+// groupInstances returns running instances of given autoscaling group.
+func groupInstances(asg *autoscaling.AutoScaling, ec2c *ec2.EC2, name string) ([]instance, error) {
+	groups, err := asg.DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing autoscaling group %q: %w", name, err)
+	}
+
+	ids := []string{}
+
+	for _, g := range groups.AutoScalingGroups {
+		for _, i := range g.Instances {
+			if aws.StringValue(i.LifecycleState) == autoscaling.LifecycleStateInService {
+				ids = append(ids, aws.StringValue(i.InstanceId))
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	reservations, err := ec2c.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice(ids),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing instances of autoscaling group %q: %w", name, err)
+	}
+
+	instances := []instance{}
+
+	for _, r := range reservations.Reservations {
+		for _, i := range r.Instances {
+			instances = append(instances, instance{
+				id:         aws.StringValue(i.InstanceId),
+				privateIP:  aws.StringValue(i.PrivateIpAddress),
+				launchTime: aws.TimeValue(i.LaunchTime),
+			})
+		}
+	}
+
+	return instances, nil
+}
+
+// terminateInstances terminates given instances of the autoscaling group, decreasing its
+// desired capacity, so no replacement instances are launched.
+func terminateInstances(asg *autoscaling.AutoScaling, g groupScaleDown, instances []instance) error {
+	// Desired capacity can't be lower than the minimum size of the group.
+	if _, err := asg.UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(g.name),
+		MinSize:              aws.Int64(int64(g.minSize)),
+	}); err != nil {
+		return fmt.Errorf("updating minimum size of autoscaling group %q: %w", g.name, err)
+	}
+
+	for _, i := range instances {
+		if _, err := asg.TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+			InstanceId:                     aws.String(i.id),
+			ShouldDecrementDesiredCapacity: aws.Bool(true),
+		}); err != nil {
+			return fmt.Errorf("terminating instance %q: %w", i.id, err)
+		}
+	}
+
+	return nil
+}
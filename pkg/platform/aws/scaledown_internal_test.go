@@ -0,0 +1,114 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+func TestShrinkingGroups(t *testing.T) {
+	changes := []terraform.ResourceChange{
+		{
+			Address: "module.worker-pool-0.aws_autoscaling_group.workers",
+			Actions: []string{"update"},
+			Before:  map[string]interface{}{"name": "foo-worker", "desired_capacity": 3.0, "min_size": 3.0},
+			After:   map[string]interface{}{"name": "foo-worker", "desired_capacity": 1.0, "min_size": 1.0},
+		},
+		{
+			Address: "module.worker-pool-1.aws_autoscaling_group.workers",
+			Actions: []string{"update"},
+			Before:  map[string]interface{}{"name": "bar-worker", "desired_capacity": 1.0, "min_size": 1.0},
+			After:   map[string]interface{}{"name": "bar-worker", "desired_capacity": 2.0, "min_size": 2.0},
+		},
+		{
+			Address: "module.worker-pool-2.aws_autoscaling_group.workers",
+			Actions: []string{"delete"},
+			Before:  map[string]interface{}{"name": "baz-worker", "desired_capacity": 2.0, "min_size": 2.0},
+		},
+		{
+			Address: "module.worker-pool-3.aws_autoscaling_group.workers",
+			Actions: []string{"update"},
+			Before:  map[string]interface{}{"name": "qux-worker", "desired_capacity": 5.0, "min_size": 1.0},
+			After:   map[string]interface{}{"name": "qux-worker", "desired_capacity": 1.0, "min_size": 1.0},
+		},
+		{
+			Address: "module.worker-pool-0.aws_launch_configuration.worker",
+			Actions: []string{"delete"},
+		},
+	}
+
+	expected := []groupScaleDown{
+		{name: "foo-worker", remove: 2, minSize: 1},
+		{name: "baz-worker", remove: 2, deleted: true},
+	}
+
+	autoscaled := map[string]bool{"qux-worker": true}
+
+	if got := shrinkingGroups(changes, autoscaled); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestNewestInstances(t *testing.T) {
+	now := time.Now()
+
+	instances := []instance{
+		{id: "a", launchTime: now.Add(-2 * time.Hour)},
+		{id: "b", launchTime: now},
+		{id: "c", launchTime: now.Add(-time.Hour)},
+	}
+
+	got := newestInstances(instances, 2)
+
+	if len(got) != 2 || got[0].id != "b" || got[1].id != "c" {
+		t.Fatalf("Expected instances b and c, got %+v", got)
+	}
+
+	if got := newestInstances(instances, 5); len(got) != 3 {
+		t.Fatalf("Expected all 3 instances, got %+v", got)
+	}
+}
+
+func TestInstanceNodes(t *testing.T) {
+	node := func(name, ip string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: ip},
+				},
+			},
+		}
+	}
+
+	nodes := []corev1.Node{
+		node("ip-10-0-0-1", "10.0.0.1"),
+		node("ip-10-0-0-2", "10.0.0.2"),
+	}
+
+	expected := []string{"ip-10-0-0-2"}
+
+	got := instanceNodes(nodes, []instance{{privateIP: "10.0.0.2"}, {privateIP: "10.0.0.3"}})
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+}
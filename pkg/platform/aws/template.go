@@ -23,6 +23,9 @@ module "aws-{{.Config.ClusterName}}" {
   dns_zone     = "{{.Config.DNSZone}}"
   dns_zone_id  = "{{.Config.DNSZoneID}}"
   enable_csi   = {{.Config.EnableCSI}}
+  {{- if .AutoscalingEnabled }}
+  enable_autoscaling = true
+  {{- end }}
   {{- if .Config.ClusterDomainSuffix }}
   cluster_domain_suffix = "{{.Config.ClusterDomainSuffix}}"
   {{- end }}
@@ -108,13 +111,13 @@ module "aws-{{.Config.ClusterName}}" {
 
   worker_bootstrap_tokens = [
     {{- range $index, $pool := .Config.WorkerPools }}
-    module.worker-pool-{{ $index }}.worker_bootstrap_token,
+    module.worker-pool-{{ $pool.Name }}.worker_bootstrap_token,
     {{- end }}
   ]
 }
 
 {{ range $index, $pool := .Config.WorkerPools }}
-module "worker-pool-{{ $index }}" {
+module "worker-pool-{{ $pool.Name }}" {
   source = "../terraform-modules/aws/flatcar-linux/kubernetes/workers"
 
   enable_csi            = {{ $.Config.EnableCSI }}
@@ -138,6 +141,15 @@ module "worker-pool-{{ $index }}" {
   cluster_name          = "{{ $.Config.ClusterName }}"
   pool_name             = "{{ $pool.Name }}"
   worker_count          = "{{ $pool.Count}}"
+  {{- if $pool.MinCount }}
+  min_count             = {{ $pool.MinCount }}
+  {{- end }}
+  {{- if $pool.MaxCount }}
+  max_count             = {{ $pool.MaxCount }}
+  {{- end }}
+  {{- if $pool.AutoscalingEnabled }}
+  enable_autoscaling    = true
+  {{- end }}
   {{- if $pool.InstanceType }}
   instance_type         = "{{ $pool.InstanceType }}"
   {{- end }}
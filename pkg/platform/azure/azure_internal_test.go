@@ -0,0 +1,216 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	lokoconfig "github.com/kinvolk/lokomotive/pkg/config"
+)
+
+// loadConfigFromString loads config from string.
+func loadConfigFromString(t *testing.T, c string) (*config, hcl.Diagnostics) {
+	p := hclparse.NewParser()
+
+	f, d := p.ParseHCL([]byte(c), "x.lokocfg")
+	if d.HasErrors() {
+		t.Fatalf("parsing HCL should succeed, got: %v", d)
+	}
+
+	configBody := hcl.MergeFiles([]*hcl.File{f})
+
+	var rootConfig lokoconfig.RootConfig
+
+	if d := gohcl.DecodeBody(configBody, nil, &rootConfig); d.HasErrors() {
+		t.Fatalf("decoding root config should succeed, got: %v", d)
+	}
+
+	cc := NewConfig()
+
+	return cc, cc.LoadConfig(&rootConfig.Cluster.Config, &hcl.EvalContext{})
+}
+
+const testConfig = `
+cluster "azure" {
+  asset_dir                        = "/fooo"
+  cluster_name                     = "mycluster"
+  dns_zone                         = "example.com"
+  dns_zone_group                   = "example-group"
+  custom_image_resource_group_name = "images"
+  custom_image_name                = "flatcar"
+  ssh_pubkeys                      = ["testkey"]
+
+  oidc {
+    issuer_url = "https://dex.example.com"
+  }
+
+  worker_pool "foo" {
+    count       = 2
+    ssh_pubkeys = ["testkey"]
+    vm_type     = "Standard_DS2_v2"
+    priority    = "Low"
+    labels = {
+      "testlabel" = "testvalue"
+    }
+  }
+}
+`
+
+func TestLoadConfig(t *testing.T) {
+	c, d := loadConfigFromString(t, testConfig)
+	if d.HasErrors() {
+		t.Fatalf("valid config should not return error, got: %v", d)
+	}
+
+	if c.Region != "West Europe" {
+		t.Errorf("expected default region to be set, got %q", c.Region)
+	}
+
+	if c.Meta().ExpectedNodes != 3 {
+		t.Errorf("expected 3 nodes, got %d", c.Meta().ExpectedNodes)
+	}
+}
+
+func TestCreateTerraformConfigFile(t *testing.T) {
+	c, d := loadConfigFromString(t, testConfig)
+	if d.HasErrors() {
+		t.Fatalf("valid config should not return error, got: %v", d)
+	}
+
+	dir, err := ioutil.TempDir("", "lokoctl-azure")
+	if err != nil {
+		t.Fatalf("creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("removing temporary directory: %v", err)
+		}
+	})
+
+	if err := createTerraformConfigFile(c, dir); err != nil {
+		t.Fatalf("creating Terraform configuration should succeed, got: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "cluster.tf"))
+	if err != nil {
+		t.Fatalf("reading rendered Terraform configuration: %v", err)
+	}
+
+	for _, expected := range []string{
+		`module "azure-mycluster"`,
+		`name                    = "mycluster-foo"`,
+		`vm_type = "Standard_DS2_v2"`,
+		`priority = "Low"`,
+		`"testlabel" = "testvalue"`,
+		`--oidc-issuer-url=https://dex.example.com`,
+		`module.worker-pool-0.worker_bootstrap_token`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected rendered configuration to contain %q, got:\n%s", expected, b)
+		}
+	}
+}
+
+func validConfig() *config {
+	return &config{
+		ClusterName: "mycluster",
+		WorkerPools: []workerPool{
+			{
+				Name: "pool-1",
+			},
+		},
+	}
+}
+
+func TestConfigurationIsInvalidWhen(t *testing.T) {
+	cases := map[string]func(c *config){
+		"conntrack_max_per_core_is_negative": func(c *config) {
+			c.ConntrackMaxPerCore = -1
+		},
+		"no_worker_pools_are_defined": func(c *config) {
+			c.WorkerPools = nil
+		},
+		"worker_pool_names_are_duplicated": func(c *config) {
+			c.WorkerPools = append(c.WorkerPools, workerPool{Name: "pool-1"})
+		},
+		"cluster_name_has_uppercase_letters": func(c *config) {
+			c.ClusterName = "MyCluster"
+		},
+		"worker_pool_priority_is_invalid": func(c *config) {
+			c.WorkerPools[0].Priority = "Spot"
+		},
+		"worker_pool_name_ends_with_dash": func(c *config) {
+			c.WorkerPools[0].Name = "pool-"
+		},
+		"worker_pool_name_is_too_long_with_cluster_name": func(c *config) {
+			c.WorkerPools[0].Name = strings.Repeat("a", 41)
+		},
+		"cpu_manager_policy_is_invalid": func(c *config) {
+			c.WorkerPools[0].CPUManagerPolicy = "foo"
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			config := validConfig()
+
+			c(config)
+
+			if d := config.checkValidConfig(); !d.HasErrors() {
+				t.Fatalf("Validating configuration did not return expected error")
+			}
+		})
+	}
+}
+
+func TestConfigurationIsValidWhen(t *testing.T) {
+	cases := map[string]func(c *config){
+		"all_required_fields_are_set": func(c *config) {},
+		"conntrack_max_per_core_is_a_positive_value": func(c *config) {
+			c.ConntrackMaxPerCore = 10
+		},
+		"worker_pool_priority_is_low": func(c *config) {
+			c.WorkerPools[0].Priority = "Low"
+		},
+		"worker_pool_name_has_maximum_length": func(c *config) {
+			c.WorkerPools[0].Name = strings.Repeat("a", 40)
+		},
+	}
+
+	for n, c := range cases {
+		c := c
+
+		t.Run(n, func(t *testing.T) {
+			config := validConfig()
+
+			c(config)
+
+			if d := config.checkValidConfig(); d.HasErrors() {
+				t.Fatalf("Validating configuration returned expected error: %v", d)
+			}
+		})
+	}
+}
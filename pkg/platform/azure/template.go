@@ -0,0 +1,233 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+var terraformConfigTmpl = `
+module "azure-{{.Config.ClusterName}}" {
+  source = "../terraform-modules/azure/flatcar-linux/kubernetes"
+
+  cluster_name   = "{{.Config.ClusterName}}"
+  region         = "{{.Config.Region}}"
+  dns_zone       = "{{.Config.DNSZone}}"
+  dns_zone_group = "{{.Config.DNSZoneGroup}}"
+
+  custom_image_resource_group_name = "{{.Config.CustomImageResourceGroupName}}"
+  custom_image_name                = "{{.Config.CustomImageName}}"
+
+  {{- if .Config.ClusterDomainSuffix }}
+  cluster_domain_suffix = "{{.Config.ClusterDomainSuffix}}"
+  {{- end }}
+
+  ssh_keys  = {{$.SSHPublicKeys}}
+  asset_dir = "../cluster-assets"
+
+  {{- if .Config.ControllerCount}}
+  controller_count = {{.Config.ControllerCount}}
+  {{- end }}
+
+  {{- if .Config.ControllerType}}
+  controller_type  = "{{.Config.ControllerType}}"
+  {{- end }}
+
+  {{- if .Config.DiskSize }}
+  disk_size = {{.Config.DiskSize}}
+  {{- end }}
+
+  {{- if ne .ControllerCLCSnippets "null" }}
+  controller_clc_snippets = {{.ControllerCLCSnippets}}
+  {{- end }}
+
+  {{- if .Config.NetworkMTU }}
+  network_mtu = {{.Config.NetworkMTU}}
+  {{- end }}
+  enable_reporting = {{.Config.EnableReporting}}
+  {{- if .Config.HostCIDR }}
+  host_cidr = "{{.Config.HostCIDR}}"
+  {{- end }}
+  {{- if .Config.PodCIDR }}
+  pod_cidr = "{{.Config.PodCIDR}}"
+  {{- end }}
+  {{- if .Config.ServiceCIDR }}
+  service_cidr = "{{.Config.ServiceCIDR}}"
+  {{- end }}
+
+  enable_aggregation = {{.Config.EnableAggregation}}
+
+  {{- if .Config.CertsValidityPeriodHours }}
+  certs_validity_period_hours = {{.Config.CertsValidityPeriodHours}}
+  {{- end }}
+
+  disable_self_hosted_kubelet = {{ .Config.DisableSelfHostedKubelet }}
+
+  {{- if .Config.KubeAPIServerExtraFlags }}
+  kube_apiserver_extra_flags = [
+    {{- range .Config.KubeAPIServerExtraFlags }}
+    "{{ . }}",
+    {{- end }}
+  ]
+  {{- end }}
+
+  enable_tls_bootstrap = {{ .Config.EnableTLSBootstrap }}
+
+  {{- if .Config.EncryptPodTraffic }}
+  encrypt_pod_traffic = {{.Config.EncryptPodTraffic}}
+  {{- end }}
+
+  ignore_x509_cn_check = {{.Config.IgnoreX509CNCheck}}
+
+  conntrack_max_per_core = {{.Config.ConntrackMaxPerCore}}
+
+  enable_node_local_dns = {{.Config.EnableNodeLocalDNS}}
+  node_local_dns_ip     = "{{.Config.NodeLocalDNSIP}}"
+
+  worker_bootstrap_tokens = [
+    {{- range $index, $pool := .Config.WorkerPools }}
+    module.worker-pool-{{ $index }}.worker_bootstrap_token,
+    {{- end }}
+  ]
+}
+
+{{ range $index, $pool := .Config.WorkerPools }}
+module "worker-pool-{{ $index }}" {
+  source = "../terraform-modules/azure/flatcar-linux/kubernetes/workers"
+
+  name                    = "{{ $.Config.ClusterName }}-{{ $pool.Name }}"
+  resource_group_name     = module.azure-{{ $.Config.ClusterName }}.resource_group_name
+  region                  = module.azure-{{ $.Config.ClusterName }}.region
+  subnet_id               = module.azure-{{ $.Config.ClusterName }}.subnet_id
+  security_group_id       = module.azure-{{ $.Config.ClusterName }}.security_group_id
+  backend_address_pool_id = module.azure-{{ $.Config.ClusterName }}.backend_address_pool_id
+
+  custom_image_resource_group_name = "{{ $.Config.CustomImageResourceGroupName }}"
+  custom_image_name                = "{{ $.Config.CustomImageName }}"
+
+  kubeconfig           = module.azure-{{ $.Config.ClusterName }}.kubeconfig
+  ca_cert              = module.azure-{{ $.Config.ClusterName }}.ca_cert
+  apiserver            = module.azure-{{ $.Config.ClusterName }}.apiserver
+  enable_tls_bootstrap = {{ $.Config.EnableTLSBootstrap }}
+
+  {{- if $.Config.ServiceCIDR }}
+  service_cidr          = "{{ $.Config.ServiceCIDR }}"
+  {{- end }}
+
+  {{- if $.Config.ClusterDomainSuffix }}
+  cluster_domain_suffix = "{{ $.Config.ClusterDomainSuffix }}"
+  {{- end }}
+
+  ssh_keys     = {{ (index $.WorkerpoolCfg $index "ssh_pub_keys") }}
+  worker_count = {{ $pool.Count }}
+
+  {{- if $pool.VMType }}
+  vm_type = "{{ $pool.VMType }}"
+  {{- end }}
+
+  {{- if $pool.Priority }}
+  priority = "{{ $pool.Priority }}"
+  {{- end }}
+
+  {{- if $pool.DiskSize }}
+  disk_size = {{ $pool.DiskSize }}
+  {{- end }}
+
+  {{- if $pool.Labels }}
+  labels = {
+  {{- range $k, $v := $pool.Labels }}
+    "{{ $k }}" = "{{ $v }}",
+  {{- end }}
+  }
+  {{- end }}
+
+  {{- if $pool.Taints }}
+  taints = {
+  {{- range $k, $v := $pool.Taints }}
+    "{{ $k }}" = "{{ $v }}",
+  {{- end }}
+  }
+  {{- end}}
+
+  {{- if $pool.CLCSnippets }}
+  clc_snippets = {{ (index $.WorkerpoolCfg $index "clc_snippets") }}
+  {{- end }}
+
+  {{- if $pool.CPUManagerPolicy }}
+  cpu_manager_policy = "{{$pool.CPUManagerPolicy}}"
+  {{- end}}
+}
+{{- end }}
+
+provider "azurerm" {
+  features {}
+  {{- if .Config.SubscriptionID }}
+  subscription_id = "{{.Config.SubscriptionID}}"
+  {{- end }}
+  {{- if .Config.TenantID }}
+  tenant_id       = "{{.Config.TenantID}}"
+  {{- end }}
+}
+
+# Stub output, which indicates, that Terraform run at least once.
+# Used when checking, if we should ask user for confirmation, when
+# applying changes to the cluster.
+output "initialized" {
+  value     = true
+  sensitive = true
+}
+
+# values.yaml content for all deployed charts.
+output "pod-checkpointer_values" {
+  value     = module.azure-{{.Config.ClusterName}}.pod-checkpointer_values
+  sensitive = true
+}
+
+output "kube-apiserver_values" {
+  value     = module.azure-{{.Config.ClusterName}}.kube-apiserver_values
+  sensitive = true
+}
+
+output "kubernetes_values" {
+  value     = module.azure-{{.Config.ClusterName}}.kubernetes_values
+  sensitive = true
+}
+
+output "kubelet_values" {
+  value     = module.azure-{{.Config.ClusterName}}.kubelet_values
+  sensitive = true
+}
+
+output "calico_values" {
+  value     = module.azure-{{.Config.ClusterName}}.calico_values
+  sensitive = true
+}
+
+output "lokomotive_values" {
+  value     = module.azure-{{.Config.ClusterName}}.lokomotive_values
+  sensitive = true
+}
+
+output "bootstrap-secrets_values" {
+  value     = module.azure-{{.Config.ClusterName}}.bootstrap-secrets_values
+  sensitive = true
+}
+
+output "node-local-dns_values" {
+  value     = module.azure-{{.Config.ClusterName}}.node-local-dns_values
+  sensitive = true
+}
+
+output "kubeconfig" {
+  value     = module.azure-{{.Config.ClusterName}}.kubeconfig-admin
+  sensitive = true
+}
+`
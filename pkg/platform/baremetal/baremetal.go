@@ -17,8 +17,11 @@ package baremetal
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/hashicorp/hcl/v2"
@@ -54,9 +57,10 @@ type config struct {
 	IgnoreWorkerChanges          bool                `hcl:"ignore_worker_changes,optional"`
 	InstallPreBootCmds           string              `hcl:"install_pre_reboot_cmds,optional"`
 	SSHPubKeys                   []string            `hcl:"ssh_pubkeys"`
-	WorkerNames                  []string            `hcl:"worker_names"`
-	WorkerMacs                   []string            `hcl:"worker_macs"`
-	WorkerDomains                []string            `hcl:"worker_domains"`
+	WorkerNames                  []string            `hcl:"worker_names,optional"`
+	WorkerMacs                   []string            `hcl:"worker_macs,optional"`
+	WorkerDomains                []string            `hcl:"worker_domains,optional"`
+	WorkerPools                  []workerPool        `hcl:"worker_pool,block"`
 	Labels                       Labels              `hcl:"labels,optional"`
 	NodeSpecificLabels           map[string]Labels   `hcl:"node_specific_labels,optional"`
 	OIDC                         *oidc.Config        `hcl:"oidc,block"`
@@ -75,7 +79,36 @@ type config struct {
 	WipeAdditionalDisks          bool                `hcl:"wipe_additional_disks,optional"`
 	EnableNodeLocalDNS           bool                `hcl:"enable_node_local_dns,optional"`
 	NodeLocalDNSIP               string              `hcl:"node_local_dns_ip,optional"`
+	InventoryFile                string              `hcl:"inventory_file,optional"`
 	KubeAPIServerExtraFlags      []string
+	NodeSpecificInstallDisks     map[string]string
+
+	inventory *inventory
+}
+
+// workerPool represents a group of workers sharing the same configuration, so
+// machines with different hardware can be provisioned differently.
+type workerPool struct {
+	PoolName    string            `hcl:"name,label"`
+	Hosts       []host            `hcl:"host,block"`
+	Labels      map[string]string `hcl:"labels,optional"`
+	Taints      map[string]string `hcl:"taints,optional"`
+	CLCSnippets []string          `hcl:"clc_snippets,optional"`
+	OSVersion   string            `hcl:"os_version,optional"`
+	KernelArgs  []string          `hcl:"kernel_args,optional"`
+	InstallDisk string            `hcl:"install_disk,optional"`
+}
+
+// host represents a single machine in the worker pool.
+type host struct {
+	Name   string `hcl:"name,label"`
+	MAC    string `hcl:"mac"`
+	Domain string `hcl:"domain"`
+}
+
+// Name returns worker pool name.
+func (w *workerPool) Name() string {
+	return w.PoolName
 }
 
 const (
@@ -83,6 +116,9 @@ const (
 	Name = "bare-metal"
 )
 
+// Worker pool names are used in Terraform module names.
+var workerPoolNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
 func (c *config) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
 	if configBody == nil {
 		return hcl.Diagnostics{}
@@ -92,19 +128,45 @@ func (c *config) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext)
 		return diags
 	}
 
-	return c.checkValidConfig()
+	if c.InventoryFile != "" {
+		i, err := loadInventory(c.InventoryFile)
+		if err != nil {
+			return hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Failed to load inventory file",
+					Detail:   err.Error(),
+				},
+			}
+		}
+
+		if diags := i.validate(c.WorkerPools); diags.HasErrors() {
+			return diags
+		}
+
+		c.inventory = i
+	}
+
+	return c.withInventory().checkValidConfig()
 }
 
 // Meta is part of Platform interface and returns common information about the platform configuration.
 func (c *config) Meta() platform.Meta {
+	c = c.withInventory()
+
 	charts := platform.CommonControlPlaneCharts(platform.ControlPlanCharts{
 		Kubelet:      !c.DisableSelfHostedKubelet,
 		NodeLocalDNS: c.EnableNodeLocalDNS,
 	})
 
+	nodes := len(c.ControllerMacs) + len(c.WorkerMacs)
+	for _, wp := range c.WorkerPools {
+		nodes += len(wp.Hosts)
+	}
+
 	return platform.Meta{
 		AssetDir:             c.AssetDir,
-		ExpectedNodes:        len(c.ControllerMacs) + len(c.WorkerMacs),
+		ExpectedNodes:        nodes,
 		ControlplaneCharts:   charts,
 		Deployments:          platform.CommonDeployments(len(c.ControllerMacs)),
 		DaemonSets:           platform.CommonDaemonSets(len(c.ControllerMacs), c.DisableSelfHostedKubelet),
@@ -163,6 +225,8 @@ func (c *config) Initialize(ex *terraform.Executor) error {
 }
 
 func createTerraformConfigFile(cfg *config, terraformPath string) error {
+	cfg = cfg.withInventory()
+
 	tmplName := "cluster.tf"
 	t := template.New(tmplName)
 	t, err := t.Parse(terraformConfigTmpl)
@@ -257,6 +321,7 @@ func createTerraformConfigFile(cfg *config, terraformPath string) error {
 		KubeAPIServerExtraFlags      []string
 		Labels                       Labels
 		NodeSpecificLabels           map[string]Labels
+		NodeSpecificInstallDisks     map[string]string
 		EncryptPodTraffic            bool
 		IgnoreX509CNCheck            bool
 		CertsValidityPeriodHours     int
@@ -272,6 +337,7 @@ func createTerraformConfigFile(cfg *config, terraformPath string) error {
 		WipeAdditionalDisks          bool
 		EnableNodeLocalDNS           bool
 		NodeLocalDNSIP               string
+		WorkerPools                  []workerPool
 	}{
 		CachedInstall:                cfg.CachedInstall,
 		ClusterName:                  cfg.ClusterName,
@@ -298,6 +364,7 @@ func createTerraformConfigFile(cfg *config, terraformPath string) error {
 		KubeAPIServerExtraFlags:      cfg.KubeAPIServerExtraFlags,
 		Labels:                       cfg.Labels,
 		NodeSpecificLabels:           cfg.NodeSpecificLabels,
+		NodeSpecificInstallDisks:     cfg.NodeSpecificInstallDisks,
 		EncryptPodTraffic:            cfg.EncryptPodTraffic,
 		IgnoreX509CNCheck:            cfg.IgnoreX509CNCheck,
 		CertsValidityPeriodHours:     cfg.CertsValidityPeriodHours,
@@ -313,6 +380,7 @@ func createTerraformConfigFile(cfg *config, terraformPath string) error {
 		WipeAdditionalDisks:          cfg.WipeAdditionalDisks,
 		EnableNodeLocalDNS:           cfg.EnableNodeLocalDNS,
 		NodeLocalDNSIP:               cfg.NodeLocalDNSIP,
+		WorkerPools:                  cfg.workerPoolsWithDefaults(),
 	}
 
 	if err := t.Execute(f, terraformCfg); err != nil {
@@ -322,6 +390,42 @@ func createTerraformConfigFile(cfg *config, terraformPath string) error {
 	return nil
 }
 
+// workerPoolsWithDefaults returns worker pools with unset options inherited from
+// the cluster-wide configuration.
+func (c *config) workerPoolsWithDefaults() []workerPool {
+	pools := make([]workerPool, 0, len(c.WorkerPools))
+
+	for _, wp := range c.WorkerPools {
+		if wp.OSVersion == "" {
+			wp.OSVersion = c.OSVersion
+		}
+
+		if len(wp.KernelArgs) == 0 {
+			wp.KernelArgs = c.KernelArgs
+		}
+
+		if wp.InstallDisk == "" {
+			wp.InstallDisk = c.InstallDisk
+		}
+
+		labels := map[string]string{}
+
+		for k, v := range c.Labels {
+			labels[k] = v
+		}
+
+		for k, v := range wp.Labels {
+			labels[k] = v
+		}
+
+		wp.Labels = labels
+
+		pools = append(pools, wp)
+	}
+
+	return pools
+}
+
 // checkValidConfig validates cluster configuration.
 func (c *config) checkValidConfig() hcl.Diagnostics {
 	var diagnostics hcl.Diagnostics
@@ -355,6 +459,18 @@ func (c *config) checkValidConfig() hcl.Diagnostics {
 		diagnostics = append(diagnostics, diags...)
 	}
 
+	diagnostics = append(diagnostics, c.checkWorkerPools()...)
+	diagnostics = append(diagnostics, c.checkNamesUnique()...)
+	diagnostics = append(diagnostics, c.checkMACsUnique()...)
+
+	if c.InstallToSmallestDisk && len(c.NodeSpecificInstallDisks) > 0 {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "`install_disk` and `install_to_smallest_disk` are mutually exclusive",
+			Detail:   "Inventory sets `install_disk` for some hosts while `install_to_smallest_disk` is enabled",
+		})
+	}
+
 	for key, list := range c.CLCSnippets {
 		if key == "" || len(list) == 0 {
 			diagnostics = append(diagnostics, &hcl.Diagnostic{
@@ -377,3 +493,125 @@ func (c *config) checkValidConfig() hcl.Diagnostics {
 
 	return diagnostics
 }
+
+// checkWorkerPools validates worker pools.
+func (c *config) checkWorkerPools() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	pools := []platform.WorkerPool{}
+
+	for i, wp := range c.WorkerPools {
+		pools = append(pools, &c.WorkerPools[i])
+
+		if !workerPoolNameRegexp.MatchString(wp.PoolName) {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid worker pool name",
+				Detail: fmt.Sprintf("Worker pool name %q must consist of lower case alphanumeric characters or '-', "+
+					"and must start and end with an alphanumeric character", wp.PoolName),
+			})
+		}
+
+		if len(wp.Hosts) == 0 {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Worker pool must have at least one host",
+				Detail:   fmt.Sprintf("Worker pool %q has no host blocks defined", wp.PoolName),
+			})
+		}
+
+		if wp.InstallDisk != "" && c.InstallToSmallestDisk {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "`install_disk` and `install_to_smallest_disk` are mutually exclusive",
+				Detail:   fmt.Sprintf("Worker pool %q sets `install_disk` while `install_to_smallest_disk` is enabled", wp.PoolName),
+			})
+		}
+
+		for _, h := range wp.Hosts {
+			if h.Name == "" {
+				diagnostics = append(diagnostics, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Host name can't be empty",
+					Detail:   fmt.Sprintf("Worker pool %q has host with empty name", wp.PoolName),
+				})
+			}
+		}
+	}
+
+	return append(diagnostics, platform.WorkerPoolNamesUnique(pools)...)
+}
+
+// checkNamesUnique verifies that host names are unique across controllers, workers and
+// worker pools, as they are used as matchbox group names.
+func (c *config) checkNamesUnique() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	names := append(append([]string{}, c.ControllerNames...), c.WorkerNames...)
+
+	for _, wp := range c.WorkerPools {
+		for _, h := range wp.Hosts {
+			names = append(names, h.Name)
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, n := range names {
+		if n == "" {
+			continue
+		}
+
+		if seen[n] {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Host names should be unique",
+				Detail:   fmt.Sprintf("Host name %q is duplicated", n),
+			})
+		}
+
+		seen[n] = true
+	}
+
+	return diagnostics
+}
+
+// checkMACsUnique verifies that each MAC address is used by only one machine, as MAC
+// addresses are used by matchbox to select machine profile.
+func (c *config) checkMACsUnique() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	macs := append(append([]string{}, c.ControllerMacs...), c.WorkerMacs...)
+
+	for _, wp := range c.WorkerPools {
+		for _, h := range wp.Hosts {
+			macs = append(macs, h.MAC)
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, mac := range macs {
+		if mac == "" {
+			continue
+		}
+
+		// The same address can be written in different formats, so compare normalized values.
+		m := strings.ToLower(mac)
+		if hw, err := net.ParseMAC(mac); err == nil {
+			m = hw.String()
+		}
+
+		if seen[m] {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "MAC addresses should be unique",
+				Detail:   fmt.Sprintf("MAC address %q is duplicated", mac),
+			})
+		}
+
+		seen[m] = true
+	}
+
+	return diagnostics
+}
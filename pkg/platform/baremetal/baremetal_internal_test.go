@@ -17,6 +17,9 @@ package baremetal
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -71,6 +74,50 @@ func TestConfigurationIsInvalidWhen(t *testing.T) {
 				"node1": {"clc_snippet_1", "", "clc_snippet_3"},
 			}
 		},
+		"worker_pool_names_are_duplicated": func(c *config) {
+			c.WorkerPools = []workerPool{
+				{PoolName: "pool", Hosts: []host{{Name: "node2"}}},
+				{PoolName: "pool", Hosts: []host{{Name: "node3"}}},
+			}
+		},
+		"worker_pool_name_is_invalid": func(c *config) {
+			c.WorkerPools = []workerPool{
+				{PoolName: "Pool_1", Hosts: []host{{Name: "node2"}}},
+			}
+		},
+		"worker_pool_has_no_hosts": func(c *config) {
+			c.WorkerPools = []workerPool{
+				{PoolName: "pool"},
+			}
+		},
+		"host_name_is_duplicated_across_worker_pools": func(c *config) {
+			c.WorkerPools = []workerPool{
+				{PoolName: "foo", Hosts: []host{{Name: "node2"}}},
+				{PoolName: "bar", Hosts: []host{{Name: "node2"}}},
+			}
+		},
+		"host_name_is_duplicated_between_controllers_and_workers": func(c *config) {
+			c.ControllerNames = []string{"node1"}
+			c.WorkerNames = []string{"node1"}
+		},
+		"MAC_address_is_duplicated_in_different_format": func(c *config) {
+			c.ControllerMacs = []string{"52:54:00:a1:9c:ae"}
+			c.WorkerPools = []workerPool{
+				{PoolName: "pool", Hosts: []host{{Name: "node2", MAC: "52-54-00-A1-9C-AE"}}},
+			}
+		},
+		"host_name_is_duplicated_with_controller": func(c *config) {
+			c.ControllerNames = []string{"node1"}
+			c.WorkerPools = []workerPool{
+				{PoolName: "pool", Hosts: []host{{Name: "node1"}}},
+			}
+		},
+		"worker_pool_install_disk_is_set_with_install_to_smallest_disk": func(c *config) {
+			c.InstallToSmallestDisk = true
+			c.WorkerPools = []workerPool{
+				{PoolName: "pool", InstallDisk: "/dev/sdb", Hosts: []host{{Name: "node2"}}},
+			}
+		},
 	}
 
 	for n, c := range cases {
@@ -115,6 +162,13 @@ func TestConfigurationIsValidWhen(t *testing.T) {
 				"node1": {"clc_snippet_1", "clc_snippet_2"},
 			}
 		},
+		"worker_pools_have_unique_names_and_hosts": func(c *config) {
+			c.ControllerNames = []string{"node1"}
+			c.WorkerPools = []workerPool{
+				{PoolName: "foo", Hosts: []host{{Name: "node2"}}},
+				{PoolName: "bar", Hosts: []host{{Name: "node3"}, {Name: "node4"}}},
+			}
+		},
 	}
 
 	for n, c := range cases {
@@ -131,3 +185,87 @@ func TestConfigurationIsValidWhen(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerPoolsInheritClusterDefaults(t *testing.T) {
+	c := NewConfig()
+	c.OSVersion = "2765.2.0"
+	c.KernelArgs = []string{"foo=bar"}
+	c.InstallDisk = "/dev/sda"
+	c.Labels = map[string]string{"foo": "bar", "baz": "cluster"}
+	c.WorkerPools = []workerPool{
+		{
+			PoolName: "default",
+			Labels:   map[string]string{"baz": "pool"},
+		},
+		{
+			PoolName:    "custom",
+			OSVersion:   "2605.12.0",
+			KernelArgs:  []string{"console=ttyS1"},
+			InstallDisk: "/dev/nvme0n1",
+		},
+	}
+
+	pools := c.workerPoolsWithDefaults()
+
+	if pools[0].OSVersion != c.OSVersion || pools[0].InstallDisk != c.InstallDisk || !reflect.DeepEqual(pools[0].KernelArgs, c.KernelArgs) {
+		t.Errorf("Worker pool without settings should inherit cluster settings, got: %+v", pools[0])
+	}
+
+	expectedLabels := map[string]string{"foo": "bar", "baz": "pool"}
+	if !reflect.DeepEqual(pools[0].Labels, expectedLabels) {
+		t.Errorf("Expected labels %v, got %v", expectedLabels, pools[0].Labels)
+	}
+
+	if pools[1].OSVersion != "2605.12.0" || pools[1].InstallDisk != "/dev/nvme0n1" || pools[1].KernelArgs[0] != "console=ttyS1" {
+		t.Errorf("Worker pool settings should take precedence over cluster settings, got: %+v", pools[1])
+	}
+
+	if _, ok := c.WorkerPools[0].Labels["foo"]; ok {
+		t.Errorf("Applying defaults should not modify the configuration")
+	}
+}
+
+func TestCreateTerraformConfigFileWithWorkerPools(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "lokoctl-tests-")
+	if err != nil {
+		t.Fatalf("creating tmp dir should succeed, got: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			t.Logf("failed to remove temp dir %q: %v", tmpDir, err)
+		}
+	})
+
+	c := NewConfig()
+	c.ClusterName = "mercury"
+	c.WorkerPools = []workerPool{
+		{
+			PoolName: "storage",
+			Hosts: []host{
+				{Name: "node2", MAC: "52:54:00:b2:2f:86", Domain: "node2.example.com"},
+			},
+			Taints: map[string]string{"storage": "true:NoSchedule"},
+		},
+	}
+
+	if err := createTerraformConfigFile(c, tmpDir); err != nil {
+		t.Fatalf("creating Terraform config files should succeed, got: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "cluster.tf"))
+	if err != nil {
+		t.Fatalf("reading rendered Terraform config should succeed, got: %v", err)
+	}
+
+	for _, expected := range []string{
+		`module "worker-pool-storage" {`,
+		`module.worker-pool-storage.bootstrap_tokens,`,
+		`"52:54:00:b2:2f:86",`,
+		`"storage" = "true:NoSchedule",`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Rendered Terraform config should contain %q, got:\n%s", expected, b)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baremetal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/mitchellh/go-homedir"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RoleController is the inventory role of controller nodes.
+	RoleController = "controller"
+	// RoleWorker is the inventory role of worker nodes.
+	RoleWorker = "worker"
+)
+
+// inventory represents the list of machines, which can be maintained in the file
+// outside of the cluster configuration.
+type inventory struct {
+	Hosts []inventoryHost `json:"hosts"`
+}
+
+// inventoryHost represents a single machine in the inventory file.
+type inventoryHost struct {
+	Name        string            `json:"name"`
+	MAC         string            `json:"mac"`
+	Domain      string            `json:"domain"`
+	Role        string            `json:"role"`
+	Pool        string            `json:"pool,omitempty"`
+	InstallDisk string            `json:"install_disk,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// csvColumns are columns recognized in the CSV inventory file header.
+var csvColumns = map[string]bool{
+	"name":         true,
+	"mac":          true,
+	"domain":       true,
+	"role":         true,
+	"pool":         true,
+	"install_disk": true,
+	"labels":       true,
+}
+
+// loadInventory reads the inventory file from the given path. The format is
+// selected based on the file extension.
+func loadInventory(path string) (*inventory, error) {
+	p, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("expanding path %q: %w", path, err)
+	}
+
+	b, err := ioutil.ReadFile(p) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading file %q: %w", p, err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(p)); ext {
+	case ".yaml", ".yml":
+		return parseYAMLInventory(b)
+	case ".csv":
+		return parseCSVInventory(b)
+	default:
+		return nil, fmt.Errorf("unsupported inventory file extension %q, expected .yaml, .yml or .csv", ext)
+	}
+}
+
+func parseYAMLInventory(b []byte) (*inventory, error) {
+	i := &inventory{}
+
+	if err := yaml.UnmarshalStrict(b, i); err != nil {
+		return nil, fmt.Errorf("parsing YAML: %w", err)
+	}
+
+	return i, nil
+}
+
+// parseCSVInventory parses inventory in CSV format. First line must be a header with
+// column names. Labels are specified as a semicolon separated list of key=value pairs.
+func parseCSVInventory(b []byte) (*inventory, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	for i, c := range header {
+		header[i] = strings.TrimSpace(c)
+
+		if !csvColumns[header[i]] {
+			return nil, fmt.Errorf("unknown CSV column %q", header[i])
+		}
+	}
+
+	i := &inventory{}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		h := inventoryHost{}
+
+		for n, v := range record {
+			v = strings.TrimSpace(v)
+
+			switch header[n] {
+			case "name":
+				h.Name = v
+			case "mac":
+				h.MAC = v
+			case "domain":
+				h.Domain = v
+			case "role":
+				h.Role = v
+			case "pool":
+				h.Pool = v
+			case "install_disk":
+				h.InstallDisk = v
+			case "labels":
+				labels, err := parseCSVLabels(v)
+				if err != nil {
+					return nil, fmt.Errorf("parsing labels of host %d: %w", len(i.Hosts), err)
+				}
+
+				h.Labels = labels
+			}
+		}
+
+		i.Hosts = append(i.Hosts, h)
+	}
+
+	return i, nil
+}
+
+func parseCSVLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	labels := map[string]string{}
+
+	for _, kv := range strings.Split(s, ";") {
+		p := strings.SplitN(kv, "=", 2)
+		if len(p) != 2 || strings.TrimSpace(p[0]) == "" {
+			return nil, fmt.Errorf("label %q is not in key=value format", kv)
+		}
+
+		labels[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
+	}
+
+	return labels, nil
+}
+
+// validate checks inventory hosts for required fields, MAC address format, roles and
+// references to worker pools defined in the cluster configuration.
+func (i *inventory) validate(pools []workerPool) hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	knownPools := map[string]bool{}
+	for _, wp := range pools {
+		knownPools[wp.PoolName] = true
+	}
+
+	for n, h := range i.Hosts {
+		if h.Name == "" || h.Domain == "" {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Inventory host must have name and domain",
+				Detail:   fmt.Sprintf("Host %d in inventory has name %q and domain %q", n, h.Name, h.Domain),
+			})
+		}
+
+		if hw, err := net.ParseMAC(h.MAC); err != nil || len(hw) != 6 {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid MAC address in inventory",
+				Detail:   fmt.Sprintf("Host %q has invalid MAC address %q", h.Name, h.MAC),
+			})
+		}
+
+		switch h.Role {
+		case RoleController:
+			if h.Pool != "" {
+				diagnostics = append(diagnostics, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Controller can't be a member of worker pool",
+					Detail:   fmt.Sprintf("Host %q has role %q and pool %q", h.Name, h.Role, h.Pool),
+				})
+			}
+		case RoleWorker:
+			if h.Pool != "" && !knownPools[h.Pool] {
+				diagnostics = append(diagnostics, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unknown worker pool in inventory",
+					Detail:   fmt.Sprintf("Host %q refers to worker pool %q, which is not defined", h.Name, h.Pool),
+				})
+			}
+		default:
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid role in inventory",
+				Detail: fmt.Sprintf("Host %q has role %q, expected %q or %q",
+					h.Name, h.Role, RoleController, RoleWorker),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// withInventory returns a copy of the configuration with hosts from the inventory
+// merged into controller lists, worker lists and worker pools.
+func (c *config) withInventory() *config {
+	if c.inventory == nil {
+		return c
+	}
+
+	cc := *c
+
+	cc.ControllerNames = append([]string{}, c.ControllerNames...)
+	cc.ControllerMacs = append([]string{}, c.ControllerMacs...)
+	cc.ControllerDomains = append([]string{}, c.ControllerDomains...)
+	cc.WorkerNames = append([]string{}, c.WorkerNames...)
+	cc.WorkerMacs = append([]string{}, c.WorkerMacs...)
+	cc.WorkerDomains = append([]string{}, c.WorkerDomains...)
+	cc.NodeSpecificLabels = map[string]Labels{}
+	cc.NodeSpecificInstallDisks = map[string]string{}
+	cc.WorkerPools = make([]workerPool, len(c.WorkerPools))
+
+	for k, v := range c.NodeSpecificLabels {
+		cc.NodeSpecificLabels[k] = v
+	}
+
+	for i, wp := range c.WorkerPools {
+		wp.Hosts = append([]host{}, wp.Hosts...)
+		cc.WorkerPools[i] = wp
+	}
+
+	for _, h := range c.inventory.Hosts {
+		mac := h.MAC
+		if hw, err := net.ParseMAC(h.MAC); err == nil {
+			mac = hw.String()
+		}
+
+		if len(h.Labels) > 0 {
+			labels := Labels{}
+
+			for k, v := range cc.NodeSpecificLabels[h.Name] {
+				labels[k] = v
+			}
+
+			for k, v := range h.Labels {
+				labels[k] = v
+			}
+
+			cc.NodeSpecificLabels[h.Name] = labels
+		}
+
+		if h.InstallDisk != "" {
+			cc.NodeSpecificInstallDisks[h.Name] = h.InstallDisk
+		}
+
+		switch {
+		case h.Role == RoleController:
+			cc.ControllerNames = append(cc.ControllerNames, h.Name)
+			cc.ControllerMacs = append(cc.ControllerMacs, mac)
+			cc.ControllerDomains = append(cc.ControllerDomains, h.Domain)
+		case h.Pool == "":
+			cc.WorkerNames = append(cc.WorkerNames, h.Name)
+			cc.WorkerMacs = append(cc.WorkerMacs, mac)
+			cc.WorkerDomains = append(cc.WorkerDomains, h.Domain)
+		default:
+			for i := range cc.WorkerPools {
+				if cc.WorkerPools[i].PoolName == h.Pool {
+					cc.WorkerPools[i].Hosts = append(cc.WorkerPools[i].Hosts, host{
+						Name:   h.Name,
+						MAC:    mac,
+						Domain: h.Domain,
+					})
+				}
+			}
+		}
+	}
+
+	return &cc
+}
@@ -0,0 +1,275 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baremetal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	lokoconfig "github.com/kinvolk/lokomotive/pkg/config"
+)
+
+const yamlInventory = `
+hosts:
+- name: node1
+  mac: 52:54:00:A1:9C:AE
+  domain: node1.example.com
+  role: controller
+- name: node2
+  mac: 52:54:00:b2:2f:86
+  domain: node2.example.com
+  role: worker
+  labels:
+    foo: bar
+- name: node3
+  mac: 52:54:00:c3:61:77
+  domain: node3.example.com
+  role: worker
+  pool: storage
+  install_disk: /dev/nvme0n1
+`
+
+const csvInventory = `name,mac,domain,role,pool,install_disk,labels
+# Controllers.
+node1,52:54:00:A1:9C:AE,node1.example.com,controller,,,
+node2,52:54:00:b2:2f:86,node2.example.com,worker,,,foo=bar
+node3,52:54:00:c3:61:77,node3.example.com,worker,storage,/dev/nvme0n1,
+`
+
+func writeInventory(t *testing.T, name, content string) string {
+	tmpDir, err := ioutil.TempDir("", "lokoctl-tests-")
+	if err != nil {
+		t.Fatalf("creating tmp dir should succeed, got: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			t.Logf("failed to remove temp dir %q: %v", tmpDir, err)
+		}
+	})
+
+	path := filepath.Join(tmpDir, name)
+
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing inventory file should succeed, got: %v", err)
+	}
+
+	return path
+}
+
+func loadConfigWithInventory(t *testing.T, inventoryPath string) (*config, hcl.Diagnostics) {
+	c := fmt.Sprintf(`
+cluster "bare-metal" {
+  asset_dir                 = "/fooo"
+  cluster_name              = "mercury"
+  k8s_domain_name           = "example.com"
+  matchbox_ca_path          = "/foo/ca.crt"
+  matchbox_client_cert_path = "/foo/client.crt"
+  matchbox_client_key_path  = "/foo/client.key"
+  matchbox_endpoint         = "matchbox.example.com:8081"
+  matchbox_http_endpoint    = "http://matchbox.example.com:8080"
+  ssh_pubkeys               = ["ssh-rsa AAAA"]
+  controller_names          = []
+  controller_macs           = []
+  controller_domains        = []
+  inventory_file            = %q
+
+  worker_pool "storage" {}
+}
+`, inventoryPath)
+
+	p := hclparse.NewParser()
+
+	f, d := p.ParseHCL([]byte(c), "x.lokocfg")
+	if d.HasErrors() {
+		t.Fatalf("parsing HCL should succeed, got: %v", d)
+	}
+
+	configBody := hcl.MergeFiles([]*hcl.File{f})
+
+	var rootConfig lokoconfig.RootConfig
+
+	if d := gohcl.DecodeBody(configBody, nil, &rootConfig); d.HasErrors() {
+		t.Fatalf("decoding root config should succeed, got: %v", d)
+	}
+
+	cc := NewConfig()
+
+	return cc, cc.LoadConfig(&rootConfig.Cluster.Config, &hcl.EvalContext{})
+}
+
+func TestLoadInventory(t *testing.T) {
+	for name, content := range map[string]string{
+		"inventory.yaml": yamlInventory,
+		"inventory.csv":  csvInventory,
+	} {
+		name, content := name, content
+
+		t.Run(name, func(t *testing.T) {
+			c, d := loadConfigWithInventory(t, writeInventory(t, name, content))
+			if d.HasErrors() {
+				t.Fatalf("loading valid configuration should succeed, got: %v", d)
+			}
+
+			cc := c.withInventory()
+
+			if !reflect.DeepEqual(cc.ControllerMacs, []string{"52:54:00:a1:9c:ae"}) {
+				t.Errorf("expected controller MAC address to be normalized, got: %v", cc.ControllerMacs)
+			}
+
+			if !reflect.DeepEqual(cc.WorkerNames, []string{"node2"}) {
+				t.Errorf("expected worker without pool to be added to worker names, got: %v", cc.WorkerNames)
+			}
+
+			if cc.NodeSpecificLabels["node2"]["foo"] != "bar" {
+				t.Errorf("expected host labels to be added to node specific labels, got: %v", cc.NodeSpecificLabels)
+			}
+
+			if len(cc.WorkerPools[0].Hosts) != 1 || cc.WorkerPools[0].Hosts[0].Name != "node3" {
+				t.Errorf("expected host to be added to worker pool, got: %v", cc.WorkerPools[0].Hosts)
+			}
+
+			if cc.NodeSpecificInstallDisks["node3"] != "/dev/nvme0n1" {
+				t.Errorf("expected host install disk to be set, got: %v", cc.NodeSpecificInstallDisks)
+			}
+
+			if len(c.WorkerNames) != 0 || len(c.WorkerPools[0].Hosts) != 0 {
+				t.Errorf("merging inventory should not modify the configuration")
+			}
+
+			if n := c.Meta().ExpectedNodes; n != 3 {
+				t.Errorf("expected 3 nodes, got %d", n)
+			}
+		})
+	}
+}
+
+func TestLoadInventoryIsInvalidWhen(t *testing.T) {
+	cases := map[string]string{
+		"MAC_address_is_duplicated": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: controller}
+- {name: node2, mac: "52:54:00:A1:9C:AE", domain: node2.example.com, role: worker}
+`,
+		"MAC_address_is_invalid": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c", domain: node1.example.com, role: controller}
+`,
+		"pool_is_unknown": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: worker, pool: foo}
+`,
+		"role_is_invalid": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: master}
+`,
+		"controller_has_pool": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: controller, pool: storage}
+`,
+		"name_is_missing": `
+hosts:
+- {mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: controller}
+`,
+		"host_name_is_duplicated_across_roles": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: controller}
+- {name: node1, mac: "52:54:00:a1:9c:af", domain: node1.example.com, role: worker}
+`,
+		"host_name_is_duplicated": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: worker, pool: storage}
+- {name: node1, mac: "52:54:00:a1:9c:af", domain: node1.example.com, role: worker, pool: storage}
+`,
+		"field_is_unknown": `
+hosts:
+- {name: node1, mac: "52:54:00:a1:9c:ae", domain: node1.example.com, role: controller, foo: bar}
+`,
+	}
+
+	for n, content := range cases {
+		content := content
+
+		t.Run(n, func(t *testing.T) {
+			if _, d := loadConfigWithInventory(t, writeInventory(t, "inventory.yaml", content)); !d.HasErrors() {
+				t.Fatalf("loading configuration should fail")
+			}
+		})
+	}
+}
+
+func TestParseCSVInventoryIsInvalidWhen(t *testing.T) {
+	cases := map[string]string{
+		"column_is_unknown": "name,mac,domain,role,foo\n",
+		"labels_are_malformed": "name,mac,domain,role,labels\n" +
+			"node1,52:54:00:a1:9c:ae,node1.example.com,controller,foo\n",
+		"record_has_too_many_fields": "name,mac,domain,role\n" +
+			"node1,52:54:00:a1:9c:ae,node1.example.com,controller,foo\n",
+	}
+
+	for n, content := range cases {
+		content := content
+
+		t.Run(n, func(t *testing.T) {
+			if _, err := parseCSVInventory([]byte(content)); err == nil {
+				t.Fatalf("parsing CSV inventory should fail")
+			}
+		})
+	}
+}
+
+func TestLoadInventoryRejectsUnknownExtension(t *testing.T) {
+	if _, err := loadInventory(writeInventory(t, "inventory.json", "{}")); err == nil {
+		t.Fatalf("loading inventory with unknown extension should fail")
+	}
+}
+
+func TestCreateTerraformConfigFileWithInventory(t *testing.T) {
+	c, d := loadConfigWithInventory(t, writeInventory(t, "inventory.yaml", yamlInventory))
+	if d.HasErrors() {
+		t.Fatalf("loading valid configuration should succeed, got: %v", d)
+	}
+
+	tmpDir := filepath.Dir(c.InventoryFile)
+
+	if err := createTerraformConfigFile(c, tmpDir); err != nil {
+		t.Fatalf("creating Terraform config files should succeed, got: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "cluster.tf"))
+	if err != nil {
+		t.Fatalf("reading rendered Terraform config should succeed, got: %v", err)
+	}
+
+	for _, expected := range []string{
+		`controller_names   = ["node1"]`,
+		`worker_macs        = ["52:54:00:b2:2f:86"]`,
+		`"node3" = "/dev/nvme0n1",`,
+		`"node3.example.com",`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("rendered Terraform config should contain %q, got:\n%s", expected, b)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baremetal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// reprovisionResources are resources of the matchbox-flatcar module, which must be
+// recreated to reprovision the machine.
+var reprovisionResources = []string{
+	"matchbox_profile.node",
+	"null_resource.reprovision-node-when-ignition-changes",
+}
+
+// NodeReplacement implements platform.PlatformWithNodeReplace interface. Worker machines are
+// matched by name or by domain. Only worker nodes can be replaced.
+//
+// The machine is reinstalled using PXE boot, so pxe_commands must be configured.
+func (c *config) NodeReplacement(name string, addresses []string) (*platform.NodeReplacement, error) {
+	cfg := c.withInventory()
+
+	if cfg.PXECommands == "" {
+		return nil, fmt.Errorf("replacing nodes requires pxe_commands to PXE boot the machine")
+	}
+
+	matches := func(n, domain string) bool {
+		if n == name {
+			return true
+		}
+
+		for _, a := range addresses {
+			if a == domain {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for i, n := range cfg.ControllerNames {
+		if matches(n, cfg.ControllerDomains[i]) {
+			return nil, fmt.Errorf("node %q is a controller node, replacing controller nodes is not supported", name)
+		}
+	}
+
+	for i, n := range cfg.WorkerNames {
+		if matches(n, cfg.WorkerDomains[i]) {
+			module := fmt.Sprintf("module.%s-%s.module.worker_profile[%d]", Name, cfg.ClusterName, i)
+
+			return &platform.NodeReplacement{Steps: cfg.nodeReplaceSteps(module, cfg.WorkerMacs[i])}, nil
+		}
+	}
+
+	for _, wp := range cfg.WorkerPools {
+		for i, h := range wp.Hosts {
+			if matches(h.Name, h.Domain) {
+				module := fmt.Sprintf("module.worker-pool-%s.module.worker_profile[%d]", wp.PoolName, i)
+
+				return &platform.NodeReplacement{Steps: cfg.nodeReplaceSteps(module, h.MAC)}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("looking up node %q: %w", name, platform.ErrNodeNotFound)
+}
+
+func (c *config) nodeReplaceSteps(module, mac string) []terraform.ExecutionStep {
+	steps := []terraform.ExecutionStep{}
+
+	for i, r := range reprovisionResources {
+		step := terraform.ExecutionStep{
+			Description: "taint machine resources",
+			Args:        []string{"taint", fmt.Sprintf("%s.%s", module, r)},
+		}
+
+		if i == 0 {
+			step.PreExecutionHook = c.removeProvisionedFlag(mac)
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+// removeProvisionedFlag removes the file, which PXE helper script creates in assets
+// directory once the machine is provisioned. Without removing it, the machine would
+// only be rebooted instead of being PXE booted and reinstalled.
+func (c *config) removeProvisionedFlag(mac string) terraform.ExecutionHook {
+	return func(*terraform.Executor) error {
+		assetDir, err := homedir.Expand(c.AssetDir)
+		if err != nil {
+			return fmt.Errorf("expanding path %q: %w", c.AssetDir, err)
+		}
+
+		path := filepath.Join(assetDir, "cluster-assets", mac)
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing file %q: %w", path, err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baremetal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+)
+
+func replaceConfig() *config {
+	c := NewConfig()
+	c.ClusterName = "mercury"
+	c.PXECommands = "ipmitool -H bmc-$domain chassis bootdev pxe"
+	c.ControllerNames = []string{"node1"}
+	c.ControllerMacs = []string{"52:54:00:a1:9c:ae"}
+	c.ControllerDomains = []string{"node1.example.com"}
+	c.WorkerNames = []string{"node2"}
+	c.WorkerMacs = []string{"52:54:00:b2:2f:86"}
+	c.WorkerDomains = []string{"node2.example.com"}
+	c.WorkerPools = []workerPool{
+		{
+			PoolName: "storage",
+			Hosts: []host{
+				{Name: "node3", MAC: "52:54:00:c3:61:77", Domain: "node3.example.com"},
+				{Name: "node4", MAC: "52:54:00:d4:61:77", Domain: "node4.example.com"},
+			},
+		},
+	}
+
+	return c
+}
+
+func stepsArgs(t *testing.T, c *config, name string, addresses []string) [][]string {
+	r, err := c.NodeReplacement(name, addresses)
+	if err != nil {
+		t.Fatalf("Getting node replacement should succeed, got: %v", err)
+	}
+
+	if r.Reboot {
+		t.Fatalf("Machine should be reinstalled using PXE boot instead of reboot")
+	}
+
+	args := [][]string{}
+	for _, s := range r.Steps {
+		args = append(args, s.Args)
+	}
+
+	return args
+}
+
+func TestNodeReplacementTaintWorkerResources(t *testing.T) {
+	expected := [][]string{
+		{"taint", "module.bare-metal-mercury.module.worker_profile[0].matchbox_profile.node"},
+		{"taint", "module.bare-metal-mercury.module.worker_profile[0].null_resource.reprovision-node-when-ignition-changes"},
+	}
+
+	if got := stepsArgs(t, replaceConfig(), "node2", nil); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Expected steps %v, got %v", expected, got)
+	}
+}
+
+func TestNodeReplacementTaintWorkerPoolResourcesMatchedByDomain(t *testing.T) {
+	expected := [][]string{
+		{"taint", "module.worker-pool-storage.module.worker_profile[1].matchbox_profile.node"},
+		{"taint", "module.worker-pool-storage.module.worker_profile[1].null_resource.reprovision-node-when-ignition-changes"},
+	}
+
+	got := stepsArgs(t, replaceConfig(), "node4.example.com", []string{"10.0.0.4", "node4.example.com"})
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("Expected steps %v, got %v", expected, got)
+	}
+}
+
+func TestNodeReplacementRejectControllers(t *testing.T) {
+	if _, err := replaceConfig().NodeReplacement("node1", nil); err == nil {
+		t.Fatalf("Replacing controller node should fail")
+	}
+}
+
+func TestNodeReplacementRejectUnknownNodes(t *testing.T) {
+	_, err := replaceConfig().NodeReplacement("node5", nil)
+	if !errors.Is(err, platform.ErrNodeNotFound) {
+		t.Fatalf("Expected error %q, got: %v", platform.ErrNodeNotFound, err)
+	}
+}
+
+func TestNodeReplacementRemoveProvisionedFlag(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "lokoctl-tests-")
+	if err != nil {
+		t.Fatalf("creating tmp dir should succeed, got: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			t.Logf("failed to remove temp dir %q: %v", tmpDir, err)
+		}
+	})
+
+	c := replaceConfig()
+	c.AssetDir = tmpDir
+
+	flag := filepath.Join(tmpDir, "cluster-assets", c.WorkerMacs[0])
+
+	if err := os.MkdirAll(filepath.Dir(flag), 0o700); err != nil {
+		t.Fatalf("creating directory should succeed, got: %v", err)
+	}
+
+	if err := ioutil.WriteFile(flag, []byte(c.WorkerDomains[0]), 0o600); err != nil {
+		t.Fatalf("writing flag file should succeed, got: %v", err)
+	}
+
+	r, err := c.NodeReplacement("node2", nil)
+	if err != nil {
+		t.Fatalf("Getting node replacement should succeed, got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := r.Steps[0].PreExecutionHook(nil); err != nil {
+			t.Fatalf("Running hook should succeed, also when flag file does not exist, got: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(flag); !os.IsNotExist(err) {
+		t.Fatalf("Flag file should be removed, got: %v", err)
+	}
+}
+
+func TestNodeReplacementRequiresPXECommands(t *testing.T) {
+	c := replaceConfig()
+	c.PXECommands = ""
+
+	if _, err := c.NodeReplacement("node2", nil); err == nil {
+		t.Fatalf("Replacing node without pxe_commands should fail")
+	}
+}
@@ -79,6 +79,14 @@ module "bare-metal-{{.ClusterName}}" {
   }
   {{- end }}
 
+  {{- if .NodeSpecificInstallDisks }}
+  node_specific_install_disks = {
+  {{- range $nodeName, $disk := .NodeSpecificInstallDisks }}
+    "{{ $nodeName }}" = "{{ $disk }}",
+  {{- end }}
+  }
+  {{- end }}
+
   ignore_x509_cn_check   = {{.IgnoreX509CNCheck}}
   conntrack_max_per_core = {{.ConntrackMaxPerCore}}
 
@@ -160,7 +168,140 @@ EOF
     {{- end }}
   }
   {{- end }}
+
+  worker_bootstrap_tokens = concat(
+    {{- range $pool := .WorkerPools }}
+    module.worker-pool-{{ $pool.PoolName }}.bootstrap_tokens,
+    {{- end }}
+  )
+}
+
+{{- range $pool := .WorkerPools }}
+
+module "worker-pool-{{ $pool.PoolName }}" {
+  source = "../terraform-modules/bare-metal/flatcar-linux/kubernetes/workers"
+
+  cluster_name           = "{{ $.ClusterName }}"
+  pool_name              = "{{ $pool.PoolName }}"
+  matchbox_http_endpoint = "{{ $.MatchboxHTTPEndpoint }}"
+  os_channel             = "{{ $.OSChannel }}"
+  os_version             = "{{ $pool.OSVersion }}"
+
+  ca_cert                = module.bare-metal-{{ $.ClusterName }}.ca_cert
+  apiserver              = module.bare-metal-{{ $.ClusterName }}.apiserver
+  cluster_dns_service_ip = module.bare-metal-{{ $.ClusterName }}.cluster_dns_service_ip
+
+  cached_install = "{{ $.CachedInstall }}"
+  ssh_keys       = {{ $.SSHPublicKeys }}
+  asset_dir      = "../cluster-assets"
+
+  # machines
+  names = [
+  {{- range $pool.Hosts }}
+    "{{ .Name }}",
+  {{- end }}
+  ]
+  macs = [
+  {{- range $pool.Hosts }}
+    "{{ .MAC }}",
+  {{- end }}
+  ]
+  domains = [
+  {{- range $pool.Hosts }}
+    "{{ .Domain }}",
+  {{- end }}
+  ]
+
+  {{- if $pool.Labels }}
+  labels = {
+  {{- range $key, $value := $pool.Labels }}
+    "{{ $key }}" = "{{ $value }}",
+  {{- end }}
+  }
+  {{- end }}
+
+  {{- if $pool.Taints }}
+  taints = {
+  {{- range $key, $value := $pool.Taints }}
+    "{{ $key }}" = "{{ $value }}",
+  {{- end }}
+  }
+  {{- end }}
+
+  {{- if $.NodeSpecificLabels }}
+  node_specific_labels = {
+    {{- range $nodeName, $mapOfLabels := $.NodeSpecificLabels }}
+      {{- if $mapOfLabels }}
+        "{{ $nodeName }}" = {
+          {{- range $key, $value := $mapOfLabels }}
+            "{{ $key }}" = "{{ $value }}",
+          {{- end }}
+        }
+      {{- end }}
+    {{- end }}
+  }
+  {{- end }}
+
+  {{- if $pool.CLCSnippets }}
+  clc_snippets = [
+  {{- range $clcSnippet := $pool.CLCSnippets }}
+    <<EOF
+{{ $clcSnippet }}
+EOF
+    ,
+  {{- end }}
+  ]
+  {{- end }}
+
+  {{- if $pool.InstallDisk }}
+  install_disk = "{{ $pool.InstallDisk }}"
+  {{- end }}
+
+  {{- if $.NodeSpecificInstallDisks }}
+  node_specific_install_disks = {
+  {{- range $nodeName, $disk := $.NodeSpecificInstallDisks }}
+    "{{ $nodeName }}" = "{{ $disk }}",
+  {{- end }}
+  }
+  {{- end }}
+
+  install_to_smallest_disk = {{ $.InstallToSmallestDisk }}
+
+  {{- if $pool.KernelArgs }}
+  kernel_args = [
+  {{- range $arg := $pool.KernelArgs }}
+    "{{ $arg }}",
+  {{- end }}
+  ]
+  {{- end }}
+
+  {{- if $.KernelConsole }}
+  kernel_console = [
+  {{- range $arg := $.KernelConsole }}
+    "{{ $arg }}",
+  {{- end }}
+  ]
+  {{- end }}
+
+  {{- if $.PXECommands }}
+  pxe_commands = <<EOT
+{{ $.PXECommands }}
+EOT
+  {{- end }}
+
+  {{- if $.InstallPreBootCmds }}
+  install_pre_reboot_cmds = <<EOT
+{{ $.InstallPreBootCmds }}
+EOT
+  {{- end }}
+
+  ignore_changes = {{ $.IgnoreWorkerChanges }}
+
+  download_protocol = "{{ $.DownloadProtocol }}"
+
+  wipe_additional_disks = "{{ $.WipeAdditionalDisks }}"
 }
+{{- end }}
 
 terraform {
   required_providers {
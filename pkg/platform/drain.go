@@ -0,0 +1,98 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kinvolk/lokomotive/pkg/k8sutil"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// ScaleDownFunc is called with the planned Terraform changes before they are applied. It should
+// drain the worker nodes, which are about to be removed.
+type ScaleDownFunc func(ctx context.Context, cs kubernetes.Interface, changes []terraform.ResourceChange) error
+
+// PrepareScaleDown plans Terraform changes and passes them to a given function together with
+// a clientset for the cluster, so workers which are going to be removed can be drained before
+// Terraform destroys them. If the cluster has not been created yet, it does nothing.
+func PrepareScaleDown(ex *terraform.Executor, f ScaleDownFunc) error {
+	o := map[string]interface{}{}
+
+	if err := ex.Output("", &o); err != nil {
+		return fmt.Errorf("reading Terraform outputs: %w", err)
+	}
+
+	if _, ok := o["kubeconfig"]; !ok {
+		return nil
+	}
+
+	kubeconfig := ""
+
+	if err := ex.Output("kubeconfig", &kubeconfig); err != nil {
+		return fmt.Errorf("reading kubeconfig from Terraform outputs: %w", err)
+	}
+
+	cs, err := k8sutil.NewClientset([]byte(kubeconfig))
+	if err != nil {
+		return fmt.Errorf("creating Kubernetes clientset: %w", err)
+	}
+
+	changes, err := ex.PlanChanges()
+	if err != nil {
+		return fmt.Errorf("planning infrastructure changes: %w", err)
+	}
+
+	return f(context.TODO(), cs, changes)
+}
+
+// DrainNodes cordons given nodes and evicts pods from them, respecting PodDisruptionBudgets.
+// Nodes, which are not registered in the cluster are skipped.
+func DrainNodes(ctx context.Context, cs kubernetes.Interface, nodes []string) error {
+	logger := log.WithFields(log.Fields{
+		"phase": "infrastructure",
+	})
+
+	for _, n := range nodes {
+		_, err := cs.CoreV1().Nodes().Get(ctx, n, metav1.GetOptions{})
+
+		switch {
+		case k8serrors.IsNotFound(err):
+			logger.Warnf("Node %q to be removed is not registered in the cluster, skipping draining", n)
+
+			continue
+		case err != nil:
+			return fmt.Errorf("getting node %q: %w", n, err)
+		}
+
+		logger.Infof("Draining node %q before removing it", n)
+
+		options := k8sutil.DrainOptions{
+			Out: logger.WriterLevel(log.DebugLevel),
+		}
+
+		if err := k8sutil.DrainNode(ctx, cs, n, options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
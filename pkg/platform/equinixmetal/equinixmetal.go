@@ -224,6 +224,10 @@ func (c *config) Apply(ex *terraform.Executor) error {
 		return err
 	}
 
+	if err := platform.PrepareScaleDown(ex, drainRemovedWorkers); err != nil {
+		return fmt.Errorf("draining removed workers: %w", err)
+	}
+
 	return c.terraformSmartApply(ex, c.DNS, []string{terraform.WithParallelism})
 }
 
@@ -233,6 +237,10 @@ func (c *config) ApplyWithoutParallel(ex *terraform.Executor) error {
 		return fmt.Errorf("initializing Terraform configuration: %w", err)
 	}
 
+	if err := platform.PrepareScaleDown(ex, drainRemovedWorkers); err != nil {
+		return fmt.Errorf("draining removed workers: %w", err)
+	}
+
 	return c.terraformSmartApply(ex, c.DNS, []string{terraform.WithoutParallelism})
 }
 
@@ -19,6 +19,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/kinvolk/lokomotive/pkg/terraform"
 )
 
 func TestCheckNotEmptyWorkersEmpty(t *testing.T) {
@@ -479,3 +481,34 @@ func Test_resolveNodePrivateCIDRs(t *testing.T) { //nolint:funlen
 		})
 	}
 }
+
+func TestRemovedWorkers(t *testing.T) {
+	changes := []terraform.ResourceChange{
+		{
+			Address: "module.worker-pool1.metal_device.nodes[2]",
+			Actions: []string{"delete"},
+			Before:  map[string]interface{}{"hostname": "foo-pool1-worker-2"},
+		},
+		{
+			Address: "module.worker-pool1.metal_device.nodes[1]",
+			Actions: []string{"update"},
+			Before:  map[string]interface{}{"hostname": "foo-pool1-worker-1"},
+		},
+		{
+			Address: "module.equinixmetal-foo.metal_device.controllers[0]",
+			Actions: []string{"delete"},
+			Before:  map[string]interface{}{"hostname": "foo-controller-0"},
+		},
+		{
+			Address: "module.worker-pool2.metal_device.nodes[0]",
+			Actions: []string{"delete", "create"},
+			Before:  map[string]interface{}{"hostname": "foo-pool2-worker-0"},
+		},
+	}
+
+	expected := []string{"foo-pool1-worker-2", "foo-pool2-worker-0"}
+
+	if diff := cmp.Diff(expected, removedWorkers(changes)); diff != "" {
+		t.Fatalf("Unexpected removed workers (-want +got)\n%s", diff)
+	}
+}
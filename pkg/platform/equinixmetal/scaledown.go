@@ -0,0 +1,51 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package equinixmetal
+
+import (
+	"context"
+	"regexp"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// workerDeviceRegexp matches Terraform addresses of worker pool devices.
+var workerDeviceRegexp = regexp.MustCompile(`^module\.worker-[^.]+\.metal_device\.nodes\[\d+\]$`)
+
+// removedWorkers returns names of worker nodes, which devices are going to be destroyed,
+// either because the worker pool count was lowered or because the pool was removed.
+func removedWorkers(changes []terraform.ResourceChange) []string {
+	nodes := []string{}
+
+	for _, rc := range changes {
+		if !rc.Deleted() || !workerDeviceRegexp.MatchString(rc.Address) {
+			continue
+		}
+
+		// Device hostname is used as a node name.
+		if hostname, ok := rc.Before["hostname"].(string); ok && hostname != "" {
+			nodes = append(nodes, hostname)
+		}
+	}
+
+	return nodes
+}
+
+func drainRemovedWorkers(ctx context.Context, cs kubernetes.Interface, changes []terraform.ResourceChange) error {
+	return platform.DrainNodes(ctx, cs, removedWorkers(changes))
+}
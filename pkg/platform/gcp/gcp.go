@@ -0,0 +1,401 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gcp provides the implementation of the Platform interface for Google Cloud.
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/kinvolk/lokomotive/pkg/oidc"
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+type workerPool struct {
+	Name             string            `hcl:"pool_name,label"`
+	Count            int               `hcl:"count"`
+	CPUManagerPolicy string            `hcl:"cpu_manager_policy,optional"`
+	SSHPubKeys       []string          `hcl:"ssh_pubkeys"`
+	MachineType      string            `hcl:"machine_type,optional"`
+	Preemptible      bool              `hcl:"preemptible,optional"`
+	OSImage          string            `hcl:"os_image,optional"`
+	DiskSize         int               `hcl:"disk_size,optional"`
+	Labels           map[string]string `hcl:"labels,optional"`
+	Taints           map[string]string `hcl:"taints,optional"`
+	CLCSnippets      []string          `hcl:"clc_snippets,optional"`
+}
+
+type config struct {
+	AssetDir                 string       `hcl:"asset_dir"`
+	ClusterName              string       `hcl:"cluster_name"`
+	ProjectID                string       `hcl:"project_id"`
+	Region                   string       `hcl:"region,optional"`
+	CredsPath                string       `hcl:"creds_path,optional"`
+	DNSZone                  string       `hcl:"dns_zone"`
+	DNSZoneName              string       `hcl:"dns_zone_name"`
+	SSHPubKeys               []string     `hcl:"ssh_pubkeys"`
+	ControllerCount          int          `hcl:"controller_count,optional"`
+	ControllerType           string       `hcl:"controller_type,optional"`
+	ControllerCLCSnippets    []string     `hcl:"controller_clc_snippets,optional"`
+	OSImage                  string       `hcl:"os_image,optional"`
+	DiskSize                 int          `hcl:"disk_size,optional"`
+	EnableAggregation        bool         `hcl:"enable_aggregation,optional"`
+	NetworkMTU               int          `hcl:"network_mtu,optional"`
+	PodCIDR                  string       `hcl:"pod_cidr,optional"`
+	ServiceCIDR              string       `hcl:"service_cidr,optional"`
+	ClusterDomainSuffix      string       `hcl:"cluster_domain_suffix,optional"`
+	EnableReporting          bool         `hcl:"enable_reporting,optional"`
+	CertsValidityPeriodHours int          `hcl:"certs_validity_period_hours,optional"`
+	WorkerPools              []workerPool `hcl:"worker_pool,block"`
+	DisableSelfHostedKubelet bool         `hcl:"disable_self_hosted_kubelet,optional"`
+	OIDC                     *oidc.Config `hcl:"oidc,block"`
+	EnableTLSBootstrap       bool         `hcl:"enable_tls_bootstrap,optional"`
+	EncryptPodTraffic        bool         `hcl:"encrypt_pod_traffic,optional"`
+	IgnoreX509CNCheck        bool         `hcl:"ignore_x509_cn_check,optional"`
+	ConntrackMaxPerCore      int          `hcl:"conntrack_max_per_core,optional"`
+	EnableNodeLocalDNS       bool         `hcl:"enable_node_local_dns,optional"`
+	NodeLocalDNSIP           string       `hcl:"node_local_dns_ip,optional"`
+	KubeAPIServerExtraFlags  []string
+}
+
+const (
+	// Name represents Google Cloud platform name as it should be referenced in function calls and configuration.
+	Name = "google-cloud"
+
+	// networkMTU is the default MTU of Google Cloud VPC networks.
+	networkMTU = 1460
+
+	// maxResourceNameLen is the limit of the length of Google Cloud resource names.
+	maxResourceNameLen = 63
+)
+
+// resourceNameRegexp matches names accepted by Google Cloud for compute resources.
+var resourceNameRegexp = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
+func (c *config) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		return hcl.Diagnostics{}
+	}
+
+	if diags := gohcl.DecodeBody(*configBody, evalContext, c); len(diags) != 0 {
+		return diags
+	}
+
+	return c.checkValidConfig()
+}
+
+// NewConfig returns new Google Cloud platform configuration with default values set.
+//
+//nolint:golint
+func NewConfig() *config {
+	return &config{
+		Region:              "europe-west1",
+		EnableAggregation:   true,
+		EnableTLSBootstrap:  true,
+		NetworkMTU:          networkMTU,
+		ConntrackMaxPerCore: platform.ConntrackMaxPerCore,
+		NodeLocalDNSIP:      platform.NodeLocalDNSIP,
+	}
+}
+
+func (c *config) clusterDomain() string {
+	return fmt.Sprintf("%s.%s", c.ClusterName, c.DNSZone)
+}
+
+// controllerCount returns the number of controllers, which defaults to 1 in the Terraform module.
+func (c *config) controllerCount() int {
+	if c.ControllerCount == 0 {
+		return 1
+	}
+
+	return c.ControllerCount
+}
+
+// Meta is part of Platform interface and returns common information about the platform configuration.
+func (c *config) Meta() platform.Meta {
+	nodes := c.controllerCount()
+	for _, workerpool := range c.WorkerPools {
+		nodes += workerpool.Count
+	}
+
+	charts := platform.CommonControlPlaneCharts(platform.ControlPlanCharts{
+		Kubelet:      !c.DisableSelfHostedKubelet,
+		NodeLocalDNS: c.EnableNodeLocalDNS,
+	})
+
+	return platform.Meta{
+		AssetDir:             c.AssetDir,
+		ExpectedNodes:        nodes,
+		ControlplaneCharts:   charts,
+		ControllerModuleName: fmt.Sprintf("%s-%s", Name, c.ClusterName),
+		Deployments:          platform.CommonDeployments(c.controllerCount()),
+		DaemonSets:           platform.CommonDaemonSets(c.controllerCount(), c.DisableSelfHostedKubelet),
+	}
+}
+
+// Apply creates or updates the cluster infrastructure.
+func (c *config) Apply(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return err
+	}
+
+	return ex.Apply([]string{terraform.WithParallelism})
+}
+
+// ApplyWithoutParallel applies Terraform configuration without parallel execution.
+func (c *config) ApplyWithoutParallel(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return fmt.Errorf("initializing Terraform configuration: %w", err)
+	}
+
+	return ex.Apply([]string{terraform.WithoutParallelism})
+}
+
+// Destroy destroys the cluster infrastructure.
+func (c *config) Destroy(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return err
+	}
+
+	return ex.Destroy()
+}
+
+// Initialize renders the Terraform configuration for the cluster.
+func (c *config) Initialize(ex *terraform.Executor) error {
+	assetDir, err := homedir.Expand(c.AssetDir)
+	if err != nil {
+		return err
+	}
+
+	terraformRootDir := terraform.GetTerraformRootDir(assetDir)
+
+	return createTerraformConfigFile(c, terraformRootDir)
+}
+
+func createTerraformConfigFile(cfg *config, terraformRootDir string) error {
+	workerpoolCfgList := []map[string]string{}
+	tmplName := "cluster.tf"
+
+	t, err := template.New(tmplName).Parse(terraformConfigTmpl)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	path := filepath.Join(terraformRootDir, tmplName)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	keyListBytes, err := json.Marshal(cfg.SSHPubKeys)
+	if err != nil {
+		return fmt.Errorf("marshaling SSH public keys: %w", err)
+	}
+
+	controllerCLCSnippetsBytes, err := json.Marshal(cfg.ControllerCLCSnippets)
+	if err != nil {
+		return fmt.Errorf("marshaling CLC snippets: %w", err)
+	}
+
+	credsPath := ""
+
+	if cfg.CredsPath != "" {
+		credsPath, err = homedir.Expand(cfg.CredsPath)
+		if err != nil {
+			return fmt.Errorf("expanding path %q: %w", cfg.CredsPath, err)
+		}
+	}
+
+	// Configure oidc flags and set it to KubeAPIServerExtraFlags.
+	if cfg.OIDC != nil {
+		// Skipping the error checking here because its done in checkValidConfig().
+		oidcFlags, _ := cfg.OIDC.ToKubeAPIServerFlags(cfg.clusterDomain())
+		// Not using append, as Initialize may be called multiple times for the same config.
+		cfg.KubeAPIServerExtraFlags = oidcFlags
+	}
+
+	for _, workerpool := range cfg.WorkerPools {
+		input := map[string]interface{}{
+			"clc_snippets": workerpool.CLCSnippets,
+			"ssh_pub_keys": workerpool.SSHPubKeys,
+		}
+
+		output := map[string]string{}
+
+		for k, v := range input {
+			bytes, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("marshaling %q for worker pool %q failed: %w", k, workerpool.Name, err)
+			}
+
+			output[k] = string(bytes)
+		}
+
+		workerpoolCfgList = append(workerpoolCfgList, output)
+	}
+
+	terraformCfg := struct {
+		Config                config
+		CredsPath             string
+		SSHPublicKeys         string
+		ControllerCLCSnippets string
+		WorkerpoolCfg         []map[string]string
+	}{
+		Config:                *cfg,
+		CredsPath:             credsPath,
+		SSHPublicKeys:         string(keyListBytes),
+		ControllerCLCSnippets: string(controllerCLCSnippetsBytes),
+		WorkerpoolCfg:         workerpoolCfgList,
+	}
+
+	if err := t.Execute(f, terraformCfg); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+
+	return nil
+}
+
+// checkValidConfig validates cluster configuration.
+func (c *config) checkValidConfig() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	diagnostics = append(diagnostics, c.checkNotEmptyWorkers()...)
+	diagnostics = append(diagnostics, c.checkWorkerPoolNamesUnique()...)
+	diagnostics = append(diagnostics, c.checkNames()...)
+	diagnostics = append(diagnostics, c.checkCPUManagerPolicy()...)
+
+	if c.ConntrackMaxPerCore < 0 {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "conntrack_max_per_core can't be negative value",
+			Detail:   fmt.Sprintf("'conntrack_max_per_core' value is %d", c.ConntrackMaxPerCore),
+		})
+	}
+
+	if c.OIDC != nil {
+		_, diags := c.OIDC.ToKubeAPIServerFlags(c.clusterDomain())
+		diagnostics = append(diagnostics, diags...)
+	}
+
+	return diagnostics
+}
+
+// checkNames checks that cluster and worker pool names can be used as a prefix of
+// Google Cloud resource names, which must be lowercase and at most 63 characters long.
+func (c *config) checkNames() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	maxClusterNameLen := maxResourceNameLen - len("-internal-node-exporter") // This is the longest resource suffix.
+
+	if !resourceNameRegexp.MatchString(c.ClusterName) {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid cluster name",
+			Detail: fmt.Sprintf("Cluster name %q must start with a lowercase letter and contain only "+
+				"lowercase letters, digits and dashes", c.ClusterName),
+		})
+	}
+
+	if len(c.ClusterName) > maxClusterNameLen {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Cluster name too long",
+			Detail:   fmt.Sprintf("Maximum length is %d", maxClusterNameLen),
+		})
+	}
+
+	// Worker pool resources are prefixed with the cluster name.
+	maxPoolNameLen := maxResourceNameLen - len("-ingress-https-ipv4") - len(c.ClusterName) - 1
+
+	for _, wp := range c.WorkerPools {
+		if !resourceNameRegexp.MatchString(wp.Name) {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid worker pool name",
+				Detail: fmt.Sprintf("Worker pool name %q must start with a lowercase letter and contain only "+
+					"lowercase letters, digits and dashes", wp.Name),
+			})
+		}
+
+		if len(wp.Name) > maxPoolNameLen {
+			diagnostics = append(diagnostics, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Worker pool name too long",
+				Detail: fmt.Sprintf("Maximum length of worker pool %q name is %d with cluster name %q",
+					wp.Name, maxPoolNameLen, c.ClusterName),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// checkNotEmptyWorkers checks if the cluster has at least 1 node pool defined.
+func (c *config) checkNotEmptyWorkers() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	if len(c.WorkerPools) == 0 {
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "At least one worker pool must be defined",
+			Detail:   "Make sure to define at least one worker pool block in your cluster block",
+		})
+	}
+
+	return diagnostics
+}
+
+// checkWorkerPoolNamesUnique verifies that all worker pool names are unique.
+func (c *config) checkWorkerPoolNamesUnique() hcl.Diagnostics {
+	var diagnostics hcl.Diagnostics
+
+	dup := make(map[string]bool)
+
+	for _, w := range c.WorkerPools {
+		if !dup[w.Name] {
+			dup[w.Name] = true
+			continue
+		}
+
+		// It is duplicated.
+		diagnostics = append(diagnostics, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Worker pools name should be unique",
+			Detail:   fmt.Sprintf("Worker pool '%v' is duplicated", w.Name),
+		})
+	}
+
+	return diagnostics
+}
+
+func (c *config) checkCPUManagerPolicy() hcl.Diagnostics {
+	wp := make(map[string]string)
+	for _, w := range c.WorkerPools {
+		wp[w.Name] = w.CPUManagerPolicy
+	}
+
+	return platform.CheckCPUManagerPolicy(wp)
+}
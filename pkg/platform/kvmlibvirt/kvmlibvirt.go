@@ -0,0 +1,352 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvmlibvirt
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+const (
+	// Name represents KVM/libvirt platform name as it should be referenced in function calls and configuration.
+	Name = "kvm-libvirt"
+
+	// DefaultLibvirtURI is libvirt connection URI used, when none is configured.
+	DefaultLibvirtURI = "qemu:///system"
+
+	// Controllers get static IP addresses starting from this offset in node_ip_pool.
+	controllerIPOffset = 10
+)
+
+// Libvirt network, volume and domain names are derived from cluster and pool names,
+// so they should be valid hostname labels.
+var nameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Config represents KVM/libvirt platform configuration.
+type Config struct { //nolint:maligned
+	AssetDir      string   `hcl:"asset_dir"`
+	ClusterName   string   `hcl:"cluster_name"`
+	MachineDomain string   `hcl:"machine_domain"`
+	OSImage       string   `hcl:"os_image"`
+	SSHPubKeys    []string `hcl:"ssh_pubkeys"`
+
+	LibvirtURI string `hcl:"libvirt_uri,optional"`
+	NodeIPPool string `hcl:"node_ip_pool,optional"`
+
+	ControllerCount         int      `hcl:"controller_count,optional"`
+	ControllerVirtualCPUs   int      `hcl:"controller_virtual_cpus,optional"`
+	ControllerVirtualMemory int      `hcl:"controller_virtual_memory,optional"`
+	ControllerCLCSnippets   []string `hcl:"controller_clc_snippets,optional"`
+
+	// Generic options.
+	EnableAggregation        bool   `hcl:"enable_aggregation,optional"`
+	EnableReporting          bool   `hcl:"enable_reporting,optional"`
+	PodCIDR                  string `hcl:"pod_cidr,optional"`
+	ServiceCIDR              string `hcl:"service_cidr,optional"`
+	ClusterDomainSuffix      string `hcl:"cluster_domain_suffix,optional"`
+	CertsValidityPeriodHours int    `hcl:"certs_validity_period_hours,optional"`
+	NetworkMTU               int    `hcl:"network_mtu,optional"`
+	EncryptPodTraffic        bool   `hcl:"encrypt_pod_traffic,optional"`
+	ConntrackMaxPerCore      int    `hcl:"conntrack_max_per_core,optional"`
+
+	WorkerPools []WorkerPool `hcl:"worker_pool,block"`
+}
+
+// WorkerPool represents KVM/libvirt worker pool configuration.
+type WorkerPool struct {
+	PoolName string `hcl:"name,label"`
+
+	Count         int      `hcl:"count"`
+	VirtualCPUs   int      `hcl:"virtual_cpus,optional"`
+	VirtualMemory int      `hcl:"virtual_memory,optional"`
+	SSHPubKeys    []string `hcl:"ssh_pubkeys,optional"`
+	CLCSnippets   []string `hcl:"clc_snippets,optional"`
+
+	// Generic options.
+	Labels map[string]string `hcl:"labels,optional"`
+	Taints map[string]string `hcl:"taints,optional"`
+}
+
+// Name returns worker pool name.
+func (w *WorkerPool) Name() string {
+	return w.PoolName
+}
+
+// LoadConfig loads platform configuration using given HCL structs.
+func (c *Config) LoadConfig(configBody *hcl.Body, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	if configBody == nil {
+		emptyConfig := hcl.EmptyBody()
+		configBody = &emptyConfig
+	}
+
+	if diags := gohcl.DecodeBody(*configBody, evalContext, c); len(diags) != 0 {
+		return diags
+	}
+
+	for i, k := range c.SSHPubKeys {
+		c.SSHPubKeys[i] = strings.TrimSpace(k)
+	}
+
+	for _, p := range c.WorkerPools {
+		for i, k := range p.SSHPubKeys {
+			p.SSHPubKeys[i] = strings.TrimSpace(k)
+		}
+	}
+
+	return c.Validate()
+}
+
+// NewConfig returns KVM/libvirt default configuration.
+func NewConfig() *Config {
+	return &Config{
+		LibvirtURI:          DefaultLibvirtURI,
+		NodeIPPool:          "192.168.192.0/24",
+		ControllerCount:     1,
+		EnableAggregation:   true,
+		ConntrackMaxPerCore: platform.ConntrackMaxPerCore,
+	}
+}
+
+// Meta is part of Platform interface and returns common information about the platform configuration.
+func (c *Config) Meta() platform.Meta {
+	nodes := c.ControllerCount
+	for _, workerpool := range c.WorkerPools {
+		nodes += workerpool.Count
+	}
+
+	charts := platform.CommonControlPlaneCharts(platform.ControlPlanCharts{
+		Kubelet: true,
+	})
+
+	return platform.Meta{
+		AssetDir:             c.AssetDir,
+		ExpectedNodes:        nodes,
+		ControlplaneCharts:   charts,
+		Deployments:          platform.CommonDeployments(c.ControllerCount),
+		DaemonSets:           platform.CommonDaemonSets(c.ControllerCount, false),
+		ControllerModuleName: fmt.Sprintf("%s-%s", Name, c.ClusterName),
+	}
+}
+
+// Initialize creates Terraform configuration file.
+func (c *Config) Initialize(ex *terraform.Executor) error {
+	assetDir, err := homedir.Expand(c.AssetDir)
+	if err != nil {
+		return err
+	}
+
+	terraformRootDir := terraform.GetTerraformRootDir(assetDir)
+
+	return c.createTerraformConfigFile(terraformRootDir)
+}
+
+// Apply applies Terraform configuration.
+func (c *Config) Apply(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return err
+	}
+
+	return ex.Apply([]string{terraform.WithParallelism})
+}
+
+// ApplyWithoutParallel applies Terraform configuration without parallel executions.
+func (c *Config) ApplyWithoutParallel(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return fmt.Errorf("initializing Terraform configuration: %w", err)
+	}
+
+	return ex.Apply([]string{terraform.WithoutParallelism})
+}
+
+// Destroy destroys Terraform managed resources.
+func (c *Config) Destroy(ex *terraform.Executor) error {
+	if err := c.Initialize(ex); err != nil {
+		return err
+	}
+
+	return ex.Destroy()
+}
+
+func (c *Config) createTerraformConfigFile(terraformPath string) error {
+	tmplName := "cluster.tf"
+
+	t := template.Must(template.New(tmplName).Parse(terraformConfigTmpl))
+
+	path := filepath.Join(terraformPath, tmplName)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", path, err)
+	}
+
+	osImage, err := homedir.Expand(c.OSImage)
+	if err != nil {
+		return fmt.Errorf("expanding OS image path %q: %w", c.OSImage, err)
+	}
+
+	terraformCfg := struct {
+		*Config
+		OSImagePath string
+	}{
+		Config:      c,
+		OSImagePath: osImage,
+	}
+
+	if err := t.Execute(f, terraformCfg); err != nil {
+		return fmt.Errorf("failed to write template to file %q: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed closing file %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// Validate validates cluster configuration.
+func (c *Config) Validate() hcl.Diagnostics {
+	var d hcl.Diagnostics
+
+	// Convert KVM/libvirt worker pool to generic workerpool collection.
+	x := []platform.WorkerPool{}
+	for i, pool := range c.WorkerPools {
+		x = append(x, &c.WorkerPools[i])
+
+		if !nameRegexp.MatchString(pool.PoolName) {
+			d = append(d, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid worker pool name",
+				Detail: fmt.Sprintf("Worker pool %d name %q must consist of lower case alphanumeric characters or '-', "+
+					"and must start and end with an alphanumeric character", i, pool.PoolName),
+			})
+		}
+
+		if pool.Count < 1 {
+			d = append(d, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Worker pool must have at least one worker",
+				Detail:   fmt.Sprintf("Worker pool %q count is %d", pool.PoolName, pool.Count),
+			})
+		}
+	}
+
+	if c.ConntrackMaxPerCore < 0 {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "conntrack_max_per_core can't be negative value",
+			Detail:   fmt.Sprintf("'conntrack_max_per_core' value is %d", c.ConntrackMaxPerCore),
+		})
+	}
+
+	d = append(d, platform.WorkerPoolNamesUnique(x)...)
+	d = append(d, c.validateRequiredFields()...)
+	d = append(d, c.validateNodeIPPool()...)
+
+	return d
+}
+
+func (c *Config) validateRequiredFields() hcl.Diagnostics {
+	var d hcl.Diagnostics
+
+	if c.AssetDir == "" {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Asset dir can't be empty",
+		})
+	}
+
+	if !nameRegexp.MatchString(c.ClusterName) {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid cluster name",
+			Detail: fmt.Sprintf("Cluster name %q must consist of lower case alphanumeric characters or '-', "+
+				"and must start and end with an alphanumeric character", c.ClusterName),
+		})
+	}
+
+	if c.MachineDomain == "" {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Machine domain can't be empty",
+		})
+	}
+
+	if c.OSImage == "" {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "OS image path can't be empty",
+		})
+	}
+
+	if len(c.SSHPubKeys) == 0 {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Controllers must have at least one SSH key configured",
+		})
+	}
+
+	if c.ControllerCount < 1 {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "There must be at least one controller",
+			Detail:   fmt.Sprintf("'controller_count' value is %d", c.ControllerCount),
+		})
+	}
+
+	return d
+}
+
+// validateNodeIPPool checks, that node_ip_pool is a valid IPv4 CIDR, which has
+// enough addresses to assign static IP addresses to all controllers.
+func (c *Config) validateNodeIPPool() hcl.Diagnostics {
+	_, ipNet, err := net.ParseCIDR(c.NodeIPPool)
+	if err != nil || ipNet.IP.To4() == nil {
+		return hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid node IP pool",
+				Detail:   fmt.Sprintf("'node_ip_pool' value %q is not a valid IPv4 CIDR", c.NodeIPPool),
+			},
+		}
+	}
+
+	ones, bits := ipNet.Mask.Size()
+
+	// Network and broadcast addresses can't be used.
+	if available := 1<<uint(bits-ones) - 1; controllerIPOffset+c.ControllerCount >= available {
+		return hcl.Diagnostics{
+			&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Node IP pool is too small",
+				Detail: fmt.Sprintf("'node_ip_pool' value %q has not enough addresses for %d controllers",
+					c.NodeIPPool, c.ControllerCount),
+			},
+		}
+	}
+
+	return nil
+}
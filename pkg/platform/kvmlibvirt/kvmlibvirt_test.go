@@ -0,0 +1,276 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvmlibvirt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	lokoconfig "github.com/kinvolk/lokomotive/pkg/config"
+	"github.com/kinvolk/lokomotive/pkg/platform/kvmlibvirt"
+)
+
+func baseConfig() *kvmlibvirt.Config {
+	c := kvmlibvirt.NewConfig()
+
+	c.AssetDir = "foo"
+	c.ClusterName = "foo"
+	c.MachineDomain = "example.local"
+	c.OSImage = "/foo/flatcar_production_qemu_image.img"
+	c.SSHPubKeys = []string{"foo"}
+	c.WorkerPools = []kvmlibvirt.WorkerPool{
+		{
+			PoolName: "foo",
+			Count:    1,
+		},
+	}
+
+	return c
+}
+
+//nolint:funlen
+func TestConfigValidation(t *testing.T) {
+	cases := map[string]struct {
+		mutatingF   func(*kvmlibvirt.Config)
+		expectError bool
+	}{
+		"base config": {
+			mutatingF: func(c *kvmlibvirt.Config) {},
+		},
+		"require asset dir": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.AssetDir = ""
+			},
+			expectError: true,
+		},
+		"require cluster name": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.ClusterName = ""
+			},
+			expectError: true,
+		},
+		"reject invalid cluster name": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.ClusterName = "Foo_bar"
+			},
+			expectError: true,
+		},
+		"require machine domain": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.MachineDomain = ""
+			},
+			expectError: true,
+		},
+		"require OS image": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.OSImage = ""
+			},
+			expectError: true,
+		},
+		"require SSH public key": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.SSHPubKeys = []string{}
+			},
+			expectError: true,
+		},
+		"require at least one controller": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.ControllerCount = 0
+			},
+			expectError: true,
+		},
+		"reject invalid node IP pool": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.NodeIPPool = "foo"
+			},
+			expectError: true,
+		},
+		"reject IPv6 node IP pool": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.NodeIPPool = "fd00::/64"
+			},
+			expectError: true,
+		},
+		"reject node IP pool too small for controllers": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.NodeIPPool = "192.168.192.0/28"
+				c.ControllerCount = 5
+			},
+			expectError: true,
+		},
+		"reject negative conntrack_max_per_core": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.ConntrackMaxPerCore = -1
+			},
+			expectError: true,
+		},
+		"allow no worker pools": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.WorkerPools = nil
+			},
+		},
+		"require worker pool name": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.WorkerPools[0].PoolName = ""
+			},
+			expectError: true,
+		},
+		"require worker pool count": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.WorkerPools[0].Count = 0
+			},
+			expectError: true,
+		},
+		"require unique worker pool names": {
+			mutatingF: func(c *kvmlibvirt.Config) {
+				c.WorkerPools = append(c.WorkerPools, c.WorkerPools[0])
+			},
+			expectError: true,
+		},
+	}
+
+	for n, spec := range cases {
+		spec := spec
+
+		t.Run(n, func(t *testing.T) {
+			c := baseConfig()
+			spec.mutatingF(c)
+
+			diags := c.Validate()
+
+			if spec.expectError && !diags.HasErrors() {
+				t.Fatalf("Expected validation error")
+			}
+
+			if !spec.expectError && diags.HasErrors() {
+				t.Fatalf("Unexpected validation error: %v", diags)
+			}
+		})
+	}
+}
+
+func loadConfigFromString(t *testing.T, c string) (*kvmlibvirt.Config, hcl.Diagnostics) {
+	p := hclparse.NewParser()
+
+	f, d := p.ParseHCL([]byte(c), "x.lokocfg")
+	if d.HasErrors() {
+		t.Fatalf("Parsing HCL should succeed, got: %v", d)
+	}
+
+	configBody := hcl.MergeFiles([]*hcl.File{f})
+
+	var rootConfig lokoconfig.RootConfig
+
+	if d := gohcl.DecodeBody(configBody, nil, &rootConfig); d.HasErrors() {
+		t.Fatalf("Decoding root config should succeed, got: %v", d)
+	}
+
+	cc := kvmlibvirt.NewConfig()
+
+	return cc, cc.LoadConfig(&rootConfig.Cluster.Config, &hcl.EvalContext{})
+}
+
+func TestLoadConfigEmpty(t *testing.T) {
+	c := `cluster "kvm-libvirt" {}`
+
+	if _, d := loadConfigFromString(t, c); !d.HasErrors() {
+		t.Fatalf("Empty config should not be valid")
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	c := `
+cluster "kvm-libvirt" {
+  asset_dir      = "foo"
+  cluster_name   = "foo"
+  machine_domain = "example.local"
+  os_image       = "/foo/flatcar_production_qemu_image.img"
+  ssh_pubkeys    = [<<EOF
+key
+
+
+EOF
+		,
+	]
+
+  worker_pool "foo" {
+    count = 2
+
+    ssh_pubkeys = [<<EOF
+key
+
+EOF
+			,
+		]
+  }
+}
+`
+
+	cc, d := loadConfigFromString(t, c)
+	if d.HasErrors() {
+		t.Fatalf("Valid config should not return error, got: %v", d)
+	}
+
+	if cc.LibvirtURI != kvmlibvirt.DefaultLibvirtURI {
+		t.Errorf("Expected default libvirt URI %q, got %q", kvmlibvirt.DefaultLibvirtURI, cc.LibvirtURI)
+	}
+
+	if cc.ControllerCount != 1 {
+		t.Errorf("Expected 1 controller by default, got %d", cc.ControllerCount)
+	}
+
+	if !reflect.DeepEqual(cc.SSHPubKeys, []string{"key"}) {
+		t.Errorf("Controllers SSH public keys should be trimmed from whitespace to ensure right Terraform rendering")
+	}
+
+	if !reflect.DeepEqual(cc.WorkerPools[0].SSHPubKeys, []string{"key"}) {
+		t.Errorf("Worker pools SSH public keys should be trimmed from whitespace to ensure right Terraform rendering")
+	}
+}
+
+func TestMeta(t *testing.T) {
+	c := baseConfig()
+	c.ControllerCount = 3
+	c.WorkerPools = []kvmlibvirt.WorkerPool{
+		{
+			PoolName: "foo",
+			Count:    1,
+		},
+		{
+			PoolName: "bar",
+			Count:    2,
+		},
+	}
+
+	m := c.Meta()
+
+	if m.AssetDir != c.AssetDir {
+		t.Errorf("Expected asset dir %q, got %q", c.AssetDir, m.AssetDir)
+	}
+
+	expectedNodes := 6
+	if m.ExpectedNodes != expectedNodes {
+		t.Errorf("Expected %d nodes, got %d", expectedNodes, m.ExpectedNodes)
+	}
+
+	expectedModuleName := "kvm-libvirt-foo"
+	if m.ControllerModuleName != expectedModuleName {
+		t.Errorf("Expected controller module name %q, got %q", expectedModuleName, m.ControllerModuleName)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvmlibvirt
+
+var terraformConfigTmpl = `
+terraform {
+  required_providers {
+    libvirt = {
+      source  = "dmacvicar/libvirt"
+      version = "0.6.2"
+    }
+  }
+}
+
+provider "libvirt" {
+  uri = "{{.LibvirtURI}}"
+}
+
+module "kvm-libvirt-{{.ClusterName}}" {
+  source = "../terraform-modules/kvm-libvirt/flatcar-linux/kubernetes"
+
+  cluster_name      = "{{.ClusterName}}"
+  machine_domain    = "{{.MachineDomain}}"
+  os_image_unpacked = "{{.OSImagePath}}"
+  node_ip_pool      = "{{.NodeIPPool}}"
+
+  ssh_keys = [
+  {{- range .SSHPubKeys}}
+    "{{.}}",
+  {{- end}}
+  ]
+  asset_dir = "../cluster-assets"
+
+  controller_count = {{.ControllerCount}}
+
+  {{- if .ControllerVirtualCPUs}}
+  virtual_cpus = {{.ControllerVirtualCPUs}}
+  {{- end}}
+
+  {{- if .ControllerVirtualMemory}}
+  virtual_memory = {{.ControllerVirtualMemory}}
+  {{- end}}
+
+  {{- if .ControllerCLCSnippets}}
+  controller_clc_snippets = [
+  {{- range .ControllerCLCSnippets }}
+    <<EOF
+{{.}}
+EOF
+    ,
+  {{- end}}
+  ]
+  {{- end}}
+
+  enable_aggregation = {{.EnableAggregation}}
+  enable_reporting   = {{.EnableReporting}}
+
+  {{- if .NetworkMTU }}
+  network_mtu = {{.NetworkMTU}}
+  {{- end }}
+
+  {{- if .PodCIDR }}
+  pod_cidr = "{{.PodCIDR}}"
+  {{- end }}
+
+  {{- if .ServiceCIDR }}
+  service_cidr = "{{.ServiceCIDR}}"
+  {{- end }}
+
+  {{- if .ClusterDomainSuffix }}
+  cluster_domain_suffix = "{{.ClusterDomainSuffix}}"
+  {{- end }}
+
+  {{- if .CertsValidityPeriodHours }}
+  certs_validity_period_hours = {{.CertsValidityPeriodHours}}
+  {{- end }}
+
+  encrypt_pod_traffic    = {{.EncryptPodTraffic}}
+  conntrack_max_per_core = {{.ConntrackMaxPerCore}}
+
+  worker_bootstrap_tokens = concat(
+    {{- range $index, $pool := .WorkerPools }}
+    module.worker-{{ $pool.Name }}.bootstrap_tokens,
+    {{- end }}
+  )
+}
+
+{{- range $index, $pool := .WorkerPools }}
+
+module "worker-{{ $pool.Name }}" {
+  source = "../terraform-modules/kvm-libvirt/flatcar-linux/kubernetes/workers"
+
+  cluster_name           = "{{ $.ClusterName }}"
+  pool_name              = "{{ $pool.Name }}"
+  worker_count           = {{ $pool.Count }}
+  network_name           = module.kvm-libvirt-{{ $.ClusterName }}.network_name
+  libvirtpool            = module.kvm-libvirt-{{ $.ClusterName }}.libvirtpool
+  libvirtbaseid          = module.kvm-libvirt-{{ $.ClusterName }}.libvirtbaseid
+  ca_cert                = module.kvm-libvirt-{{ $.ClusterName }}.ca_cert
+  apiserver              = module.kvm-libvirt-{{ $.ClusterName }}.apiserver
+  cluster_dns_service_ip = module.kvm-libvirt-{{ $.ClusterName }}.cluster_dns_service_ip
+
+  ssh_keys = [
+  {{- range (or $pool.SSHPubKeys $.SSHPubKeys) }}
+    "{{.}}",
+  {{- end}}
+  ]
+
+  {{- if $pool.VirtualCPUs }}
+  virtual_cpus = {{ $pool.VirtualCPUs }}
+  {{- end }}
+
+  {{- if $pool.VirtualMemory }}
+  virtual_memory = {{ $pool.VirtualMemory }}
+  {{- end }}
+
+  {{- if $.ClusterDomainSuffix }}
+  cluster_domain_suffix = "{{ $.ClusterDomainSuffix }}"
+  {{- end }}
+
+  {{- if $pool.CLCSnippets}}
+  clc_snippets = [
+  {{- range $pool.CLCSnippets}}
+    <<EOF
+{{.}}
+EOF
+    ,
+  {{- end}}
+  ]
+  {{- end}}
+
+  {{- if $pool.Labels }}
+  labels = {
+  {{- range $k, $v := $pool.Labels }}
+    "{{ $k }}" = "{{ $v }}",
+  {{- end }}
+  }
+  {{- end }}
+
+  {{- if $pool.Taints }}
+  taints = {
+  {{- range $k, $v := $pool.Taints }}
+    "{{ $k }}" = "{{ $v }}",
+  {{- end }}
+  }
+  {{- end }}
+}
+{{- end }}
+
+# Stub output, which indicates, that Terraform run at least once.
+# Used when checking, if we should ask user for confirmation, when
+# applying changes to the cluster.
+output "initialized" {
+  value     = true
+  sensitive = true
+}
+
+# values.yaml content for all deployed charts.
+output "pod-checkpointer_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.pod-checkpointer_values
+  sensitive = true
+}
+
+output "kube-apiserver_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.kube-apiserver_values
+  sensitive = true
+}
+
+output "kubernetes_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.kubernetes_values
+  sensitive = true
+}
+
+output "kubelet_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.kubelet_values
+  sensitive = true
+}
+
+output "calico_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.calico_values
+  sensitive = true
+}
+
+output "lokomotive_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.lokomotive_values
+  sensitive = true
+}
+
+output "bootstrap-secrets_values" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.bootstrap-secrets_values
+  sensitive = true
+}
+
+output "kubeconfig" {
+  value     = module.kvm-libvirt-{{.ClusterName}}.kubeconfig-admin
+  sensitive = true
+}
+`
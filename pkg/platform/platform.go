@@ -15,6 +15,7 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -127,6 +128,29 @@ type PlatformWithPostApplyHook interface { //nolint:golint
 	PostApplyHook(kubeconfig []byte) error
 }
 
+// PlatformWithNodeReplace is implemented by platforms, which can reprovision a single
+// machine. Implementing this interface is optional for platforms.
+type PlatformWithNodeReplace interface { //nolint:golint
+	// NodeReplacement returns how to reprovision the machine backing the given node. Addresses
+	// are node addresses reported by Kubernetes and can be empty, if the node is no longer
+	// registered in the cluster.
+	NodeReplacement(name string, addresses []string) (*NodeReplacement, error)
+}
+
+// NodeReplacement describes how the machine backing a node is reprovisioned.
+type NodeReplacement struct {
+	// Steps are Terraform steps, which mark resources of the machine for recreation on the
+	// next apply.
+	Steps []terraform.ExecutionStep
+	// Reboot is set, when the machine must be rebooted after the apply to start the
+	// installation. Otherwise the apply boots the machine into the installation itself.
+	Reboot bool
+}
+
+// ErrNodeNotFound is returned by PlatformWithNodeReplace implementations, when no machine
+// in the cluster configuration matches the given node.
+var ErrNodeNotFound = errors.New("node not found in cluster configuration")
+
 // WorkerPool describes common functionality between worker pools implementations.
 type WorkerPool interface {
 	Name() string
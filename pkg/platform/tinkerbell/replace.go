@@ -0,0 +1,89 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tinkerbell
+
+import (
+	"fmt"
+
+	"github.com/kinvolk/lokomotive/pkg/platform"
+	"github.com/kinvolk/lokomotive/pkg/terraform"
+)
+
+// NodeReplacement implements platform.PlatformWithNodeReplace interface. As worker hostnames
+// are not unique across worker pools, machines are matched by IP address, which can be also
+// given as a node name. Only worker nodes can be replaced.
+//
+// Without sandbox, the machine must be rebooted to boot from the network and run the new workflow.
+func (c *Config) NodeReplacement(name string, addresses []string) (*platform.NodeReplacement, error) {
+	matches := func(ip string) bool {
+		if ip == name {
+			return true
+		}
+
+		for _, a := range addresses {
+			if a == ip {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, ip := range c.ControllerIPAddresses {
+		if matches(ip) {
+			return nil, fmt.Errorf("node %q is a controller node, replacing controller nodes is not supported", name)
+		}
+	}
+
+	for _, wp := range c.WorkerPools {
+		for i, ip := range wp.IPAddresses {
+			if matches(ip) {
+				return &platform.NodeReplacement{
+					Steps:  c.nodeReplaceSteps(wp.PoolName, i),
+					Reboot: c.Sandbox == nil,
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("looking up node %q: %w", name, platform.ErrNodeNotFound)
+}
+
+func (c *Config) nodeReplaceSteps(pool string, index int) []terraform.ExecutionStep {
+	resources := []string{
+		fmt.Sprintf("module.worker_%s.tinkerbell_template.main[%d]", pool, index),
+		fmt.Sprintf("module.worker_%s.tinkerbell_workflow.main[%d]", pool, index),
+	}
+
+	// With sandbox, recreating the virtual machine with an empty disk forces it to
+	// boot from the network and run the new workflow.
+	if c.Sandbox != nil {
+		resources = append(resources,
+			fmt.Sprintf("module.tink_worker_%s[%d].libvirt_volume.worker", pool, index),
+			fmt.Sprintf("module.tink_worker_%s[%d].libvirt_domain.worker", pool, index),
+		)
+	}
+
+	steps := []terraform.ExecutionStep{}
+
+	for _, r := range resources {
+		steps = append(steps, terraform.ExecutionStep{
+			Description: "taint machine resources",
+			Args:        []string{"taint", r},
+		})
+	}
+
+	return steps
+}
@@ -237,6 +237,10 @@ module "worker_{{ $pool.Name }}" {
 
   ip_addresses = local.worker_{{ $pool.Name }}_ips
 
+  {{- if $pool.Autoscaling }}
+  spare_count = {{ $pool.Autoscaling.MaxCount }}
+  {{- end }}
+
   {{- if $.ClusterDomainSuffix }}
   cluster_domain_suffix = "{{$.ClusterDomainSuffix}}"
   {{- end }}
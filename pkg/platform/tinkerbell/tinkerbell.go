@@ -24,6 +24,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/mitchellh/go-homedir"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/kinvolk/lokomotive/pkg/platform"
 	"github.com/kinvolk/lokomotive/pkg/terraform"
@@ -87,6 +88,16 @@ type WorkerPool struct {
 	// Generic options.
 	Labels map[string]string `hcl:"labels,optional"`
 	Taints map[string]string `hcl:"taints,optional"`
+
+	Autoscaling *Autoscaling `hcl:"autoscaling,block"`
+}
+
+// Autoscaling represents configuration of worker pool nodes added by the Cluster Autoscaler.
+type Autoscaling struct {
+	MaxCount int    `hcl:"max_count"`
+	CPU      string `hcl:"cpu"`
+	Memory   string `hcl:"memory"`
+	MaxPods  int    `hcl:"max_pods,optional"`
 }
 
 // Name returns worker pool name.
@@ -234,6 +245,8 @@ func (c *Config) Validate() hcl.Diagnostics {
 				Detail:   fmt.Sprintf("Worker pool %q IP addresses list is empty", pool.PoolName),
 			})
 		}
+
+		d = append(d, pool.validateAutoscaling()...)
 	}
 
 	if c.ConntrackMaxPerCore < 0 {
@@ -304,3 +317,47 @@ func (c *Config) validateRequiredFields() hcl.Diagnostics {
 
 	return d
 }
+
+func (w *WorkerPool) validateAutoscaling() hcl.Diagnostics {
+	var d hcl.Diagnostics
+
+	if w.Autoscaling == nil {
+		return d
+	}
+
+	if w.Autoscaling.MaxCount <= 0 {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Autoscaling max_count must be positive",
+			Detail:   fmt.Sprintf("Worker pool %q has 'max_count' set to %d", w.PoolName, w.Autoscaling.MaxCount),
+		})
+	}
+
+	if w.Autoscaling.MaxPods < 0 {
+		d = append(d, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Autoscaling max_pods can't be negative value",
+			Detail:   fmt.Sprintf("Worker pool %q has 'max_pods' set to %d", w.PoolName, w.Autoscaling.MaxPods),
+		})
+	}
+
+	quantities := []struct {
+		name  string
+		value string
+	}{
+		{"cpu", w.Autoscaling.CPU},
+		{"memory", w.Autoscaling.Memory},
+	}
+
+	for _, q := range quantities {
+		if _, err := resource.ParseQuantity(q.value); err != nil {
+			d = append(d, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Autoscaling %s must be a valid quantity", q.name),
+				Detail:   fmt.Sprintf("Worker pool %q has '%s' set to %q: %v", w.PoolName, q.name, q.value, err),
+			})
+		}
+	}
+
+	return d
+}
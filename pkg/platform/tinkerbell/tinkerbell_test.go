@@ -98,6 +98,34 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectError: true,
 		},
+		"allow worker pool autoscaling": {
+			mutatingF: func(c *tinkerbell.Config) {
+				c.WorkerPools[0].Autoscaling = &tinkerbell.Autoscaling{
+					MaxCount: 2,
+					CPU:      "8",
+					Memory:   "32Gi",
+				}
+			},
+		},
+		"require positive autoscaling max count": {
+			mutatingF: func(c *tinkerbell.Config) {
+				c.WorkerPools[0].Autoscaling = &tinkerbell.Autoscaling{
+					CPU:    "8",
+					Memory: "32Gi",
+				}
+			},
+			expectError: true,
+		},
+		"require valid autoscaling memory": {
+			mutatingF: func(c *tinkerbell.Config) {
+				c.WorkerPools[0].Autoscaling = &tinkerbell.Autoscaling{
+					MaxCount: 2,
+					CPU:      "8",
+					Memory:   "32 GB",
+				}
+			},
+			expectError: true,
+		},
 		"require sandbox hosts CIDR": {
 			mutatingF: func(c *tinkerbell.Config) {
 				c.Sandbox.HostsCIDR = ""
@@ -244,3 +272,73 @@ func TestMeta(t *testing.T) {
 		t.Errorf("Expected %d nodes, got %d", expectedNodes, m.ExpectedNodes)
 	}
 }
+
+func TestNodeReplacement(t *testing.T) {
+	cases := map[string]struct {
+		mutatingF func(*tinkerbell.Config)
+		name      string
+		addresses []string
+		expected  [][]string
+		reboot    bool
+	}{
+		"with sandbox": {
+			mutatingF: func(c *tinkerbell.Config) {},
+			name:      "foo-worker-0",
+			addresses: []string{"10.17.3.5"},
+			expected: [][]string{
+				{"taint", "module.worker_bar.tinkerbell_template.main[1]"},
+				{"taint", "module.worker_bar.tinkerbell_workflow.main[1]"},
+				{"taint", "module.tink_worker_bar[1].libvirt_volume.worker"},
+				{"taint", "module.tink_worker_bar[1].libvirt_domain.worker"},
+			},
+		},
+		"without sandbox by IP address": {
+			mutatingF: func(c *tinkerbell.Config) {
+				c.Sandbox = nil
+			},
+			name: "10.17.3.5",
+			expected: [][]string{
+				{"taint", "module.worker_bar.tinkerbell_template.main[1]"},
+				{"taint", "module.worker_bar.tinkerbell_workflow.main[1]"},
+			},
+			reboot: true,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+
+		t.Run(n, func(t *testing.T) {
+			c := baseConfig()
+			c.WorkerPools = append(c.WorkerPools, tinkerbell.WorkerPool{
+				PoolName:    "bar",
+				IPAddresses: []string{"10.17.3.4", "10.17.3.5"},
+			})
+			tc.mutatingF(c)
+
+			r, err := c.NodeReplacement(tc.name, tc.addresses)
+			if err != nil {
+				t.Fatalf("Getting node replacement should succeed, got: %v", err)
+			}
+
+			if r.Reboot != tc.reboot {
+				t.Fatalf("Expected reboot %v, got %v", tc.reboot, r.Reboot)
+			}
+
+			args := [][]string{}
+			for _, s := range r.Steps {
+				args = append(args, s.Args)
+			}
+
+			if !reflect.DeepEqual(tc.expected, args) {
+				t.Fatalf("Expected steps %v, got %v", tc.expected, args)
+			}
+		})
+	}
+}
+
+func TestNodeReplacementRejectControllers(t *testing.T) {
+	if _, err := baseConfig().NodeReplacement("foo-controller-0", []string{"foo"}); err == nil {
+		t.Fatalf("Replacing controller node should fail")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// FileScheme is the URL scheme of secrets stored in local files.
+const FileScheme = "file"
+
+// FileProvider reads secrets from local files, for example mounted by the CI system.
+// Absolute paths are referenced as "file:///path/to/file" and relative paths as
+// "file:path/to/file". If the URL has a fragment, the file is parsed as JSON object
+// and the value of the key given by the fragment is returned. Otherwise the file content
+// without trailing newlines is returned.
+type FileProvider struct{}
+
+// Secret implements Provider interface.
+func (f *FileProvider) Secret(ref *url.URL) (string, error) {
+	if ref.Host != "" {
+		return "", fmt.Errorf("host is not supported, use file:///absolute/path or file:relative/path")
+	}
+
+	path := ref.Path
+	if ref.Opaque != "" {
+		path = ref.Opaque
+	}
+
+	if path == "" {
+		return "", fmt.Errorf("no path specified")
+	}
+
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	if ref.Fragment != "" {
+		return selectJSONKey(content, ref.Fragment)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/secrets"
+)
+
+func tempFile(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lokomotive-secrets")
+	if err != nil {
+		t.Fatalf("Creating temporary directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Logf("Removing temporary directory: %v", err)
+		}
+	})
+
+	path := filepath.Join(dir, "secret")
+
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Writing file: %v", err)
+	}
+
+	return path
+}
+
+func TestResolveFile(t *testing.T) {
+	t.Parallel()
+
+	path := tempFile(t, "s3cr3t\n")
+
+	secret, err := secrets.DefaultProviders().Resolve("file://" + path)
+	if err != nil {
+		t.Fatalf("Resolving file secret should succeed, got: %v", err)
+	}
+
+	if secret != "s3cr3t" {
+		t.Fatalf("Expected secret %q, got %q", "s3cr3t", secret)
+	}
+}
+
+func TestResolveFileKey(t *testing.T) {
+	t.Parallel()
+
+	path := tempFile(t, `{"client_secret": "s3cr3t"}`)
+
+	secret, err := secrets.DefaultProviders().Resolve("file://" + path + "#client_secret")
+	if err != nil {
+		t.Fatalf("Resolving file secret should succeed, got: %v", err)
+	}
+
+	if secret != "s3cr3t" {
+		t.Fatalf("Expected secret %q, got %q", "s3cr3t", secret)
+	}
+}
+
+func TestResolveFails(t *testing.T) {
+	t.Parallel()
+
+	path := tempFile(t, `{"client_secret": "s3cr3t"}`)
+
+	for name, ref := range map[string]string{
+		"with unsupported scheme": "foo://bar",
+		"with file host":          "file://localhost" + path,
+		"with missing file":       "file:///nonexistent/secret",
+		"with missing key":        "file://" + path + "#foo",
+	} {
+		if _, err := secrets.DefaultProviders().Resolve(ref); err == nil {
+			t.Errorf("Resolving secret %s should fail", name)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets resolves references to secrets stored outside of the Lokomotive
+// configuration, like "vault://secret/data/foo#password" or "file:///run/secrets/token".
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Provider reads secrets from a single kind of secret store.
+type Provider interface {
+	// Secret returns the secret referenced by given URL. The URL scheme is the one
+	// the provider is registered for.
+	Secret(ref *url.URL) (string, error)
+}
+
+// Providers maps URL schemes to secret providers.
+type Providers map[string]Provider
+
+// DefaultProviders returns providers for all supported secret stores. Providers are
+// configured from the environment only when used.
+func DefaultProviders() Providers {
+	return Providers{
+		FileScheme:  &FileProvider{},
+		VaultScheme: NewVaultProviderFromEnv(),
+	}
+}
+
+// Resolve returns the secret referenced by given URL using provider registered for
+// its scheme.
+func (p Providers) Resolve(ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parsing secret reference %q: %w", ref, err)
+	}
+
+	provider, ok := p[u.Scheme]
+	if !ok {
+		return "", fmt.Errorf("unsupported secret reference %q: scheme must be one of: '%s'",
+			ref, strings.Join(p.schemes(), "', '"))
+	}
+
+	secret, err := provider.Secret(u)
+	if err != nil {
+		return "", fmt.Errorf("reading secret %q: %w", ref, err)
+	}
+
+	return secret, nil
+}
+
+func (p Providers) schemes() []string {
+	schemes := []string{}
+
+	for s := range p {
+		schemes = append(schemes, s)
+	}
+
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// selectKey returns the string value of given key from the JSON object.
+func selectKey(data map[string]interface{}, key string) (string, error) {
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", key)
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("value of key %q is not a string", key)
+	}
+
+	return s, nil
+}
+
+// selectJSONKey parses given content as JSON object and returns the string value of given key.
+func selectJSONKey(content []byte, key string) (string, error) {
+	data := map[string]interface{}{}
+
+	if err := json.Unmarshal(content, &data); err != nil {
+		return "", fmt.Errorf("parsing content as JSON object: %w", err)
+	}
+
+	return selectKey(data, key)
+}
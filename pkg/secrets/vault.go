@@ -0,0 +1,181 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// VaultScheme is the URL scheme of secrets stored in HashiCorp Vault KV secrets engine.
+	VaultScheme = "vault"
+
+	// vaultTokenFile is the file where Vault CLI stores the token after logging in.
+	vaultTokenFile = "~/.vault-token"
+
+	// vaultTimeout is the timeout of requests to Vault.
+	vaultTimeout = 30 * time.Second
+)
+
+// VaultProvider reads secrets from HashiCorp Vault KV secrets engine, version 1 or 2.
+// Secrets are referenced by the API path of the secret and the key, for example
+// "vault://secret/data/foo#password" for KV version 2 mounted at "secret/".
+type VaultProvider struct {
+	// Address is the address of the Vault server, e.g. "https://vault.example.com:8200".
+	Address string
+	// Token is the Vault token. If empty, the token stored by Vault CLI is used.
+	Token string
+	// Namespace is the Vault Enterprise namespace.
+	Namespace string
+	// CACert is the path to the CA certificate used to verify the Vault server certificate.
+	CACert string
+}
+
+// NewVaultProviderFromEnv returns Vault provider configured using the same environment
+// variables as Vault CLI.
+func NewVaultProviderFromEnv() *VaultProvider {
+	return &VaultProvider{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		CACert:    os.Getenv("VAULT_CACERT"),
+	}
+}
+
+// vaultResponse is the response of Vault read secret API.
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// Secret implements Provider interface.
+func (v *VaultProvider) Secret(ref *url.URL) (string, error) {
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	if path == "" || ref.Fragment == "" {
+		return "", fmt.Errorf("reference must be in format %s://<path>#<key>", VaultScheme)
+	}
+
+	if v.Address == "" {
+		return "", fmt.Errorf("no Vault address specified: use VAULT_ADDR environment variable")
+	}
+
+	token, err := v.token()
+	if err != nil {
+		return "", err
+	}
+
+	client, err := v.client()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(v.Address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("reading secret from Vault: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	body := vaultResponse{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("decoding Vault response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading secret from Vault: %s: %s", resp.Status, strings.Join(body.Errors, ", "))
+	}
+
+	data := body.Data
+
+	// KV version 2 wraps secret data together with its metadata.
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	return selectKey(data, ref.Fragment)
+}
+
+func (v *VaultProvider) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+
+	path, err := homedir.Expand(vaultTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("expanding path %q: %w", vaultTokenFile, err)
+	}
+
+	token, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("no Vault token specified: use VAULT_TOKEN environment variable or log in "+
+			"using Vault CLI: %w", err)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
+
+func (v *VaultProvider) client() (*http.Client, error) {
+	client := &http.Client{
+		Timeout: vaultTimeout,
+	}
+
+	if v.CACert == "" {
+		return client, nil
+	}
+
+	caCert, err := ioutil.ReadFile(v.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("reading Vault CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in Vault CA certificate file %q", v.CACert)
+	}
+
+	client.Transport = &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	return client, nil
+}
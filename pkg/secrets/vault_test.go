@@ -0,0 +1,115 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kinvolk/lokomotive/pkg/secrets"
+)
+
+const testVaultToken = "root"
+
+// fakeVault serves KV version 1 secret under "kv/foo" and KV version 2 secret under
+// "secret/data/foo", like Vault dev server with additional "kv" mount.
+func fakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/foo":
+			fmt.Fprint(w, `{"data": {"password": "v1-secret"}}`)
+		case "/v1/secret/data/foo":
+			fmt.Fprint(w, `{"data": {"data": {"password": "v2-secret"}, "metadata": {"version": 1}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": []}`)
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestVaultSecret(t *testing.T) {
+	t.Parallel()
+
+	s := fakeVault(t)
+
+	providers := secrets.Providers{
+		secrets.VaultScheme: &secrets.VaultProvider{
+			Address: s.URL,
+			Token:   testVaultToken,
+		},
+	}
+
+	cases := map[string]string{
+		"vault://kv/foo#password":          "v1-secret",
+		"vault://secret/data/foo#password": "v2-secret",
+	}
+
+	for ref, expected := range cases {
+		secret, err := providers.Resolve(ref)
+		if err != nil {
+			t.Errorf("Resolving secret %q should succeed, got: %v", ref, err)
+
+			continue
+		}
+
+		if secret != expected {
+			t.Errorf("Secret %q: expected %q, got %q", ref, expected, secret)
+		}
+	}
+}
+
+func TestVaultSecretFails(t *testing.T) {
+	t.Parallel()
+
+	s := fakeVault(t)
+
+	cases := map[string]struct {
+		token string
+		ref   string
+	}{
+		"without key":         {testVaultToken, "vault://secret/data/foo"},
+		"with missing key":    {testVaultToken, "vault://secret/data/foo#username"},
+		"with missing secret": {testVaultToken, "vault://secret/data/bar#password"},
+		"with invalid token":  {"invalid", "vault://secret/data/foo#password"},
+	}
+
+	for name, c := range cases {
+		providers := secrets.Providers{
+			secrets.VaultScheme: &secrets.VaultProvider{
+				Address: s.URL,
+				Token:   c.token,
+			},
+		}
+
+		if _, err := providers.Resolve(c.ref); err == nil {
+			t.Errorf("Resolving secret %s should fail", name)
+		}
+	}
+}
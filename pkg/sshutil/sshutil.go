@@ -0,0 +1,174 @@
+// Copyright 2021 The Lokomotive Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshutil provides helpers for running commands on cluster nodes over SSH.
+package sshutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// DefaultUser is the user, which is configured with SSH keys on Flatcar Container Linux nodes.
+	DefaultUser = "core"
+
+	defaultPort = "22"
+	dialTimeout = 30 * time.Second
+)
+
+// Config controls how connections to the nodes are established.
+type Config struct {
+	// User is the name of the user to log in as.
+	User string
+	// InsecureIgnoreHostKey disables host key verification against known_hosts file.
+	InsecureIgnoreHostKey bool
+}
+
+// Client runs commands on a single node.
+type Client struct {
+	host   string
+	client *ssh.Client
+}
+
+// Dial connects to given host, authenticating using keys available in the SSH agent.
+func Dial(host string, config Config) (*Client, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, fmt.Errorf("SSH agent is not running: SSH_AUTH_SOCK environment variable is empty")
+	}
+
+	agentConn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to SSH agent: %w", err)
+	}
+
+	// Agent is only used for authentication, which is done when dialing.
+	defer agentConn.Close() //nolint:errcheck
+
+	hostKeyCallback, err := hostKeyCallback(config)
+	if err != nil {
+		return nil, err
+	}
+
+	user := config.User
+	if user == "" {
+		user = DefaultUser
+	}
+
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(host, defaultPort)
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %q: %w", addr, err)
+	}
+
+	return &Client{
+		host:   host,
+		client: client,
+	}, nil
+}
+
+func hostKeyCallback(config Config) (ssh.HostKeyCallback, error) {
+	if config.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+
+	path := filepath.Join(home, ".ssh", "known_hosts")
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading known hosts from %q: %w", path, err)
+	}
+
+	return callback, nil
+}
+
+// Host returns the host the client is connected to.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// Run runs given command on the node, with optional standard input, and returns
+// it's combined output.
+func (c *Client) Run(command string, stdin io.Reader) (string, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("creating SSH session: %w", err)
+	}
+
+	defer session.Close() //nolint:errcheck
+
+	var output bytes.Buffer
+
+	session.Stdout = &output
+	session.Stderr = &output
+	session.Stdin = stdin
+
+	if err := session.Run(command); err != nil {
+		return output.String(), fmt.Errorf("running command %q on %q: %w, output: %s", command, c.host, err, output.String())
+	}
+
+	return output.String(), nil
+}
+
+// Output runs given command on the node and writes it's standard output to given writer.
+// Unlike Run, it is suitable for commands producing large or binary output.
+func (c *Client) Output(command string, w io.Writer) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("creating SSH session: %w", err)
+	}
+
+	defer session.Close() //nolint:errcheck
+
+	var stderr bytes.Buffer
+
+	session.Stdout = w
+	session.Stderr = &stderr
+
+	if err := session.Run(command); err != nil {
+		return fmt.Errorf("running command %q on %q: %w, output: %s", command, c.host, err, stderr.String())
+	}
+
+	return nil
+}
+
+// Close closes the connection to the node.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
@@ -20,4 +20,6 @@ package terraform
 type Config struct {
 	WorkingDir string
 	Verbose    bool
+	// EnvVariables are passed to Terraform in addition to the current environment.
+	EnvVariables map[string]string
 }
@@ -38,6 +38,7 @@ import (
 const (
 	stateFileName  = "terraform.tfstate"
 	tfVarsFileName = "terraform.tfvars"
+	planFileName   = "lokoctl.tfplan"
 	logsFolderName = "logs"
 
 	logsFileSuffix = ".log"
@@ -139,6 +140,7 @@ func NewExecutor(conf Config) (*Executor, error) {
 	ex := new(Executor)
 	ex.executionPath = conf.WorkingDir
 	ex.verbose = conf.Verbose
+	ex.envVariables = conf.EnvVariables
 	ex.logger = log.WithFields(log.Fields{
 		"phase": "infrastructure",
 	})
@@ -413,6 +415,56 @@ func (ex *Executor) executeSync(args ...string) ([]byte, error) {
 	return output, nil
 }
 
+// StateResources returns all resources stored in the Terraform state, including resources
+// of child modules.
+func (ex *Executor) StateResources() ([]StateResource, error) {
+	o, err := ex.executeSync("show", "-json")
+	if err != nil {
+		return nil, fmt.Errorf("reading state: %w", err)
+	}
+
+	return parseStateResources(o)
+}
+
+// stateModule is a module in the output of 'terraform show -json'.
+type stateModule struct {
+	Resources    []StateResource `json:"resources"`
+	ChildModules []stateModule   `json:"child_modules"`
+}
+
+// parseStateResources parses resources from the output of 'terraform show -json'.
+func parseStateResources(data []byte) ([]StateResource, error) {
+	state := struct {
+		Values struct {
+			RootModule stateModule `json:"root_module"`
+		} `json:"values"`
+	}{}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshaling state: %w", err)
+	}
+
+	resources := []StateResource{}
+	modules := []stateModule{state.Values.RootModule}
+
+	for len(modules) > 0 {
+		m := modules[0]
+		modules = append(modules[1:], m.ChildModules...)
+
+		resources = append(resources, m.Resources...)
+	}
+
+	return resources, nil
+}
+
+// MoveState moves resource or module in the Terraform state to the new address.
+func (ex *Executor) MoveState(from, to string) error {
+	return ex.Execute(ExecutionStep{
+		Description: fmt.Sprintf("move %s to %s in state", from, to),
+		Args:        []string{"state", "mv", from, to},
+	})
+}
+
 // Plan runs 'terraform plan'.
 func (ex *Executor) Plan() error {
 	ex.logger.Println("Generating Terraform execution plan")
@@ -428,6 +480,99 @@ func (ex *Executor) Plan() error {
 	return ex.executeVerbose("plan", "-refresh=false")
 }
 
+// StateResource describes a single resource stored in the Terraform state.
+type StateResource struct {
+	// Address is the full address of the resource, e.g. "module.foo.aws_instance.bar[0]".
+	Address string `json:"address"`
+	// Values holds the attributes of the resource.
+	Values map[string]interface{} `json:"values"`
+}
+
+// ResourceChange describes a planned change of a single Terraform resource.
+type ResourceChange struct {
+	// Address is the full address of the resource, e.g. "module.foo.aws_instance.bar[0]".
+	Address string `json:"address"`
+	// Actions is a list of actions Terraform will take on the resource, e.g. ["delete", "create"]
+	// when resource gets replaced.
+	Actions []string `json:"actions"`
+	// Before and After hold the attributes of the resource before and after the change.
+	// Unknown attributes and attributes of resources which do not exist are not included.
+	Before map[string]interface{} `json:"-"`
+	After  map[string]interface{} `json:"-"`
+}
+
+// Deleted returns true if resource will be destroyed, either because it is removed or
+// because it gets replaced.
+func (rc ResourceChange) Deleted() bool {
+	for _, a := range rc.Actions {
+		if a == "delete" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PlanChanges runs 'terraform plan' and returns the list of resources, which would be
+// changed by 'terraform apply'. Resources without any changes are not included.
+func (ex *Executor) PlanChanges() ([]ResourceChange, error) {
+	planPath := filepath.Join(ex.WorkingDirectory(), planFileName)
+
+	// Refresh is done as part of the plan, so the state is not modified.
+	step := ExecutionStep{
+		Description: "plan infrastructure changes",
+		Args:        []string{"plan", "-refresh=true", "-out=" + planPath},
+	}
+
+	if err := ex.Execute(step); err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(planPath) //nolint:errcheck
+
+	o, err := ex.executeSync("show", "-json", planPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading execution plan: %w", err)
+	}
+
+	return parsePlanChanges(o)
+}
+
+// parsePlanChanges parses resource changes from the output of 'terraform show -json <plan file>'.
+func parsePlanChanges(data []byte) ([]ResourceChange, error) {
+	plan := struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Change  struct {
+				Actions []string               `json:"actions"`
+				Before  map[string]interface{} `json:"before"`
+				After   map[string]interface{} `json:"after"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}{}
+
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("unmarshaling execution plan: %w", err)
+	}
+
+	changes := []ResourceChange{}
+
+	for _, rc := range plan.ResourceChanges {
+		if len(rc.Change.Actions) == 1 && (rc.Change.Actions[0] == "no-op" || rc.Change.Actions[0] == "read") {
+			continue
+		}
+
+		changes = append(changes, ResourceChange{
+			Address: rc.Address,
+			Actions: rc.Change.Actions,
+			Before:  rc.Change.Before,
+			After:   rc.Change.After,
+		})
+	}
+
+	return changes, nil
+}
+
 // Output gets output value from Terraform in JSON format and tries to unmarshal it
 // to a given struct.
 func (ex *Executor) Output(key string, s interface{}) error {
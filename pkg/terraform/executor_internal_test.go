@@ -17,6 +17,7 @@ package terraform
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-version"
 )
 
@@ -25,3 +26,94 @@ func TestRequiredVersion(t *testing.T) {
 		t.Fatalf("requiredVersion const must be valid version constraint, got: %v", err)
 	}
 }
+
+func TestParsePlanChanges(t *testing.T) {
+	plan := `{
+  "format_version": "0.1",
+  "resource_changes": [
+    {"address": "module.foo.aws_instance.a", "change": {"actions": ["no-op"]}},
+    {"address": "module.foo.aws_instance.b", "change": {"actions": ["create"]}},
+    {"address": "data.aws_ami.c", "change": {"actions": ["read"]}},
+    {"address": "module.foo.aws_instance.d", "change": {"actions": ["delete", "create"], "before": {"name": "d"}}}
+  ]
+}`
+
+	changes, err := parsePlanChanges([]byte(plan))
+	if err != nil {
+		t.Fatalf("Parsing valid plan should succeed, got: %v", err)
+	}
+
+	expected := []ResourceChange{
+		{Address: "module.foo.aws_instance.b", Actions: []string{"create"}},
+		{
+			Address: "module.foo.aws_instance.d",
+			Actions: []string{"delete", "create"},
+			Before:  map[string]interface{}{"name": "d"},
+		},
+	}
+
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Fatalf("Unexpected changes (-want +got)\n%s", diff)
+	}
+}
+
+func TestParsePlanChangesBadJSON(t *testing.T) {
+	if _, err := parsePlanChanges([]byte("foo")); err == nil {
+		t.Fatalf("Parsing malformed plan should fail")
+	}
+}
+
+func TestParseStateResources(t *testing.T) {
+	state := `{
+  "format_version": "0.1",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "local_file.a", "values": {"filename": "a"}}
+      ],
+      "child_modules": [
+        {
+          "address": "module.foo",
+          "resources": [
+            {"address": "module.foo.aws_instance.b", "values": {"name": "b"}}
+          ],
+          "child_modules": [
+            {
+              "address": "module.foo.module.bar",
+              "resources": [
+                {"address": "module.foo.module.bar.aws_instance.c", "values": {"name": "c"}}
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+	resources, err := parseStateResources([]byte(state))
+	if err != nil {
+		t.Fatalf("Parsing valid state should succeed, got: %v", err)
+	}
+
+	expected := []StateResource{
+		{Address: "local_file.a", Values: map[string]interface{}{"filename": "a"}},
+		{Address: "module.foo.aws_instance.b", Values: map[string]interface{}{"name": "b"}},
+		{Address: "module.foo.module.bar.aws_instance.c", Values: map[string]interface{}{"name": "c"}},
+	}
+
+	if diff := cmp.Diff(expected, resources); diff != "" {
+		t.Fatalf("Unexpected resources (-want +got)\n%s", diff)
+	}
+}
+
+func TestParseStateResourcesEmptyState(t *testing.T) {
+	resources, err := parseStateResources([]byte(`{"format_version": "0.1"}`))
+	if err != nil {
+		t.Fatalf("Parsing empty state should succeed, got: %v", err)
+	}
+
+	if len(resources) != 0 {
+		t.Fatalf("Expected no resources, got %v", resources)
+	}
+}